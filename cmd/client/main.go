@@ -47,7 +47,7 @@ func main() {
 
 	switch *cmd {
 	case cmdDone:
-		updateTaskStatus(client, *id, pb.TaskStatus_DONE)
+		updateTaskStatus(client, *id, "done")
 	case cmdList:
 		getTasks(client)
 	}
@@ -57,22 +57,9 @@ func getTasks(client pb.TaskServiceClient) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
-	// status := "in_progress"
-
-	// var pbstatus pb.TaskStatus
-
-	// switch status {
-	// case "in_progress":
-	// 	pbstatus = pb.TaskStatus_IN_PROGRESS
-	// case "done":
-	// 	pbstatus = pb.TaskStatus_DONE
-	// default:
-	// 	log.Fatal("invalid task status")
-	// }
-
 	filter := &pb.TaskFilter{
 		Id: []string{"3c3b8244-1dc2-4dc4-8e0f-19fb840aa12b"},
-		// Status: pb.TaskStatus(pbstatus),
+		// Status: "in_progress",
 	}
 
 	req := &pb.GetTasksRequest{
@@ -87,13 +74,13 @@ func getTasks(client pb.TaskServiceClient) {
 	log.Printf("Response from GetTasks: %v", resp.Tasks)
 }
 
-func updateTaskStatus(client pb.TaskServiceClient, taskID string, newStatus pb.TaskStatus) {
+func updateTaskStatus(client pb.TaskServiceClient, taskID string, newStatus string) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
 	req := &pb.UpdateTaskStatusRequest{
-		TaskId:    taskID,
-		NewStatus: newStatus,
+		TaskId: taskID,
+		Status: newStatus,
 	}
 
 	resp, err := client.UpdateTaskStatus(ctx, req)
@@ -1,13 +1,28 @@
 package app
 
 import (
+	"context"
 	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/VikaPaz/task_tracker/internal/blobstore"
+	"github.com/VikaPaz/task_tracker/internal/eventbus"
+	"github.com/VikaPaz/task_tracker/internal/models"
 	"github.com/VikaPaz/task_tracker/internal/repository"
+	"github.com/VikaPaz/task_tracker/internal/repository/cache"
+	"github.com/VikaPaz/task_tracker/internal/repository/memory"
+	"github.com/VikaPaz/task_tracker/internal/repository/publish"
+	"github.com/VikaPaz/task_tracker/internal/repository/sqlite"
 	"github.com/VikaPaz/task_tracker/internal/server/grpc"
 	"github.com/VikaPaz/task_tracker/internal/server/rest"
 	"github.com/VikaPaz/task_tracker/internal/service"
@@ -18,6 +33,22 @@ import (
 	"github.com/uptrace/bun"
 )
 
+// STORAGE values selecting where the data is kept.
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+	storageSQLite   = "sqlite"
+)
+
+// server is the app wired to a storage.
+type server struct {
+	rest  *rest.TaskHandler
+	grpc  *grpc.TaskHandler
+	tasks *service.TaskService
+	// jobs run in the background until the context is done
+	jobs []func(ctx context.Context)
+}
+
 func Run() {
 	// build with "/local/.env"
 	err := godotenv.Load("./local/.env")
@@ -27,33 +58,46 @@ func Run() {
 
 	restPort := os.Getenv("SERVER_PORT")
 	grpcPort := os.Getenv("GRPC_PORT")
-	dsn := os.Getenv("DATABASE_URL")
 
 	logger, err := NewLogger()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error creating logger")
 	}
 
-	conf := repository.Config{
-		DSN: dsn,
+	var srv server
+	switch storage := os.Getenv("STORAGE"); storage {
+	case "", storagePostgres:
+		srv = postgresServer(logger)
+	case storageMemory:
+		srv = memoryServer(logger)
+	case storageSQLite:
+		srv = sqliteServer(logger)
+	default:
+		logger.Fatal().Msgf("unknown STORAGE: %q, want %s, %s or %s", storage, storagePostgres, storageMemory, storageSQLite)
 	}
 
-	db := repository.Connection(conf, logger)
-
-	err = runMigrations(logger, db)
+	trashRetention, err := envDuration("TRASH_RETENTION", defaultTrashRetention)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("can't run migrations")
+		logger.Fatal().Err(err).Msg("invalid TRASH_RETENTION")
+	}
+	trashPurgeInterval, err := envDuration("TRASH_PURGE_INTERVAL", defaultTrashPurgeInterval)
+	if err == nil && trashPurgeInterval <= 0 {
+		err = errors.New("interval must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid TRASH_PURGE_INTERVAL")
+	}
+	archiveAfterDays, err := envInt64("ARCHIVE_AFTER_DAYS", defaultArchiveAfterDays)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid ARCHIVE_AFTER_DAYS")
+	}
+	archiveInterval, err := envDuration("ARCHIVE_INTERVAL", defaultArchiveInterval)
+	if err == nil && archiveInterval <= 0 {
+		err = errors.New("interval must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid ARCHIVE_INTERVAL")
 	}
-	logger.Debug().Msg("migrations are applied successfully")
-
-	repo := repository.NewTaskRepository(db, logger)
-	logger.Debug().Msg("created  repository")
-
-	taskService := service.NewTaskService(repo, logger)
-	logger.Debug().Msg("created  sercise")
-
-	restTaskServer := rest.NewTaskHandler(taskService, logger)
-	logger.Debug().Msg("created rest server")
 
 	go func() {
 		defer func() {
@@ -61,12 +105,10 @@ func Run() {
 				log.Fatal().Err(errors.New("panic recovered"))
 			}
 		}()
-		rest.Run(restTaskServer, restPort)
+		rest.Run(srv.rest, restPort)
 	}()
 	logger.Info().Msgf("rest server is running on port: %s", restPort)
 
-	grpcTaskServer := grpc.NewTaskHandler(taskService, logger)
-	logger.Debug().Msg("created grpc server")
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -74,15 +116,383 @@ func Run() {
 			}
 		}()
 
-		grpc.Run(grpcTaskServer, grpcPort)
+		grpc.Run(srv.grpc, grpcPort)
 	}()
 	logger.Info().Msgf("grpc server is running on port: %s", grpcPort)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go srv.tasks.RunPurge(ctx, trashRetention, trashPurgeInterval)
+	logger.Info().Msgf("trash is purged every %s, retention: %s", trashPurgeInterval, trashRetention)
+
+	if archiveAfterDays > 0 {
+		go srv.tasks.RunArchiver(ctx, time.Duration(archiveAfterDays)*24*time.Hour, archiveInterval)
+		logger.Info().Msgf("tasks done for %d days are archived every %s", archiveAfterDays, archiveInterval)
+	}
+
+	for _, job := range srv.jobs {
+		go job(ctx)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 }
 
+// postgresServer serves every feature, the data is kept in Postgres.
+func postgresServer(logger *zerolog.Logger) server {
+	dsn := os.Getenv("DATABASE_URL")
+	attachmentsDir := os.Getenv("ATTACHMENTS_DIR")
+
+	conf := repository.Config{
+		DSN: dsn,
+	}
+
+	db := repository.Connection(conf, logger)
+
+	err := runMigrations(logger, db, "postgres", "")
+	if err != nil {
+		logger.Fatal().Err(err).Msg("can't run migrations")
+	}
+	logger.Debug().Msg("migrations are applied successfully")
+
+	repo, taskCache := cachedTaskRepository(repository.NewTaskRepository(db, logger), logger)
+	workflowRepo := repository.NewWorkflowRepository(db, logger)
+	commentRepo := repository.NewCommentRepository(db, logger)
+	attachmentRepo := repository.NewAttachmentRepository(db, logger)
+	historyRepo := repository.NewHistoryRepository(db, logger)
+	projectRepo := repository.NewProjectRepository(db, logger)
+	workLogRepo := repository.NewWorkLogRepository(db, logger)
+	checklistRepo := repository.NewChecklistRepository(db, logger)
+	customFieldRepo := repository.NewCustomFieldRepository(db, logger)
+	templateRepo := repository.NewTemplateRepository(db, logger)
+	webhookRepo := repository.NewWebhookRepository(db, logger)
+	watcherRepo := repository.NewWatcherRepository(db, logger)
+	userRepo := repository.NewUserRepository(db, logger)
+	outboxRepo := repository.NewOutboxRepository(db, logger)
+	notifier := repository.NewEventNotifier(db, logger)
+	logger.Debug().Msg("created  repository")
+
+	blobStore, err := blobstore.NewLocalStore(attachmentsDir)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("can't create blob store")
+	}
+
+	attachmentsMaxSize, err := envInt64("ATTACHMENTS_MAX_SIZE", defaultAttachmentsMaxSize)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid ATTACHMENTS_MAX_SIZE")
+	}
+
+	workflowService := newWorkflowService(workflowRepo, logger)
+	userService := service.NewUserService(userRepo, logger)
+	watcherService := service.NewWatcherService(watcherRepo, userService, repo, logger)
+	commentService := service.NewCommentService(commentRepo, repo, logger).
+		WithMentions(watcherService)
+	attachmentService := service.NewAttachmentService(attachmentRepo, blobStore, repo, attachmentsMaxSize, logger)
+	events := eventbus.New(eventHistorySize, eventSubscriberBuffer)
+	taskService := service.NewTaskService(repo, workflowService, historyRepo, logger).
+		WithCleaners(attachmentService).
+		WithEvents(events)
+	projectService := service.NewProjectService(projectRepo, taskService, logger)
+	customFieldService := service.NewCustomFieldService(customFieldRepo, projectService, logger)
+	taskService.WithProjects(projectService).
+		WithCustomFields(customFieldService).
+		WithMentions(watcherService)
+	workLogService := service.NewWorkLogService(workLogRepo, repo, logger)
+	checklistService := service.NewChecklistService(checklistRepo, repo, logger)
+	templateService := service.NewTemplateService(templateRepo, taskService, logger)
+	if taskCache != nil {
+		// these write to the tasks on their own, past the cached repository
+		watcherService.WithInvalidation(taskCache)
+		customFieldService.WithInvalidation(taskCache)
+		projectService.WithInvalidation(taskCache)
+		checklistService.WithInvalidation(taskCache)
+	}
+
+	webhookInterval, err := envDuration("WEBHOOK_INTERVAL", defaultWebhookInterval)
+	if err == nil && webhookInterval <= 0 {
+		err = errors.New("interval must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid WEBHOOK_INTERVAL")
+	}
+	webhookMaxAttempts, err := envInt64("WEBHOOK_MAX_ATTEMPTS", defaultWebhookMaxAttempts)
+	if err == nil && webhookMaxAttempts <= 0 {
+		err = errors.New("max attempts must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid WEBHOOK_MAX_ATTEMPTS")
+	}
+	webhookTimeout, err := envDuration("WEBHOOK_TIMEOUT", defaultWebhookTimeout)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid WEBHOOK_TIMEOUT")
+	}
+	webhookService := service.NewWebhookService(webhookRepo, &http.Client{Timeout: webhookTimeout}, int(webhookMaxAttempts), logger)
+	outboxInterval, err := envDuration("OUTBOX_INTERVAL", defaultOutboxInterval)
+	if err == nil && outboxInterval <= 0 {
+		err = errors.New("interval must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid OUTBOX_INTERVAL")
+	}
+	outboxRetention, err := envDuration("OUTBOX_RETENTION", defaultOutboxRetention)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid OUTBOX_RETENTION")
+	}
+	publish := func(ctx context.Context, event models.TaskEvent) {
+		events.Publish(event)
+	}
+	sinkNames := outboxSinkNames()
+	if slices.Contains(sinkNames, "bus") && slices.Contains(sinkNames, "notify") {
+		// the listener publishes the events relayed by this instance as well
+		logger.Fatal().Msg("invalid OUTBOX_SINKS: notify publishes to the bus too, drop bus")
+	}
+	sinks, err := outboxSinks(sinkNames, map[string]service.EventSink{
+		"bus": service.SinkFunc(func(ctx context.Context, event models.TaskEvent) error {
+			publish(ctx, event)
+			return nil
+		}),
+		"notify":   service.SinkFunc(notifier.Notify),
+		"webhooks": service.SinkFunc(webhookService.Enqueue),
+		"log":      service.LogSink(logger),
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid OUTBOX_SINKS")
+	}
+	outboxRelay := service.NewOutboxRelay(outboxRepo, sinks, logger)
+	logger.Debug().Msg("created  sercise")
+
+	restTaskServer := rest.NewTaskHandler(taskService, logger).
+		WithWorkflow(workflowService).
+		WithComments(commentService).
+		WithAttachments(attachmentService).
+		WithProjects(projectService).
+		WithWorkLogs(workLogService).
+		WithChecklists(checklistService).
+		WithCustomFields(customFieldService).
+		WithTemplates(templateService).
+		WithWatchers(watcherService).
+		WithUsers(userService).
+		WithWebhooks(webhookService)
+	logger.Debug().Msg("created rest server")
+
+	grpcTaskServer := grpc.NewTaskHandler(taskService, logger).
+		WithWorkflow(workflowService).
+		WithComments(commentService).
+		WithWorkLogs(workLogService).
+		WithChecklists(checklistService)
+	logger.Debug().Msg("created grpc server")
+
+	jobs := []func(ctx context.Context){
+		func(ctx context.Context) { outboxRelay.RunRelay(ctx, outboxInterval, outboxRetention) },
+		func(ctx context.Context) { webhookService.RunDeliveries(ctx, webhookInterval) },
+	}
+	logger.Info().Msgf("outbox events are relayed every %s", outboxInterval)
+	logger.Info().Msgf("webhooks are delivered every %s", webhookInterval)
+	if slices.Contains(sinkNames, "notify") {
+		listen := publish
+		if taskCache != nil {
+			listen = func(ctx context.Context, event models.TaskEvent) {
+				taskCache.Invalidate(ctx, event)
+				publish(ctx, event)
+			}
+		}
+		jobs = append(jobs, func(ctx context.Context) { notifier.Listen(ctx, listen) })
+		logger.Info().Msg("task events of all instances are listened to")
+	}
+
+	return server{
+		rest:  restTaskServer,
+		grpc:  grpcTaskServer,
+		tasks: taskService,
+		jobs:  jobs,
+	}
+}
+
+// memoryServer keeps the data in memory, which is lost on exit. Only the
+// tasks, the workflow, the task history and the task events of this process
+// are served, the other features answer they aren't available.
+func memoryServer(logger *zerolog.Logger) server {
+	db := memory.NewDB()
+	events := eventbus.New(eventHistorySize, eventSubscriberBuffer)
+	workflowService := newWorkflowService(memory.NewWorkflowRepository(db, logger), logger)
+	repo := publish.NewTaskRepository(memory.NewTaskRepository(db, logger), events)
+	taskService := service.NewTaskService(repo, workflowService, memory.NewHistoryRepository(db, logger), logger).
+		WithEvents(events)
+	logger.Info().Msg("tasks are kept in memory")
+
+	return server{
+		rest: rest.NewTaskHandler(taskService, logger).
+			WithWorkflow(workflowService),
+		grpc: grpc.NewTaskHandler(taskService, logger).
+			WithWorkflow(workflowService),
+		tasks: taskService,
+	}
+}
+
+// sqliteServer keeps the data in the SQLite file at SQLITE_PATH. Like in
+// memory, only the tasks, the workflow, the task history and the task events
+// of this process are served.
+func sqliteServer(logger *zerolog.Logger) server {
+	path := os.Getenv("SQLITE_PATH")
+	if path == "" {
+		path = defaultSQLitePath
+	}
+
+	db, err := sqlite.Connection(sqlite.Config{Path: path}, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("can't open sqlite database")
+	}
+
+	err = runMigrations(logger, db, "sqlite3", "sqlite")
+	if err != nil {
+		logger.Fatal().Err(err).Msg("can't run migrations")
+	}
+	logger.Debug().Msg("migrations are applied successfully")
+
+	events := eventbus.New(eventHistorySize, eventSubscriberBuffer)
+	workflowService := newWorkflowService(sqlite.NewWorkflowRepository(db, logger), logger)
+	repo, _ := cachedTaskRepository(publish.NewTaskRepository(sqlite.NewTaskRepository(db, logger), events), logger)
+	taskService := service.NewTaskService(repo, workflowService, sqlite.NewHistoryRepository(db, logger), logger).
+		WithEvents(events)
+	logger.Info().Msgf("tasks are kept in sqlite: %s", path)
+
+	return server{
+		rest: rest.NewTaskHandler(taskService, logger).
+			WithWorkflow(workflowService),
+		grpc: grpc.NewTaskHandler(taskService, logger).
+			WithWorkflow(workflowService),
+		tasks: taskService,
+	}
+}
+
+// newWorkflowService returns the workflow service, which keeps the workflow
+// for WORKFLOW_CACHE_TTL unless it is 0.
+func newWorkflowService(repo service.WorkflowRepo, logger *zerolog.Logger) *service.WorkflowService {
+	workflowService := service.NewWorkflowService(repo, logger)
+	ttl, err := envDuration("WORKFLOW_CACHE_TTL", defaultWorkflowCacheTTL)
+	if err == nil && ttl < 0 {
+		err = errors.New("ttl can't be negative")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid WORKFLOW_CACHE_TTL")
+	}
+	if ttl > 0 {
+		workflowService.WithCache(ttl)
+	}
+	return workflowService
+}
+
+// cachedTaskRepository puts the read-through cache in front of repo when
+// CACHE_SIZE is set, the returned cache is nil otherwise. Its counters are
+// published as the task_cache expvar.
+func cachedTaskRepository(repo service.Repo, logger *zerolog.Logger) (service.Repo, *cache.TaskRepository) {
+	size, err := envInt64("CACHE_SIZE", 0)
+	if err == nil && size < 0 {
+		err = errors.New("size can't be negative")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid CACHE_SIZE")
+	}
+	if size == 0 {
+		return repo, nil
+	}
+	maxBytes, err := envInt64("CACHE_MAX_BYTES", defaultCacheMaxBytes)
+	if err == nil && maxBytes <= 0 {
+		err = errors.New("max bytes must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid CACHE_MAX_BYTES")
+	}
+	ttl, err := envDuration("CACHE_TTL", defaultCacheTTL)
+	if err == nil && ttl <= 0 {
+		err = errors.New("ttl must be positive")
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid CACHE_TTL")
+	}
+
+	lru := cache.NewLRU(int(size), maxBytes)
+	taskCache := cache.NewTaskRepository(repo, lru, ttl, logger)
+	expvar.Publish("task_cache", expvar.Func(func() any {
+		return map[string]any{
+			"repository": taskCache.Metrics(),
+			"lru":        lru.Metrics(),
+		}
+	}))
+	logger.Info().Msgf("up to %d tasks and lists are cached for %s", size, ttl)
+
+	return taskCache, taskCache
+}
+
+const (
+	defaultSQLitePath         = "tasks.db"
+	defaultCacheMaxBytes      = 32 << 20
+	defaultCacheTTL           = 30 * time.Second
+	defaultWorkflowCacheTTL   = time.Minute
+	defaultAttachmentsMaxSize = 10 << 20
+	defaultTrashRetention     = 30 * 24 * time.Hour
+	defaultTrashPurgeInterval = time.Hour
+	defaultArchiveAfterDays   = 30
+	defaultArchiveInterval    = time.Hour
+	defaultWebhookInterval    = 5 * time.Second
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookTimeout     = 10 * time.Second
+	defaultOutboxInterval     = time.Second
+	defaultOutboxRetention    = 24 * time.Hour
+	defaultOutboxSinks        = "bus,webhooks"
+	// task events kept for resuming watchers and the events a watcher may lag behind
+	eventHistorySize      = 1000
+	eventSubscriberBuffer = 100
+)
+
+// envInt64 reads an integer environment variable, falling back to def when it's unset.
+func envInt64(key string, def int64) (int64, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+// envDuration reads a duration environment variable such as "720h", falling back to def when it's unset.
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// outboxSinkNames reads the comma separated OUTBOX_SINKS such as "bus,log",
+// falling back to defaultOutboxSinks when it's unset.
+func outboxSinkNames() []string {
+	raw := os.Getenv("OUTBOX_SINKS")
+	if raw == "" {
+		raw = defaultOutboxSinks
+	}
+
+	names := strings.Split(raw, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// outboxSinks picks the named sinks.
+func outboxSinks(names []string, available map[string]service.EventSink) ([]service.EventSink, error) {
+	var sinks []service.EventSink
+	for _, name := range names {
+		sink, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sink: %q", name)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
 func NewLogger() (*zerolog.Logger, error) {
 	loggerLevel := os.Getenv("LOGGER_LEVEL")
 	path := os.Getenv("LOG_PATH")
@@ -110,7 +520,9 @@ func NewLogger() (*zerolog.Logger, error) {
 	return &logger, nil
 }
 
-func runMigrations(logger *zerolog.Logger, dbConn *bun.DB) error {
+// runMigrations applies the migrations in MIGRATION_DIR joined with subdir,
+// dialect is the goose dialect of the database.
+func runMigrations(logger *zerolog.Logger, dbConn *bun.DB, dialect string, subdir string) error {
 	upMigration, err := strconv.ParseBool(os.Getenv("RUN_MIGRATION"))
 	if err != nil {
 		return err
@@ -123,9 +535,14 @@ func runMigrations(logger *zerolog.Logger, dbConn *bun.DB) error {
 	if migrationDir == "" {
 		return errors.New("no migration dir provided; skipping migrations")
 	}
+	migrationDir = filepath.Join(migrationDir, subdir)
 
 	logger.Debug().Msgf("loaded migrations dir: %s", migrationDir)
 
+	err = goose.SetDialect(dialect)
+	if err != nil {
+		return err
+	}
 	err = goose.Up(dbConn.DB, migrationDir)
 	if err != nil {
 		return err
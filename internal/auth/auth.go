@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TODO: replace with token based auth
+const (
+	// Header carries the ID of the user making a REST request.
+	Header = "X-User-ID"
+	// MetadataKey carries the ID of the user making a gRPC request.
+	MetadataKey = "x-user-id"
+)
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx that carries the user ID.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserID returns the user ID stored in ctx by WithUser.
+func UserID(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// Parse validates a user ID received from a client.
+func Parse(raw string) (string, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
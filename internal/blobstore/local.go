@@ -0,0 +1,124 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// LocalStore keeps blobs as files under the root directory.
+// A key like "task/attachment" is stored as root/task/attachment.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// path resolves the key inside the root directory and rejects keys
+// which would escape it.
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if path == s.root || !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return path, nil
+}
+
+// Put writes the content to a temporary file first and renames it
+// into place, so readers never see a partially written blob.
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, &ctxReader{ctx: ctx, r: content})
+	if err != nil {
+		tmp.Close()
+		return written, err
+	}
+	if err := tmp.Close(); err != nil {
+		return written, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open blob %s: %w", key, err)
+	}
+	return file, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// DeletePrefix removes every blob whose key starts with the prefix directory.
+func (s *LocalStore) DeletePrefix(ctx context.Context, prefix string) error {
+	path, err := s.path(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// ctxReader stops copying once the context is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+)
+
+// Bus delivers task events to the subscribers within the process.
+// The last events are kept, so a subscriber can resume after the event it has seen.
+// Events keep their outbox IDs, which are the same on every instance, and an
+// event published again is dropped. The IDs aren't published in order, an
+// event committed late is relayed after events with greater IDs, so a
+// subscriber resumes at the position of its event on the bus.
+type Bus struct {
+	mu sync.Mutex
+	// recent holds the last events in the order they were published
+	recent []models.TaskEvent
+	// seen holds the IDs of the recent events
+	seen map[uint64]struct{}
+	// dropped is the ID of the event published right before the recent ones,
+	// a subscriber resuming after it gets all of them
+	dropped uint64
+	// last is the greatest ID published
+	last   uint64
+	size   int
+	buffer int
+	subs   map[chan models.TaskEvent]struct{}
+}
+
+// New returns a bus keeping size recent events. A subscriber lagging more
+// than buffer events behind is dropped and has to resume.
+func New(size, buffer int) *Bus {
+	return &Bus{
+		seen:   make(map[uint64]struct{}),
+		size:   size,
+		buffer: buffer,
+		subs:   make(map[chan models.TaskEvent]struct{}),
+	}
+}
+
+// Publish sends the event to the subscribers unless an event with its ID
+// is among the recent ones, and reports whether it was sent.
+func (b *Bus) Publish(event models.TaskEvent) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.seen[event.ID]; ok {
+		return false
+	}
+	if len(b.recent) == 0 && b.last == 0 && event.ID > 0 {
+		// the events before the first one were published before the bus started
+		b.dropped = event.ID - 1
+	}
+
+	b.recent = append(b.recent, event)
+	b.seen[event.ID] = struct{}{}
+	b.last = max(b.last, event.ID)
+	if len(b.recent) > b.size {
+		for _, old := range b.recent[:len(b.recent)-b.size] {
+			delete(b.seen, old.ID)
+			b.dropped = old.ID
+		}
+		b.recent = b.recent[len(b.recent)-b.size:]
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return true
+}
+
+// Subscribe returns a channel receiving the events published after the event
+// with afterID, 0 starts with the next event. The recent events published
+// after it are replayed first, in the order they were published. An ID
+// greater than all published ones hasn't reached this instance yet and
+// starts with the next event as well. The channel is closed when the
+// subscriber is too slow or after cancel is called.
+func (b *Bus) Subscribe(afterID uint64) (<-chan models.TaskEvent, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []models.TaskEvent
+	switch {
+	case afterID == 0 || (len(b.recent) > 0 && afterID > b.last):
+		// only the next events
+	case len(b.recent) > 0 && afterID == b.dropped:
+		replay = b.recent
+	default:
+		i := slices.IndexFunc(b.recent, func(event models.TaskEvent) bool { return event.ID == afterID })
+		if i < 0 {
+			// the events published after it may no longer be kept
+			return nil, nil, models.ErrEventsExpired
+		}
+		replay = b.recent[i+1:]
+	}
+
+	ch := make(chan models.TaskEvent, len(replay)+b.buffer)
+	for _, event := range replay {
+		ch <- event
+	}
+	b.subs[ch] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, nil
+}
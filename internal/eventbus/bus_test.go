@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+)
+
+func publish(b *Bus, ids ...uint64) {
+	for _, id := range ids {
+		b.Publish(models.TaskEvent{ID: id, Type: models.EventUpdated})
+	}
+}
+
+// received drains the events already sent to the channel.
+func received(ch <-chan models.TaskEvent) []uint64 {
+	var ids []uint64
+	for {
+		select {
+		case event := <-ch:
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestBusKeepsOutboxIDs(t *testing.T) {
+	b := New(10, 10)
+	publish(b, 41, 43)
+
+	ch, cancel, err := b.Subscribe(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	// an event relayed out of order is passed on, a repeated one isn't
+	publish(b, 42, 43, 44)
+	if got, want := received(ch), []uint64{42, 44}; !slices.Equal(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+func TestBusResumesAfterID(t *testing.T) {
+	b := New(3, 10)
+	publish(b, 10, 12, 11, 13)
+
+	ch, cancel, err := b.Subscribe(11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if got, want := received(ch), []uint64{13}; !slices.Equal(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+
+	// the last event no longer kept is followed by all the kept ones
+	ch, cancel, err = b.Subscribe(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if got, want := received(ch), []uint64{12, 11, 13}; !slices.Equal(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+
+	// the event 10 isn't kept anymore
+	if _, _, err := b.Subscribe(9); !errors.Is(err, models.ErrEventsExpired) {
+		t.Errorf("Subscribe(9): got error %v, want %v", err, models.ErrEventsExpired)
+	}
+	// an ID this instance hasn't got yet streams the new events
+	if _, _, err := b.Subscribe(20); err != nil {
+		t.Errorf("Subscribe(20): %v", err)
+	}
+}
+
+func TestBusResumesAtDeliveryPosition(t *testing.T) {
+	b := New(10, 10)
+	// the event 6 was committed after the event 7
+	publish(b, 5, 7, 6)
+
+	ch, cancel, err := b.Subscribe(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if got, want := received(ch), []uint64{6}; !slices.Equal(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+}
+
+func TestBusExpiresBeforeFirstEvent(t *testing.T) {
+	b := New(10, 10)
+	if _, _, err := b.Subscribe(5); !errors.Is(err, models.ErrEventsExpired) {
+		t.Errorf("Subscribe on an empty bus: got error %v, want %v", err, models.ErrEventsExpired)
+	}
+
+	publish(b, 7)
+	if _, _, err := b.Subscribe(5); !errors.Is(err, models.ErrEventsExpired) {
+		t.Errorf("Subscribe before the first event: got error %v, want %v", err, models.ErrEventsExpired)
+	}
+	if _, _, err := b.Subscribe(6); err != nil {
+		t.Errorf("Subscribe(6): %v", err)
+	}
+}
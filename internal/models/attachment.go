@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	ID          string
+	TaskID      string `validate:"uuid4"`
+	Filename    string `validate:"required,max=255"`
+	ContentType string `validate:"max=255"`
+	Size        int64
+	// Checksum is a hex encoded SHA-256 of the content.
+	Checksum   string `validate:"omitempty,len=64,hexadecimal"`
+	StorageKey string `json:"-" swaggerignore:"true"`
+	UploaderID string
+	Created    time.Time
+}
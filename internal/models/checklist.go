@@ -0,0 +1,15 @@
+package models
+
+type ChecklistItem struct {
+	ID       string `validate:"omitempty,uuid4"`
+	TaskID   string `validate:"uuid4"`
+	Text     string `validate:"required,max=1000"`
+	Checked  bool
+	Position int
+}
+
+// ChecklistProgress counts the checked items of a task checklist.
+type ChecklistProgress struct {
+	Checked int
+	Total   int
+}
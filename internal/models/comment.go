@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Comment struct {
+	ID       string `validate:"omitempty,uuid4"`
+	TaskID   string `validate:"uuid4"`
+	AuthorID string `validate:"omitempty,uuid4"`
+	Body     string `validate:"required,max=10000"`
+	Created  time.Time
+	Updated  time.Time
+	Edited   bool
+}
+
+// CommentEdit keeps a previous version of a comment body.
+type CommentEdit struct {
+	ID        string
+	CommentID string
+	EditorID  string
+	Body      string
+	Edited    time.Time
+}
+
+type CommentList struct {
+	Comments []Comment
+	Total    int
+}
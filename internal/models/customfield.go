@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldDate   FieldType = "date"
+	FieldEnum   FieldType = "enum"
+	FieldUser   FieldType = "user"
+)
+
+// DateLayout is the format of date custom field values.
+const DateLayout = "2006-01-02"
+
+// FieldDefinition describes a custom field of the tasks in a project.
+// Options lists the allowed values of an enum field.
+type FieldDefinition struct {
+	ID        string
+	ProjectID string
+	Name      string    `validate:"required,max=100"`
+	Type      FieldType `validate:"required,oneof=string number date enum user"`
+	Options   []string  `validate:"omitempty,dive,required,max=200"`
+	Required  bool
+	Created   time.Time
+}
@@ -3,5 +3,45 @@ package models
 import "errors"
 
 var (
-	ErrTaskNotFound = errors.New("task doesn't exist")
+	ErrTaskNotFound          = errors.New("task doesn't exist")
+	ErrStatusNotFound        = errors.New("status doesn't exist")
+	ErrStatusExists          = errors.New("status already exists")
+	ErrStatusInUse           = errors.New("status is used by tasks")
+	ErrTransitionNotFound    = errors.New("transition doesn't exist")
+	ErrTransitionNotAllowed  = errors.New("status transition isn't allowed")
+	ErrCommentNotFound       = errors.New("comment doesn't exist")
+	ErrUnauthenticated       = errors.New("user isn't authenticated")
+	ErrForbidden             = errors.New("action isn't allowed for the user")
+	ErrAttachmentNotFound    = errors.New("attachment doesn't exist")
+	ErrAttachmentTooLarge    = errors.New("attachment is too large")
+	ErrChecksumMismatch      = errors.New("checksum doesn't match the content")
+	ErrInvalidRRule          = errors.New("invalid recurrence rule")
+	ErrDueRequired           = errors.New("recurring task requires a due date")
+	ErrNotRecurring          = errors.New("task isn't recurring")
+	ErrProjectNotFound       = errors.New("project doesn't exist")
+	ErrMemberNotFound        = errors.New("user isn't a project member")
+	ErrMemberExists          = errors.New("user is already a project member")
+	ErrProjectOwner          = errors.New("project owner can't be removed")
+	ErrTimerRunning          = errors.New("user already has a running timer")
+	ErrTimerNotFound         = errors.New("user has no running timer")
+	ErrInvalidRange          = errors.New("invalid date range")
+	ErrChecklistItemNotFound = errors.New("checklist item doesn't exist")
+	ErrInvalidOrder          = errors.New("order must list every checklist item once")
+	ErrFieldNotFound         = errors.New("custom field doesn't exist")
+	ErrFieldExists           = errors.New("custom field already exists")
+	ErrInvalidField          = errors.New("invalid custom field")
+	ErrProjectRequired       = errors.New("custom fields require the task to be in a project")
+	ErrRevisionNotFound      = errors.New("revision doesn't exist")
+	ErrNotRevertible         = errors.New("revision can't be reverted")
+	ErrRevertConflict        = errors.New("fields of the revision have changed since")
+	ErrParentNotFound        = errors.New("parent task doesn't exist")
+	ErrTemplateNotFound      = errors.New("template doesn't exist")
+	ErrMissingVariable       = errors.New("template variable isn't set")
+	ErrInvalidUsername       = errors.New("username must be 3-32 lowercase letters, digits or underscores")
+	ErrUsernameTaken         = errors.New("username is already taken")
+	ErrEventsExpired         = errors.New("events after the given ID are no longer available")
+	ErrWebhookNotFound       = errors.New("webhook doesn't exist")
+	ErrDeliveryNotFound      = errors.New("webhook delivery doesn't exist")
+	ErrInvalidSyncToken      = errors.New("invalid sync token")
+	ErrSyncTokenExpired      = errors.New("changes after the sync token are no longer available")
 )
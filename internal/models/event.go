@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// TaskEvent is a change of a task. The ID is the one of the outbox event, the
+// same on every instance, so a stream can be resumed after the last seen one
+// on any of them.
+type TaskEvent struct {
+	ID      uint64
+	Type    EventType
+	Task    Task
+	Created time.Time
+}
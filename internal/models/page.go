@@ -0,0 +1,25 @@
+package models
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type Page struct {
+	Limit  int `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" validate:"omitempty,min=0"`
+}
+
+// Normalize fills in the default limit and clamps it to the maximum.
+func (p Page) Normalize() Page {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+	return p
+}
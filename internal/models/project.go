@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type ProjectRole string
+
+const (
+	RoleOwner  ProjectRole = "owner"
+	RoleMember ProjectRole = "member"
+)
+
+// Project groups tasks. Only its members can see the project and its tasks.
+type Project struct {
+	ID          string `validate:"omitempty,uuid4"`
+	Name        string `validate:"required,max=200"`
+	Description string
+	OwnerID     string `validate:"omitempty,uuid4"`
+	Created     time.Time
+	Updated     time.Time
+}
+
+type ProjectMember struct {
+	ProjectID string
+	UserID    string `validate:"uuid4"`
+	Role      ProjectRole
+	Added     time.Time
+}
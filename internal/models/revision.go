@@ -0,0 +1,225 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type HistoryAction string
+
+const (
+	ActionCreated    HistoryAction = "created"
+	ActionUpdated    HistoryAction = "updated"
+	ActionAssigned   HistoryAction = "assigned"
+	ActionUnassigned HistoryAction = "unassigned"
+	ActionMoved      HistoryAction = "moved"
+	ActionDeleted    HistoryAction = "deleted"
+	ActionRestored   HistoryAction = "restored"
+	ActionArchived   HistoryAction = "archived"
+	ActionUnarchived HistoryAction = "unarchived"
+	ActionReverted   HistoryAction = "reverted"
+)
+
+// Revision records a single mutation of a task.
+type Revision struct {
+	ID      string
+	TaskID  string
+	ActorID string
+	Action  HistoryAction
+	Changes []FieldChange
+	Created time.Time
+}
+
+// NewRevision returns the revision the action of the actor made to the task,
+// its changes are the tracked fields which differ between old and new. It
+// reports false for an update changing none of them, which isn't kept.
+func NewRevision(actorID string, action HistoryAction, old, new Task) (Revision, bool) {
+	changes := DiffTasks(old, new)
+	if action == ActionUpdated && len(changes) == 0 {
+		return Revision{}, false
+	}
+	return Revision{
+		TaskID:  new.ID,
+		ActorID: actorID,
+		Action:  action,
+		Changes: changes,
+		Created: time.Now().UTC(),
+	}, true
+}
+
+// FieldChange holds the JSON values of a task field before and after a revision.
+// Field is the column name, e.g. "title" or "assignee_id", an empty value is null.
+type FieldChange struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// TrackedField reads and writes a task field as the JSON value kept in revisions.
+type TrackedField struct {
+	Name string
+	Get  func(task Task) any
+	Set  func(task *Task, value any) error
+}
+
+// TrackedFields are the task fields recorded in revisions, named after their columns.
+var TrackedFields = []TrackedField{
+	{"title", func(t Task) any { return stringJSON(t.Title) }, func(t *Task, v any) error { return setString(&t.Title, v) }},
+	{"description", func(t Task) any { return stringJSON(t.Description) }, func(t *Task, v any) error { return setString(&t.Description, v) }},
+	{"status", func(t Task) any { return stringJSON(t.Status.String()) }, func(t *Task, v any) error { return setString((*string)(&t.Status), v) }},
+	{"assignee_id", func(t Task) any { return stringJSON(t.AssigneeID) }, func(t *Task, v any) error { return setString(&t.AssigneeID, v) }},
+	{"due_at", func(t Task) any { return timeJSON(t.Due) }, func(t *Task, v any) error { return setTime(&t.Due, v) }},
+	{"rrule", func(t Task) any { return stringJSON(t.RRule) }, func(t *Task, v any) error { return setString(&t.RRule, v) }},
+	{"project_id", func(t Task) any { return stringJSON(t.ProjectID) }, func(t *Task, v any) error { return setString(&t.ProjectID, v) }},
+	{"estimate_minutes", func(t Task) any { return intJSON(t.EstimateMinutes) }, func(t *Task, v any) error { return setInt(&t.EstimateMinutes, v) }},
+	{"story_points", func(t Task) any { return intJSON(t.StoryPoints) }, func(t *Task, v any) error { return setInt(&t.StoryPoints, v) }},
+	{"custom_fields", func(t Task) any { return mapJSON(t.CustomFields) }, func(t *Task, v any) error { return setMap(&t.CustomFields, v) }},
+	{"labels", func(t Task) any { return listJSON(t.Labels) }, func(t *Task, v any) error { return setList(&t.Labels, v) }},
+	{"archived_at", func(t Task) any { return timeJSON(t.Archived) }, func(t *Task, v any) error { return setTime(&t.Archived, v) }},
+}
+
+// DiffTasks lists the tracked fields which differ between the two versions of a task.
+func DiffTasks(old, new Task) []FieldChange {
+	var changes []FieldChange
+	for _, field := range TrackedFields {
+		before, after := field.Get(old), field.Get(new)
+		if !reflect.DeepEqual(before, after) {
+			changes = append(changes, FieldChange{Field: field.Name, Old: before, New: after})
+		}
+	}
+	return changes
+}
+
+// LookupField returns the tracked field with the name.
+func LookupField(name string) (TrackedField, bool) {
+	for _, field := range TrackedFields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return TrackedField{}, false
+}
+
+// Zero values are kept as null, numbers as float64 and times as RFC 3339
+// strings, so fresh values compare equal to the ones decoded from revisions.
+
+func stringJSON(val string) any {
+	if val == "" {
+		return nil
+	}
+	return val
+}
+
+func intJSON(val int) any {
+	if val == 0 {
+		return nil
+	}
+	return float64(val)
+}
+
+func timeJSON(val time.Time) any {
+	if val.IsZero() {
+		return nil
+	}
+	return val.UTC().Format(time.RFC3339Nano)
+}
+
+func mapJSON(val map[string]any) any {
+	if len(val) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return nil
+	}
+	var res map[string]any
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil
+	}
+	return res
+}
+
+func listJSON(val []string) any {
+	if len(val) == 0 {
+		return nil
+	}
+	res := make([]any, len(val))
+	for i, item := range val {
+		res[i] = item
+	}
+	return res
+}
+
+func setString(dst *string, val any) error {
+	switch v := val.(type) {
+	case nil:
+		*dst = ""
+	case string:
+		*dst = v
+	default:
+		return fmt.Errorf("unexpected value %v", val)
+	}
+	return nil
+}
+
+func setInt(dst *int, val any) error {
+	switch v := val.(type) {
+	case nil:
+		*dst = 0
+	case float64:
+		*dst = int(v)
+	default:
+		return fmt.Errorf("unexpected value %v", val)
+	}
+	return nil
+}
+
+func setTime(dst *time.Time, val any) error {
+	switch v := val.(type) {
+	case nil:
+		*dst = time.Time{}
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return err
+		}
+		*dst = parsed
+	default:
+		return fmt.Errorf("unexpected value %v", val)
+	}
+	return nil
+}
+
+func setMap(dst *map[string]any, val any) error {
+	switch v := val.(type) {
+	case nil:
+		*dst = nil
+	case map[string]any:
+		*dst = v
+	default:
+		return fmt.Errorf("unexpected value %v", val)
+	}
+	return nil
+}
+
+func setList(dst *[]string, val any) error {
+	switch v := val.(type) {
+	case nil:
+		*dst = []string{}
+	case []any:
+		res := make([]string, len(v))
+		for i, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("unexpected value %v", val)
+			}
+			res[i] = str
+		}
+		*dst = res
+	default:
+		return fmt.Errorf("unexpected value %v", val)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import "strconv"
+
+// TaskChanges are the tasks created or updated after a sync token and the IDs
+// of the deleted ones. Token is passed to the next sync, More tells whether
+// there are further changes after it.
+type TaskChanges struct {
+	Tasks   []Task
+	Deleted []string
+	Token   string
+	More    bool
+}
+
+// SyncToken returns the token of a position in the task change sequence.
+func SyncToken(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+// ParseSyncToken returns the position of a sync token, an empty token starts from the beginning.
+func ParseSyncToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidSyncToken
+	}
+	return seq, nil
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
 
 type TaskStatus string
 
@@ -9,32 +14,142 @@ const (
 	Done       TaskStatus = "done"
 )
 
+// AssigneeMe is resolved to the authenticated user in TaskFilter.AssigneeID.
+const AssigneeMe = "me"
+
+// Task is a unit of work tracked by the service.
 type Task struct {
 	ID          string `validate:"omitempty,uuid4"`
 	Title       string
 	Description string
 	Created     time.Time
 	Updated     time.Time
-	Status      TaskStatus `validate:"omitempty,oneof=in_progress done"`
-	OwnerID     string     `validate:"uuid4"`
+	Status      TaskStatus `validate:"omitempty,max=100"`
+	OwnerID     string     `validate:"omitempty,uuid4"`
+	AssigneeID  string     `validate:"omitempty,uuid4"`
+	Due         time.Time  `validate:"required_with=RRule"`
+	// RRule is an RFC 5545 recurrence rule, e.g. "FREQ=WEEKLY;BYDAY=MO". When a
+	// recurring task is done the next occurrence is created with the following due date.
+	RRule     string `validate:"omitempty,max=500"`
+	ProjectID string `validate:"omitempty,uuid4"`
+	// EstimateMinutes is the planned effort, the logged time is subtracted from it in TaskStats
+	EstimateMinutes int `validate:"min=0,max=525600"`
+	StoryPoints     int `validate:"min=0,max=100"`
+
+	ChecklistProgress ChecklistProgress
+	// CustomFields holds values of the fields defined in the task project, keyed by field name
+	CustomFields map[string]any
+	// Deleted is set while the task is in the trash
+	Deleted time.Time
+	// Archived is set while the task is archived, independently of its status
+	Archived time.Time
+	// ParentID makes the task a subtask, it is set only on creation
+	ParentID string   `validate:"omitempty,uuid4"`
+	Labels   []string `validate:"omitempty,max=20,dive,required,max=50"`
+	// Watchers are the IDs of users following the task, they receive its notifications
+	Watchers []string
 }
 
+// TaskFilter selects the listed tasks, the empty fields match any task.
 type TaskFilter struct {
 	ID          []string `form:"id" validate:"omitempty,dive,uuid4"`
 	Title       string   `form:"title"`
 	Description string   `form:"description"`
-	Status      string   `form:"status" validate:"omitempty,oneof=in_progress done"`
+	Status      string   `form:"status" validate:"omitempty,max=100"`
 	OwnerID     string   `form:"owner_id" validate:"omitempty,uuid4"`
+	// AssigneeID also accepts AssigneeMe for tasks assigned to the current user
+	AssigneeID string `form:"assigned_to" validate:"omitempty,uuid4|eq=me"`
+	ProjectID  string `form:"project_id" validate:"omitempty,uuid4"`
+	// CustomFields matches tasks having the custom field equal to the value
+	CustomFields map[string]string `form:"-"`
+	// IncludeArchived lists archived tasks too, they are left out by default
+	IncludeArchived bool   `form:"include_archived"`
+	Label           string `form:"label" validate:"omitempty,max=50"`
+	ParentID        string `form:"parent_id" validate:"omitempty,uuid4"`
 }
 
 type TaskUpdate struct {
 	ID          string `validate:"uuid4"`
 	Title       string
 	Description string
-	Status      string `validate:"required,oneof=in_progress done"`
+	Status      string `validate:"required,max=100"`
 	OwnerID     string `validate:"omitempty,uuid4"`
 }
 
+type StatsGroup string
+
+const (
+	StatsByStatus  StatsGroup = "status"
+	StatsByOwner   StatsGroup = "owner"
+	StatsByProject StatsGroup = "project"
+)
+
+// TaskStatsFilter groups the tasks matching the filter, by status if GroupBy is empty.
+type TaskStatsFilter struct {
+	TaskFilter
+	GroupBy StatsGroup `form:"group_by" validate:"omitempty,oneof=status owner project"`
+}
+
+// TaskStats is a rollup of a group of tasks. Open values exclude tasks in
+// closed statuses, RemainingMinutes is the estimate minus the logged time.
+type TaskStats struct {
+	Key              string
+	Tasks            int
+	StoryPoints      int
+	OpenStoryPoints  int
+	EstimateMinutes  int
+	RemainingMinutes int
+}
+
 func (t TaskStatus) String() string {
 	return string(t)
 }
+
+// Match reports whether the task matches the filter with the semantics of
+// the Postgres repository: title and description match a case-insensitive
+// substring, the other fields match exactly. It serves the filters applied
+// outside of a database, to task events and in memory.
+func (f TaskFilter) Match(task Task) bool {
+	contains := func(s, substr string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+	}
+
+	switch {
+	case len(f.ID) > 0 && !slices.Contains(f.ID, task.ID),
+		f.Title != "" && !contains(task.Title, f.Title),
+		f.Description != "" && !contains(task.Description, f.Description),
+		f.Status != "" && f.Status != task.Status.String(),
+		f.OwnerID != "" && f.OwnerID != task.OwnerID,
+		f.AssigneeID != "" && f.AssigneeID != task.AssigneeID,
+		f.ProjectID != "" && f.ProjectID != task.ProjectID,
+		f.Label != "" && !slices.Contains(task.Labels, f.Label),
+		f.ParentID != "" && f.ParentID != task.ParentID,
+		!f.IncludeArchived && !task.Archived.IsZero():
+		return false
+	}
+
+	for name, value := range f.CustomFields {
+		text, ok := fieldText(task.CustomFields[name])
+		if !ok || text != value {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldText returns a custom field value as text the way the ->> operator
+// of Postgres does, false for a missing value.
+func fieldText(value any) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	default:
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(raw), true
+	}
+}
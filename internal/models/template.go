@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Template describes a task with its checklist and subtasks created at once.
+// Texts may contain placeholders like {{customer}}, which are substituted on
+// instantiation; Variables lists the placeholders used in the template.
+type Template struct {
+	ID        string
+	OwnerID   string
+	Name      string `validate:"required,max=200"`
+	Root      TemplateTask
+	Variables []string
+	Created   time.Time
+	Updated   time.Time
+}
+
+type TemplateTask struct {
+	Title       string `validate:"required,max=500"`
+	Description string
+	Labels      []string       `validate:"omitempty,max=20,dive,required,max=50"`
+	Checklist   []string       `validate:"omitempty,max=100,dive,required,max=1000"`
+	Subtasks    []TemplateTask `validate:"omitempty,max=50,dive"`
+}
+
+// TaskTree is a task together with its checklist items and subtasks.
+type TaskTree struct {
+	Task      Task
+	Checklist []string
+	Subtasks  []TaskTree
+}
@@ -0,0 +1,7 @@
+package models
+
+// User maps a user ID to the username used in @mentions.
+type User struct {
+	ID       string
+	Username string
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook subscribes a URL to the events of the tasks matching Filter.
+// Payloads are signed with HMAC-SHA256 using Secret, which is never returned.
+// An empty EventTypes subscribes to every event type.
+type Webhook struct {
+	ID         string
+	OwnerID    string
+	URL        string      `validate:"required,http_url,max=2000"`
+	Secret     string      `validate:"required,min=16,max=200"`
+	EventTypes []EventType `validate:"omitempty,dive,oneof=created updated deleted"`
+	Filter     TaskFilter
+	Created    time.Time
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is a webhook request with its attempts. A pending delivery is
+// attempted at NextAttempt, a failed one has run out of attempts.
+type Delivery struct {
+	ID           string
+	WebhookID    string
+	EventID      uint64
+	EventType    EventType
+	Payload      json.RawMessage `swaggertype:"object"`
+	Status       DeliveryStatus
+	Attempts     int
+	NextAttempt  time.Time
+	ResponseCode int
+	LastError    string
+	Created      time.Time
+	Delivered    time.Time
+}
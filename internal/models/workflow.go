@@ -0,0 +1,54 @@
+package models
+
+type StatusCategory string
+
+const (
+	CategoryOpen   StatusCategory = "open"
+	CategoryActive StatusCategory = "active"
+	CategoryClosed StatusCategory = "closed"
+)
+
+type WorkflowStatus struct {
+	Name     TaskStatus `validate:"required,max=100"`
+	Title    string
+	Category StatusCategory `validate:"required,oneof=open active closed"`
+	Position int
+}
+
+type Transition struct {
+	From TaskStatus `validate:"required"`
+	To   TaskStatus `validate:"required"`
+}
+
+type Workflow struct {
+	Statuses    []WorkflowStatus
+	Transitions []Transition
+}
+
+// Status returns the registered status with the given name.
+func (w Workflow) Status(name TaskStatus) (WorkflowStatus, bool) {
+	for _, status := range w.Statuses {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// Allowed reports whether a task may move from one status to another.
+// Keeping the current status is always allowed.
+func (w Workflow) Allowed(from, to TaskStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (c StatusCategory) String() string {
+	return string(c)
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// WorkLog is time spent by a user on a task. Duration is in seconds,
+// a running timer is a log without a duration yet.
+type WorkLog struct {
+	ID       string    `validate:"omitempty,uuid4"`
+	TaskID   string    `validate:"uuid4"`
+	UserID   string    `validate:"omitempty,uuid4"`
+	Started  time.Time `validate:"required"`
+	Duration int64     `validate:"min=0"`
+	Note     string    `validate:"max=1000"`
+	Running  bool
+}
+
+type WorkLogList struct {
+	WorkLogs []WorkLog
+	Total    int
+}
+
+// WorkLogFilter selects finished logs. OwnerID and ProjectID refer to the
+// logged tasks, From and To are inclusive dates.
+type WorkLogFilter struct {
+	TaskID    string    `form:"task_id" validate:"omitempty,uuid4"`
+	UserID    string    `form:"user_id" validate:"omitempty,uuid4"`
+	OwnerID   string    `form:"owner_id" validate:"omitempty,uuid4"`
+	ProjectID string    `form:"project_id" validate:"omitempty,uuid4"`
+	From      time.Time `form:"from" time_format:"2006-01-02"`
+	To        time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+type ReportGroup string
+
+const (
+	GroupByDay     ReportGroup = "day"
+	GroupByProject ReportGroup = "project"
+)
+
+type WorkReportFilter struct {
+	WorkLogFilter
+	GroupBy []ReportGroup `form:"group_by" validate:"required,min=1,dive,oneof=day project"`
+}
+
+// WorkReportRow is the time spent within a group, Day and ProjectID are set
+// only when the report is grouped by them.
+type WorkReportRow struct {
+	Day       time.Time
+	ProjectID string
+	Duration  int64
+}
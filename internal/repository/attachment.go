@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Attachment struct {
+	bun.BaseModel `bun:"table:attachments"`
+
+	ID          string    `bun:"id,pk,type:uuid"`
+	TaskID      string    `bun:"task_id,notnull,type:uuid"`
+	Filename    string    `bun:"filename,notnull"`
+	ContentType string    `bun:"content_type,notnull"`
+	Size        int64     `bun:"size,notnull"`
+	Checksum    string    `bun:"checksum,notnull"`
+	StorageKey  string    `bun:"storage_key,notnull"`
+	UploaderID  string    `bun:"uploader_id,nullzero,type:uuid"`
+	CreatedAt   time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+type AttachmentRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewAttachmentRepository(conn *bun.DB, logger *zerolog.Logger) *AttachmentRepository {
+	return &AttachmentRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsAttachment(attachment Attachment) models.Attachment {
+	return models.Attachment{
+		ID:          attachment.ID,
+		TaskID:      attachment.TaskID,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Checksum:    attachment.Checksum,
+		StorageKey:  attachment.StorageKey,
+		UploaderID:  attachment.UploaderID,
+		Created:     attachment.CreatedAt,
+	}
+}
+
+func repoAttachment(attachment models.Attachment) Attachment {
+	return Attachment{
+		ID:          attachment.ID,
+		TaskID:      attachment.TaskID,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Checksum:    attachment.Checksum,
+		StorageKey:  attachment.StorageKey,
+		UploaderID:  attachment.UploaderID,
+		CreatedAt:   attachment.Created,
+	}
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment models.Attachment) (models.Attachment, error) {
+	repoAttachment := repoAttachment(attachment)
+	_, err := r.conn.NewInsert().Model(&repoAttachment).Returning("*").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", attachment)
+		return models.Attachment{}, err
+	}
+
+	return modelsAttachment(repoAttachment), nil
+}
+
+func (r *AttachmentRepository) Get(ctx context.Context, id string) (models.Attachment, error) {
+	var repoAttachment Attachment
+	err := r.conn.NewSelect().Model(&repoAttachment).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't receiving attachment: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Attachment{}, models.ErrAttachmentNotFound
+		}
+		return models.Attachment{}, err
+	}
+
+	return modelsAttachment(repoAttachment), nil
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.conn.NewDelete().Model((*Attachment)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete attachment: %s", id)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete attachment: %s", id)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+func (r *AttachmentRepository) List(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	var attachments []Attachment
+	err := r.conn.NewSelect().
+		Model(&attachments).
+		Where("task_id = ?", taskID).
+		Order("created_at", "id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list attachments of task: %s", taskID)
+		return nil, err
+	}
+
+	res := make([]models.Attachment, 0, len(attachments))
+	for _, val := range attachments {
+		res = append(res, modelsAttachment(val))
+	}
+	return res, nil
+}
@@ -0,0 +1,134 @@
+// Package cache keeps recently read tasks in front of a task repository.
+// The values are kept in a Backend, an in-process LRU by default; anything
+// storing bytes by key with a TTL, such as Redis, can take its place.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Backend stores the encoded values of the cache. A missing or expired key
+// isn't an error, Get reports it with false.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// LRU is a Backend in memory holding up to maxEntries values and maxBytes
+// of keys and values, the least recently used are evicted first. It is
+// safe for concurrent use.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	items      map[string]*list.Element
+	// order has the most recently used entry in front
+	order     *list.List
+	evictions int64
+	expired   int64
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// LRUMetrics are the counters of an LRU.
+type LRUMetrics struct {
+	Entries   int
+	Bytes     int64
+	Evictions int64
+	Expired   int64
+}
+
+func NewLRU(maxEntries int, maxBytes int64) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		items:      map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if !time.Now().Before(entry.expires) {
+		c.remove(el)
+		c.expired++
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+// Set stores the value for ttl. A value larger than maxBytes isn't stored.
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+
+	entry := &lruEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if entry.size() > c.maxBytes {
+		return nil
+	}
+	c.items[key] = c.order.PushFront(entry)
+	c.bytes += entry.size()
+
+	for len(c.items) > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+	return nil
+}
+
+func (c *LRU) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+func (c *LRU) Metrics() LRUMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return LRUMetrics{
+		Entries:   len(c.items),
+		Bytes:     c.bytes,
+		Evictions: c.evictions,
+		Expired:   c.expired,
+	}
+}
+
+// remove drops the entry, the caller holds the lock.
+func (c *LRU) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*lruEntry)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size()
+}
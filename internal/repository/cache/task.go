@@ -0,0 +1,324 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// TaskRepository reads tasks through the cache and passes everything else to
+// the wrapped repository. Get and List are cached, the changes made through
+// it invalidate the cached tasks and lists; the tasks written by other
+// repositories are invalidated with InvalidateTasks and changes of other
+// instances when their events are passed to Invalidate. Anything else is
+// seen once the values expire.
+type TaskRepository struct {
+	repo    service.Repo
+	backend Backend
+	ttl     time.Duration
+	log     *zerolog.Logger
+
+	// generation is advanced on every invalidation. Lists are cached under
+	// it and values read before it changed aren't kept.
+	generation atomic.Uint64
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+}
+
+// Metrics are the counters of a TaskRepository.
+type Metrics struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64
+}
+
+func NewTaskRepository(repo service.Repo, backend Backend, ttl time.Duration, logger *zerolog.Logger) *TaskRepository {
+	return &TaskRepository{
+		repo:    repo,
+		backend: backend,
+		ttl:     ttl,
+		log:     logger,
+	}
+}
+
+func (r *TaskRepository) Metrics() Metrics {
+	return Metrics{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+}
+
+func taskKey(id string) string {
+	return "task:" + id
+}
+
+func listKey(generation uint64, filter models.TaskFilter) (string, error) {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return "", err
+	}
+	return "tasks:" + strconv.FormatUint(generation, 10) + ":" + string(data), nil
+}
+
+// load decodes the cached value of the key into v and reports if it was found.
+// Backend errors are logged and taken as misses.
+func (r *TaskRepository) load(ctx context.Context, key string, v any) bool {
+	data, ok, err := r.backend.Get(ctx, key)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't read cached: %s", key)
+		r.misses.Add(1)
+		return false
+	}
+	if !ok {
+		r.misses.Add(1)
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		r.log.Error().Err(err).Msgf("can't decode cached: %s", key)
+		r.misses.Add(1)
+		return false
+	}
+	r.hits.Add(1)
+	return true
+}
+
+// store caches v read at the generation. It is dropped if the cache was
+// invalidated meanwhile, as v may be older than the invalidating change.
+func (r *TaskRepository) store(ctx context.Context, key string, generation uint64, v any) {
+	if r.generation.Load() != generation {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't encode cached: %s", key)
+		return
+	}
+	if err := r.backend.Set(ctx, key, data, r.ttl); err != nil {
+		r.log.Error().Err(err).Msgf("can't cache: %s", key)
+		return
+	}
+
+	// an invalidation between the check and Set may have missed the key
+	if r.generation.Load() != generation {
+		r.delete(ctx, key)
+	}
+}
+
+func (r *TaskRepository) delete(ctx context.Context, keys ...string) {
+	if err := r.backend.Delete(ctx, keys...); err != nil {
+		r.log.Error().Err(err).Msgf("can't delete cached: %v", keys)
+	}
+}
+
+// invalidate drops the cached lists and the tasks with the IDs.
+func (r *TaskRepository) invalidate(ctx context.Context, ids ...string) {
+	r.generation.Add(1)
+	r.invalidations.Add(1)
+
+	if len(ids) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, taskKey(id))
+	}
+	r.delete(ctx, keys...)
+}
+
+// Invalidate drops the cached values the task event makes stale, it is
+// called with the events of other instances.
+func (r *TaskRepository) Invalidate(ctx context.Context, event models.TaskEvent) {
+	r.invalidate(ctx, event.Task.ID)
+}
+
+// InvalidateTasks drops the cached lists and the tasks with the IDs, it is
+// called after the tasks were changed without the cache.
+func (r *TaskRepository) InvalidateTasks(ctx context.Context, ids ...string) {
+	r.invalidate(ctx, ids...)
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	key := taskKey(id.String())
+
+	var task models.Task
+	if r.load(ctx, key, &task) {
+		return task, nil
+	}
+
+	generation := r.generation.Load()
+	task, err := r.repo.Get(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.store(ctx, key, generation, task)
+	return task, nil
+}
+
+func (r *TaskRepository) List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	generation := r.generation.Load()
+	key, err := listKey(generation, filter)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't make cache key of: %+v", filter)
+		return r.repo.List(ctx, filter)
+	}
+
+	var tasks []models.Task
+	if r.load(ctx, key, &tasks) {
+		return tasks, nil
+	}
+
+	tasks, err = r.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, key, generation, tasks)
+	return tasks, nil
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
+	res, err := r.repo.Create(ctx, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx)
+	return res, nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, req models.Task) (models.Task, error) {
+	res, err := r.repo.Update(ctx, req)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx, req.ID)
+	return res, nil
+}
+
+func (r *TaskRepository) SetAssignee(ctx context.Context, id string, assigneeID string, updated time.Time) (models.Task, error) {
+	res, err := r.repo.SetAssignee(ctx, id, assigneeID, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx, id)
+	return res, nil
+}
+
+func (r *TaskRepository) SetProject(ctx context.Context, id string, projectID string, updated time.Time) (models.Task, error) {
+	res, err := r.repo.SetProject(ctx, id, projectID, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx, id)
+	return res, nil
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	if err := r.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// Trash isn't cached, trashed tasks are rarely read.
+func (r *TaskRepository) Trash(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	return r.repo.Trash(ctx, filter)
+}
+
+func (r *TaskRepository) Restore(ctx context.Context, id string, updated time.Time) (models.Task, error) {
+	res, err := r.repo.Restore(ctx, id, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx, id)
+	return res, nil
+}
+
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	if err := r.repo.Purge(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *TaskRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	ids, err := r.repo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		r.invalidate(ctx, ids...)
+	}
+	return ids, nil
+}
+
+func (r *TaskRepository) SetArchived(ctx context.Context, id string, archived time.Time, updated time.Time) (models.Task, error) {
+	res, err := r.repo.SetArchived(ctx, id, archived, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx, id)
+	return res, nil
+}
+
+func (r *TaskRepository) ArchiveStale(ctx context.Context, status models.TaskStatus, before time.Time, archived time.Time) ([]models.Task, error) {
+	tasks, err := r.repo.ArchiveStale(ctx, status, before, archived)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) > 0 {
+		ids := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		r.invalidate(ctx, ids...)
+	}
+	return tasks, nil
+}
+
+func (r *TaskRepository) Patch(ctx context.Context, task models.Task, columns []string, action models.HistoryAction) (models.Task, error) {
+	res, err := r.repo.Patch(ctx, task, columns, action)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.invalidate(ctx, task.ID)
+	return res, nil
+}
+
+func (r *TaskRepository) CompleteRecurring(ctx context.Context, id string, occurrence models.Task, updated time.Time) (models.Task, models.Task, error) {
+	done, next, err := r.repo.CompleteRecurring(ctx, id, occurrence, updated)
+	if err != nil {
+		return models.Task{}, models.Task{}, err
+	}
+	r.invalidate(ctx, id)
+	return done, next, nil
+}
+
+func (r *TaskRepository) CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error) {
+	res, err := r.repo.CreateTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	r.invalidate(ctx)
+	return res, nil
+}
+
+// Stats isn't cached, the rollups change with every task.
+func (r *TaskRepository) Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error) {
+	return r.repo.Stats(ctx, filter)
+}
+
+func (r *TaskRepository) Changes(ctx context.Context, since uint64, limit int) (models.TaskChanges, error) {
+	return r.repo.Changes(ctx, since, limit)
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/repository/memory"
+	"github.com/VikaPaz/task_tracker/internal/repository/repotest"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func newCached(t *testing.T) (*TaskRepository, *memory.TaskRepository) {
+	logger := zerolog.Nop()
+	repo := memory.NewTaskRepository(memory.NewDB(), &logger)
+	return NewTaskRepository(repo, NewLRU(100, 1<<20), time.Minute, &logger), repo
+}
+
+func TestTaskRepository(t *testing.T) {
+	repotest.Run(t, repotest.Backend{
+		NewRepo: func(t *testing.T) service.Repo {
+			cached, _ := newCached(t)
+			return cached
+		},
+	})
+}
+
+func TestInvalidation(t *testing.T) {
+	ctx := context.Background()
+	cached, repo := newCached(t)
+
+	task, err := cached.Create(ctx, models.Task{Title: "Cached", Status: "todo", OwnerID: uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := uuid.MustParse(task.ID)
+
+	title := func() string {
+		t.Helper()
+		got, err := cached.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got.Title
+	}
+	listed := func() int {
+		t.Helper()
+		tasks, err := cached.List(ctx, models.TaskFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(tasks)
+	}
+
+	title()
+	if got := listed(); got != 1 {
+		t.Fatalf("List = %d tasks, want 1", got)
+	}
+
+	// changed behind the cache, the cached values are served
+	if _, err := repo.Update(ctx, models.Task{ID: task.ID, Title: "Behind", Updated: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(ctx, models.Task{Title: "Behind", Status: "todo", OwnerID: uuid.NewString()}); err != nil {
+		t.Fatal(err)
+	}
+	if got := title(); got != "Cached" {
+		t.Errorf("Get = %q, want the cached title", got)
+	}
+	if got := listed(); got != 1 {
+		t.Errorf("List = %d tasks, want the cached 1", got)
+	}
+	if got := cached.Metrics(); got.Hits != 2 || got.Misses != 2 {
+		t.Errorf("Metrics = %+v, want 2 hits and 2 misses", got)
+	}
+
+	// an event of another instance invalidates them
+	cached.Invalidate(ctx, models.TaskEvent{Type: models.EventUpdated, Task: models.Task{ID: task.ID}})
+	if got := title(); got != "Behind" {
+		t.Errorf("Get = %q after Invalidate, want %q", got, "Behind")
+	}
+	if got := listed(); got != 2 {
+		t.Errorf("List = %d tasks after Invalidate, want 2", got)
+	}
+
+	// and so do the tasks written without the cache
+	if _, err := repo.Update(ctx, models.Task{ID: task.ID, Title: "Refreshed", Updated: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	cached.InvalidateTasks(ctx, task.ID)
+	if got := title(); got != "Refreshed" {
+		t.Errorf("Get = %q after InvalidateTasks, want %q", got, "Refreshed")
+	}
+
+	// and the changes made through the cache
+	if _, err := cached.Update(ctx, models.Task{ID: task.ID, Title: "Updated", Updated: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if got := title(); got != "Updated" {
+		t.Errorf("Get = %q after Update, want %q", got, "Updated")
+	}
+	if err := cached.Delete(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cached.Get(ctx, id); err != models.ErrTaskNotFound {
+		t.Errorf("Get after Delete: got error %v, want %v", err, models.ErrTaskNotFound)
+	}
+	if got := listed(); got != 1 {
+		t.Errorf("List = %d tasks after Delete, want 1", got)
+	}
+}
+
+func TestStoreSkipsInvalidatedReads(t *testing.T) {
+	ctx := context.Background()
+	cached, _ := newCached(t)
+
+	generation := cached.generation.Load()
+	cached.invalidate(ctx, "id")
+	cached.store(ctx, taskKey("id"), generation, models.Task{ID: "id"})
+
+	if _, ok, _ := cached.backend.Get(ctx, taskKey("id")); ok {
+		t.Error("a task read before the invalidation was cached")
+	}
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2, 20)
+
+	lru.Set(ctx, "a", []byte("1"), time.Minute)
+	lru.Set(ctx, "b", []byte("2"), time.Minute)
+	lru.Get(ctx, "a")
+	lru.Set(ctx, "c", []byte("3"), time.Minute)
+	if _, ok, _ := lru.Get(ctx, "b"); ok {
+		t.Error("the least recently used entry wasn't evicted")
+	}
+	if _, ok, _ := lru.Get(ctx, "a"); !ok {
+		t.Error("a recently used entry was evicted")
+	}
+
+	lru.Set(ctx, "d", []byte("0123456789abcdef"), time.Minute)
+	if got := lru.Metrics(); got.Entries != 2 || got.Bytes != 19 || got.Evictions != 2 {
+		t.Errorf("Metrics = %+v, want 2 entries of 19 bytes after 2 evictions", got)
+	}
+	lru.Set(ctx, "e", make([]byte, 20), time.Minute)
+	if _, ok, _ := lru.Get(ctx, "e"); ok {
+		t.Error("an entry larger than the limit was kept")
+	}
+
+	lru.Set(ctx, "f", []byte("1"), -time.Second)
+	if _, ok, _ := lru.Get(ctx, "f"); ok {
+		t.Error("an expired entry was returned")
+	}
+	if got := lru.Metrics(); got.Expired != 1 {
+		t.Errorf("Metrics = %+v, want 1 expired", got)
+	}
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type ChecklistItem struct {
+	bun.BaseModel `bun:"table:checklist_items"`
+
+	ID       string `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	TaskID   string `bun:"task_id,notnull,type:uuid"`
+	Text     string `bun:"text,notnull"`
+	Checked  bool   `bun:"checked,notnull"`
+	Position int    `bun:"position,notnull"`
+}
+
+type ChecklistRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewChecklistRepository(conn *bun.DB, logger *zerolog.Logger) *ChecklistRepository {
+	return &ChecklistRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsChecklistItem(item ChecklistItem) models.ChecklistItem {
+	return models.ChecklistItem{
+		ID:       item.ID,
+		TaskID:   item.TaskID,
+		Text:     item.Text,
+		Checked:  item.Checked,
+		Position: item.Position,
+	}
+}
+
+func (r *ChecklistRepository) List(ctx context.Context, taskID string) ([]models.ChecklistItem, error) {
+	var items []ChecklistItem
+	err := r.conn.NewSelect().
+		Model(&items).
+		Where("task_id = ?", taskID).
+		Order("position", "id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list checklist of task: %s", taskID)
+		return nil, err
+	}
+
+	res := make([]models.ChecklistItem, 0, len(items))
+	for _, val := range items {
+		res = append(res, modelsChecklistItem(val))
+	}
+	return res, nil
+}
+
+// Add appends the item to the end of the task checklist.
+func (r *ChecklistRepository) Add(ctx context.Context, item models.ChecklistItem) (models.ChecklistItem, error) {
+	repoItem := ChecklistItem{
+		TaskID:  item.TaskID,
+		Text:    item.Text,
+		Checked: item.Checked,
+	}
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// locking the task serializes positions of concurrently added items
+		err := tx.NewSelect().Model((*Task)(nil)).Column("id").Where("id = ?", item.TaskID).For("UPDATE").Scan(ctx, new(string))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrTaskNotFound
+			}
+			return err
+		}
+
+		err = tx.NewSelect().
+			Model((*ChecklistItem)(nil)).
+			ColumnExpr("coalesce(max(position) + 1, 0)").
+			Where("task_id = ?", item.TaskID).
+			Scan(ctx, &repoItem.Position)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewInsert().Model(&repoItem).ExcludeColumn("id").Returning("*").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		return refreshProgress(ctx, tx, item.TaskID)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't add checklist item: %v", item)
+		return models.ChecklistItem{}, err
+	}
+
+	return modelsChecklistItem(repoItem), nil
+}
+
+func (r *ChecklistRepository) SetText(ctx context.Context, id string, text string) (models.ChecklistItem, error) {
+	return r.update(ctx, ChecklistItem{ID: id, Text: text}, "text")
+}
+
+func (r *ChecklistRepository) SetChecked(ctx context.Context, id string, checked bool) (models.ChecklistItem, error) {
+	return r.update(ctx, ChecklistItem{ID: id, Checked: checked}, "checked")
+}
+
+// update stores a single column of the item, so concurrent edits of the
+// text and the checked flag don't overwrite each other.
+func (r *ChecklistRepository) update(ctx context.Context, item ChecklistItem, column string) (models.ChecklistItem, error) {
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model(&item).
+			Column(column).
+			Where("id = ?", item.ID).
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return models.ErrChecklistItemNotFound
+		}
+
+		return refreshProgress(ctx, tx, item.TaskID)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't update checklist item: %v", item)
+		return models.ChecklistItem{}, err
+	}
+
+	return modelsChecklistItem(item), nil
+}
+
+// Reorder sets the positions of the task checklist to the order of ids,
+// which must contain every item of the checklist once.
+func (r *ChecklistRepository) Reorder(ctx context.Context, taskID string, ids []string) ([]models.ChecklistItem, error) {
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var current []string
+		err := tx.NewSelect().
+			Model((*ChecklistItem)(nil)).
+			Column("id").
+			Where("task_id = ?", taskID).
+			For("UPDATE").
+			Scan(ctx, &current)
+		if err != nil {
+			return err
+		}
+
+		if !sameItems(current, ids) {
+			return models.ErrInvalidOrder
+		}
+
+		for position, id := range ids {
+			_, err := tx.NewUpdate().
+				Model((*ChecklistItem)(nil)).
+				Set("position = ?", position).
+				Where("id = ?", id).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't reorder checklist of task: %s", taskID)
+		return nil, err
+	}
+
+	return r.List(ctx, taskID)
+}
+
+// Delete removes the item and returns it.
+func (r *ChecklistRepository) Delete(ctx context.Context, id string) (models.ChecklistItem, error) {
+	var item ChecklistItem
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().Model(&item).Where("id = ?", id).Returning("*").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return models.ErrChecklistItemNotFound
+		}
+
+		return refreshProgress(ctx, tx, item.TaskID)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete checklist item: %s", id)
+		return models.ChecklistItem{}, err
+	}
+
+	return modelsChecklistItem(item), nil
+}
+
+// refreshProgress recounts the checklist progress stored in the task and
+// writes the event of the changed task.
+func refreshProgress(ctx context.Context, tx bun.Tx, taskID string) error {
+	var task Task
+	res, err := tx.NewUpdate().
+		Model(&task).
+		Set("checklist_total = (SELECT count(*) FROM checklist_items WHERE task_id = ?)", taskID).
+		Set("checklist_checked = (SELECT count(*) FROM checklist_items WHERE task_id = ? AND checked)", taskID).
+		Where("id = ?", taskID).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		// the task is in the trash, it isn't updated
+		return nil
+	}
+	return addEvents(ctx, tx, models.EventUpdated, task)
+}
+
+func sameItems(current, ids []string) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, id := range current {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
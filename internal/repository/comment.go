@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Comment struct {
+	bun.BaseModel `bun:"table:comments"`
+
+	ID        string    `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	TaskID    string    `bun:"task_id,notnull,type:uuid"`
+	AuthorID  string    `bun:"author_id,notnull,type:uuid"`
+	Body      string    `bun:"body,notnull"`
+	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp"`
+	UpdatedAt time.Time `bun:"updated_at,nullzero,default:current_timestamp"`
+	Edited    bool      `bun:"edited,notnull"`
+}
+
+type CommentEdit struct {
+	bun.BaseModel `bun:"table:comment_edits"`
+
+	ID        string    `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	CommentID string    `bun:"comment_id,notnull,type:uuid"`
+	EditorID  string    `bun:"editor_id,notnull,type:uuid"`
+	Body      string    `bun:"body,notnull"`
+	EditedAt  time.Time `bun:"edited_at,notnull,default:current_timestamp"`
+}
+
+type CommentRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewCommentRepository(conn *bun.DB, logger *zerolog.Logger) *CommentRepository {
+	return &CommentRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsComment(comment Comment) models.Comment {
+	return models.Comment{
+		ID:       comment.ID,
+		TaskID:   comment.TaskID,
+		AuthorID: comment.AuthorID,
+		Body:     comment.Body,
+		Created:  comment.CreatedAt,
+		Updated:  comment.UpdatedAt,
+		Edited:   comment.Edited,
+	}
+}
+
+func repoComment(comment models.Comment) Comment {
+	return Comment{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		AuthorID:  comment.AuthorID,
+		Body:      comment.Body,
+		CreatedAt: comment.Created,
+		UpdatedAt: comment.Updated,
+		Edited:    comment.Edited,
+	}
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	repoComment := repoComment(comment)
+	_, err := r.conn.NewInsert().Model(&repoComment).ExcludeColumn("id").Returning("*").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", comment)
+		return models.Comment{}, err
+	}
+
+	return modelsComment(repoComment), nil
+}
+
+func (r *CommentRepository) Get(ctx context.Context, id string) (models.Comment, error) {
+	var repoComment Comment
+	err := r.conn.NewSelect().Model(&repoComment).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't receiving comment: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Comment{}, models.ErrCommentNotFound
+		}
+		return models.Comment{}, err
+	}
+
+	return modelsComment(repoComment), nil
+}
+
+// Update replaces the comment body and keeps the previous one in comment_edits.
+func (r *CommentRepository) Update(ctx context.Context, comment models.Comment, editorID string) (models.Comment, error) {
+	var updated Comment
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var current Comment
+		err := tx.NewSelect().Model(&current).Where("id = ?", comment.ID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrCommentNotFound
+			}
+			return err
+		}
+
+		edit := CommentEdit{
+			CommentID: current.ID,
+			EditorID:  editorID,
+			Body:      current.Body,
+			EditedAt:  comment.Updated,
+		}
+		_, err = tx.NewInsert().Model(&edit).ExcludeColumn("id").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		updated = current
+		updated.Body = comment.Body
+		updated.UpdatedAt = comment.Updated
+		updated.Edited = true
+		_, err = tx.NewUpdate().
+			Model(&updated).
+			Column("body", "updated_at", "edited").
+			Where("id = ?", updated.ID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't updating: %v", comment)
+		return models.Comment{}, err
+	}
+
+	return modelsComment(updated), nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.conn.NewDelete().Model((*Comment)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete comment: %s", id)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete comment: %s", id)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func (r *CommentRepository) List(ctx context.Context, taskID string, page models.Page) (models.CommentList, error) {
+	var comments []Comment
+	total, err := r.conn.NewSelect().
+		Model(&comments).
+		Where("task_id = ?", taskID).
+		Order("created_at", "id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		ScanAndCount(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list comments of task: %s", taskID)
+		return models.CommentList{}, err
+	}
+
+	res := models.CommentList{
+		Comments: make([]models.Comment, 0, len(comments)),
+		Total:    total,
+	}
+	for _, val := range comments {
+		res.Comments = append(res.Comments, modelsComment(val))
+	}
+	return res, nil
+}
+
+func (r *CommentRepository) History(ctx context.Context, commentID string) ([]models.CommentEdit, error) {
+	var edits []CommentEdit
+	err := r.conn.NewSelect().
+		Model(&edits).
+		Where("comment_id = ?", commentID).
+		Order("edited_at", "id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list edits of comment: %s", commentID)
+		return nil, err
+	}
+
+	res := make([]models.CommentEdit, 0, len(edits))
+	for _, val := range edits {
+		res = append(res, models.CommentEdit{
+			ID:        val.ID,
+			CommentID: val.CommentID,
+			EditorID:  val.EditorID,
+			Body:      val.Body,
+			Edited:    val.EditedAt,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type FieldDefinition struct {
+	bun.BaseModel `bun:"table:custom_field_definitions,alias:fd"`
+
+	ID        string    `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	ProjectID string    `bun:"project_id,notnull,type:uuid"`
+	Name      string    `bun:"name,notnull"`
+	Type      string    `bun:"type,notnull"`
+	Options   []string  `bun:"options,type:jsonb"`
+	Required  bool      `bun:"required,notnull"`
+	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+type CustomFieldRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewCustomFieldRepository(conn *bun.DB, logger *zerolog.Logger) *CustomFieldRepository {
+	return &CustomFieldRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsFieldDefinition(field FieldDefinition) models.FieldDefinition {
+	return models.FieldDefinition{
+		ID:        field.ID,
+		ProjectID: field.ProjectID,
+		Name:      field.Name,
+		Type:      models.FieldType(field.Type),
+		Options:   field.Options,
+		Required:  field.Required,
+		Created:   field.CreatedAt,
+	}
+}
+
+func (r *CustomFieldRepository) List(ctx context.Context, projectID string) ([]models.FieldDefinition, error) {
+	var fields []FieldDefinition
+	err := r.conn.NewSelect().
+		Model(&fields).
+		Where("project_id = ?", projectID).
+		Order("name").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list custom fields of project: %s", projectID)
+		return nil, err
+	}
+
+	res := make([]models.FieldDefinition, 0, len(fields))
+	for _, val := range fields {
+		res = append(res, modelsFieldDefinition(val))
+	}
+	return res, nil
+}
+
+func (r *CustomFieldRepository) Create(ctx context.Context, field models.FieldDefinition) (models.FieldDefinition, error) {
+	repoField := FieldDefinition{
+		ProjectID: field.ProjectID,
+		Name:      field.Name,
+		Type:      string(field.Type),
+		Options:   field.Options,
+		Required:  field.Required,
+		CreatedAt: field.Created,
+	}
+	res, err := r.conn.NewInsert().
+		Model(&repoField).
+		ExcludeColumn("id").
+		On("CONFLICT (project_id, name) DO NOTHING").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't create custom field: %v", field)
+		return models.FieldDefinition{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't create custom field: %v", field)
+		return models.FieldDefinition{}, err
+	}
+	if affected != 1 {
+		return models.FieldDefinition{}, models.ErrFieldExists
+	}
+
+	return modelsFieldDefinition(repoField), nil
+}
+
+// Delete removes the field definition together with its values on the project
+// tasks and returns the IDs of the tasks that had a value.
+func (r *CustomFieldRepository) Delete(ctx context.Context, projectID string, name string) ([]string, error) {
+	var tasks []Task
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().
+			Model((*FieldDefinition)(nil)).
+			Where("project_id = ?", projectID).
+			Where("name = ?", name).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return models.ErrFieldNotFound
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*Task)(nil)).
+			WhereAllWithDeleted().
+			Set("custom_fields = custom_fields - ?", name).
+			Where("project_id = ?", projectID).
+			Where("custom_fields ->> ? IS NOT NULL", name).
+			Returning("*").
+			Exec(ctx, &tasks)
+		if err != nil {
+			return err
+		}
+
+		// the values of trashed tasks are dropped without an event, nobody sees them
+		var changed []Task
+		for _, task := range tasks {
+			if task.DeletedAt.IsZero() {
+				changed = append(changed, task)
+			}
+		}
+		return addEvents(ctx, tx, models.EventUpdated, changed...)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete custom field %s of project: %s", name, projectID)
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids, nil
+}
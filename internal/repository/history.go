@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Revision struct {
+	bun.BaseModel `bun:"table:task_revisions,alias:rev"`
+
+	ID        string        `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	TaskID    string        `bun:"task_id,notnull,type:uuid"`
+	ActorID   string        `bun:"actor_id,nullzero,type:uuid"`
+	Action    string        `bun:"action,notnull"`
+	Changes   []FieldChange `bun:"changes,type:jsonb,notnull"`
+	CreatedAt time.Time     `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type HistoryRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewHistoryRepository(conn *bun.DB, logger *zerolog.Logger) *HistoryRepository {
+	return &HistoryRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsRevision(rev Revision) models.Revision {
+	changes := make([]models.FieldChange, 0, len(rev.Changes))
+	for _, val := range rev.Changes {
+		changes = append(changes, models.FieldChange(val))
+	}
+	return models.Revision{
+		ID:      rev.ID,
+		TaskID:  rev.TaskID,
+		ActorID: rev.ActorID,
+		Action:  models.HistoryAction(rev.Action),
+		Changes: changes,
+		Created: rev.CreatedAt,
+	}
+}
+
+// addRevision writes the revision the action of the current user made to
+// the task in the transaction of the change.
+func addRevision(ctx context.Context, tx bun.Tx, action models.HistoryAction, old, new Task) error {
+	actorID, _ := auth.UserID(ctx)
+	rev, ok := models.NewRevision(actorID, action, modelsTask(old), modelsTask(new))
+	if !ok {
+		return nil
+	}
+
+	repoRev := Revision{
+		TaskID:    rev.TaskID,
+		ActorID:   rev.ActorID,
+		Action:    string(rev.Action),
+		Changes:   make([]FieldChange, 0, len(rev.Changes)),
+		CreatedAt: rev.Created,
+	}
+	for _, val := range rev.Changes {
+		repoRev.Changes = append(repoRev.Changes, FieldChange(val))
+	}
+
+	_, err := tx.NewInsert().Model(&repoRev).ExcludeColumn("id").Exec(ctx)
+	return err
+}
+
+func (r *HistoryRepository) Get(ctx context.Context, id string) (models.Revision, error) {
+	var rev Revision
+	err := r.conn.NewSelect().Model(&rev).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't receiving revision: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Revision{}, models.ErrRevisionNotFound
+		}
+		return models.Revision{}, err
+	}
+
+	return modelsRevision(rev), nil
+}
+
+func (r *HistoryRepository) List(ctx context.Context, taskID string) ([]models.Revision, error) {
+	var revs []Revision
+	err := r.conn.NewSelect().
+		Model(&revs).
+		Where("task_id = ?", taskID).
+		Order("created_at", "id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list history of task: %s", taskID)
+		return nil, err
+	}
+
+	res := make([]models.Revision, 0, len(revs))
+	for _, val := range revs {
+		res = append(res, modelsRevision(val))
+	}
+	return res, nil
+}
@@ -0,0 +1,92 @@
+// Package memory keeps the tasks, the workflow and the task history in
+// memory. It serves local runs and tests without a database, the data is
+// lost when the process exits.
+package memory
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+)
+
+// DB is the storage shared by the repositories of the package, like a
+// database connection. It is safe for concurrent use.
+type DB struct {
+	mu sync.RWMutex
+
+	tasks map[string]models.Task
+	// changes holds the position of every task in the change sequence
+	changes map[string]uint64
+	seq     uint64
+	// horizon is the highest change position of the purged tasks
+	horizon uint64
+	// statusChanged holds when every task got its status, like the
+	// status_changed_at column
+	statusChanged map[string]time.Time
+
+	statuses    []models.WorkflowStatus
+	transitions []models.Transition
+	revisions   []models.Revision
+}
+
+// NewDB returns an empty storage with the default workflow.
+func NewDB() *DB {
+	return &DB{
+		tasks:         map[string]models.Task{},
+		changes:       map[string]uint64{},
+		statusChanged: map[string]time.Time{},
+		statuses: []models.WorkflowStatus{
+			{Name: "todo", Title: "To do", Category: models.CategoryOpen, Position: 10},
+			{Name: "in_progress", Title: "In progress", Category: models.CategoryActive, Position: 20},
+			{Name: "in_review", Title: "In review", Category: models.CategoryActive, Position: 30},
+			{Name: "blocked", Title: "Blocked", Category: models.CategoryActive, Position: 40},
+			{Name: "done", Title: "Done", Category: models.CategoryClosed, Position: 50},
+			{Name: "cancelled", Title: "Cancelled", Category: models.CategoryClosed, Position: 60},
+		},
+		transitions: []models.Transition{
+			{From: "blocked", To: "cancelled"},
+			{From: "blocked", To: "in_progress"},
+			{From: "cancelled", To: "todo"},
+			{From: "done", To: "in_progress"},
+			{From: "in_progress", To: "blocked"},
+			{From: "in_progress", To: "cancelled"},
+			{From: "in_progress", To: "done"},
+			{From: "in_progress", To: "in_review"},
+			{From: "in_progress", To: "todo"},
+			{From: "in_review", To: "done"},
+			{From: "in_review", To: "in_progress"},
+			{From: "todo", To: "cancelled"},
+			{From: "todo", To: "in_progress"},
+		},
+	}
+}
+
+// put stores the task and moves it to the end of the change sequence.
+// The caller holds the write lock.
+func (db *DB) put(task models.Task) {
+	if old, ok := db.tasks[task.ID]; !ok || old.Status != task.Status {
+		db.statusChanged[task.ID] = task.Updated
+	}
+	db.seq++
+	db.tasks[task.ID] = cloneTask(task)
+	db.changes[task.ID] = db.seq
+}
+
+// remove deletes the task for good. The caller holds the write lock.
+func (db *DB) remove(id string) {
+	db.horizon = max(db.horizon, db.changes[id])
+	delete(db.tasks, id)
+	delete(db.changes, id)
+	delete(db.statusChanged, id)
+}
+
+// cloneTask copies the task so the stored one isn't shared with callers.
+func cloneTask(task models.Task) models.Task {
+	task.CustomFields = maps.Clone(task.CustomFields)
+	task.Labels = slices.Clone(task.Labels)
+	task.Watchers = slices.Clone(task.Watchers)
+	return task
+}
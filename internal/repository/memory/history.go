@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type HistoryRepository struct {
+	db  *DB
+	log *zerolog.Logger
+}
+
+func NewHistoryRepository(db *DB, logger *zerolog.Logger) *HistoryRepository {
+	return &HistoryRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *HistoryRepository) Get(ctx context.Context, id string) (models.Revision, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, rev := range r.db.revisions {
+		if rev.ID == id {
+			return rev, nil
+		}
+	}
+	return models.Revision{}, models.ErrRevisionNotFound
+}
+
+// List returns the history of a task, the oldest revision first.
+func (r *HistoryRepository) List(ctx context.Context, taskID string) ([]models.Revision, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	res := []models.Revision{}
+	for _, rev := range r.db.revisions {
+		if rev.TaskID == taskID {
+			res = append(res, rev)
+		}
+	}
+	return res, nil
+}
+
+// record keeps the revision the action of the current user made to the task.
+// The caller holds the write lock.
+func (db *DB) record(ctx context.Context, action models.HistoryAction, old, new models.Task) {
+	actorID, _ := auth.UserID(ctx)
+	rev, ok := models.NewRevision(actorID, action, old, new)
+	if !ok {
+		return
+	}
+	rev.ID = uuid.NewString()
+	db.revisions = append(db.revisions, rev)
+}
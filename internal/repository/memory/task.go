@@ -0,0 +1,482 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type TaskRepository struct {
+	db  *DB
+	log *zerolog.Logger
+}
+
+func NewTaskRepository(db *DB, logger *zerolog.Logger) *TaskRepository {
+	return &TaskRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	task, err := r.insert(task)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", task)
+		return models.Task{}, err
+	}
+	r.db.record(ctx, models.ActionCreated, models.Task{}, task)
+	return task, nil
+}
+
+// insert fills in the defaults the database would and stores a new task.
+// The caller holds the write lock.
+func (r *TaskRepository) insert(task models.Task) (models.Task, error) {
+	if task.ID == "" {
+		task.ID = uuid.NewString()
+	}
+	if _, ok := r.db.tasks[task.ID]; ok {
+		return models.Task{}, fmt.Errorf("task %s already exists", task.ID)
+	}
+
+	now := time.Now().UTC()
+	if task.Created.IsZero() {
+		task.Created = now
+	}
+	if task.Updated.IsZero() {
+		task.Updated = now
+	}
+	task.Watchers = nil
+
+	r.db.put(task)
+	return cloneTask(task), nil
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	task, ok := r.db.tasks[id.String()]
+	if !ok || !task.Deleted.IsZero() {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+	return cloneTask(task), nil
+}
+
+// Update changes the fields of the task which are set in req, like the
+// Postgres repository it leaves the empty ones as they are.
+func (r *TaskRepository) Update(ctx context.Context, req models.Task) (models.Task, error) {
+	return r.change(ctx, req.ID, models.ActionUpdated, func(task *models.Task) error {
+		if req.Title != "" {
+			task.Title = req.Title
+		}
+		if req.Description != "" {
+			task.Description = req.Description
+		}
+		if req.Status != "" {
+			task.Status = req.Status
+		}
+		if req.OwnerID != "" {
+			task.OwnerID = req.OwnerID
+		}
+		if !req.Due.IsZero() {
+			task.Due = req.Due
+		}
+		if req.RRule != "" {
+			task.RRule = req.RRule
+		}
+		if req.EstimateMinutes != 0 {
+			task.EstimateMinutes = req.EstimateMinutes
+		}
+		if req.StoryPoints != 0 {
+			task.StoryPoints = req.StoryPoints
+		}
+		if req.CustomFields != nil {
+			task.CustomFields = req.CustomFields
+		}
+		if req.Labels != nil {
+			task.Labels = req.Labels
+		}
+		task.Updated = req.Updated
+		return nil
+	})
+}
+
+func (r *TaskRepository) SetAssignee(ctx context.Context, id string, assigneeID string, updated time.Time) (models.Task, error) {
+	action := models.ActionAssigned
+	if assigneeID == "" {
+		action = models.ActionUnassigned
+	}
+	return r.change(ctx, id, action, func(task *models.Task) error {
+		task.AssigneeID = assigneeID
+		task.Updated = updated
+		return nil
+	})
+}
+
+// SetProject moves the task to the project, its custom fields belong to the
+// old project and are cleared.
+func (r *TaskRepository) SetProject(ctx context.Context, id string, projectID string, updated time.Time) (models.Task, error) {
+	return r.change(ctx, id, models.ActionMoved, func(task *models.Task) error {
+		task.ProjectID = projectID
+		task.CustomFields = nil
+		task.Updated = updated
+		return nil
+	})
+}
+
+// Delete moves a task to the trash, it stays there until it is purged.
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.change(ctx, id, models.ActionDeleted, func(task *models.Task) error {
+		task.Deleted = time.Now().UTC()
+		return nil
+	})
+	return err
+}
+
+// Trash lists the trashed tasks matching the filter, the most recently deleted first.
+// Archived tasks are listed as well.
+func (r *TaskRepository) Trash(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	filter.IncludeArchived = true
+	tasks := r.find(func(task models.Task) bool {
+		return !task.Deleted.IsZero() && filter.Match(task)
+	})
+	slices.SortStableFunc(tasks, func(a, b models.Task) int {
+		return b.Deleted.Compare(a.Deleted)
+	})
+	return tasks, nil
+}
+
+func (r *TaskRepository) Restore(ctx context.Context, id string, updated time.Time) (models.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	old, ok := r.db.tasks[id]
+	if !ok || old.Deleted.IsZero() {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+	task := old
+	task.Deleted = time.Time{}
+	task.Updated = updated
+
+	r.db.put(task)
+	r.db.record(ctx, models.ActionRestored, old, task)
+	return cloneTask(task), nil
+}
+
+// Purge permanently deletes a trashed task.
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	task, ok := r.db.tasks[id]
+	if !ok || task.Deleted.IsZero() {
+		return models.ErrTaskNotFound
+	}
+
+	r.db.remove(id)
+	return nil
+}
+
+// PurgeDeleted permanently deletes the tasks trashed before the given time and returns their IDs.
+func (r *TaskRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var ids []string
+	for id, task := range r.db.tasks {
+		if !task.Deleted.IsZero() && task.Deleted.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		r.db.remove(id)
+	}
+	return ids, nil
+}
+
+func (r *TaskRepository) SetArchived(ctx context.Context, id string, archived time.Time, updated time.Time) (models.Task, error) {
+	action := models.ActionArchived
+	if archived.IsZero() {
+		action = models.ActionUnarchived
+	}
+	return r.change(ctx, id, action, func(task *models.Task) error {
+		task.Archived = archived
+		task.Updated = updated
+		return nil
+	})
+}
+
+// ArchiveStale archives the tasks which have been in the status since before.
+func (r *TaskRepository) ArchiveStale(ctx context.Context, status models.TaskStatus, before time.Time, archived time.Time) ([]models.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	res := []models.Task{}
+	for _, old := range r.db.tasks {
+		if !old.Deleted.IsZero() || !old.Archived.IsZero() || old.Status != status || !r.db.statusChanged[old.ID].Before(before) {
+			continue
+		}
+		task := old
+		task.Archived = archived
+		r.db.put(task)
+		r.db.record(ctx, models.ActionArchived, old, task)
+		res = append(res, cloneTask(task))
+	}
+	return res, nil
+}
+
+// patchColumns set a task field named after its column from another version of the task.
+var patchColumns = map[string]func(task *models.Task, from models.Task){
+	"title":            func(t *models.Task, from models.Task) { t.Title = from.Title },
+	"description":      func(t *models.Task, from models.Task) { t.Description = from.Description },
+	"status":           func(t *models.Task, from models.Task) { t.Status = from.Status },
+	"owner_id":         func(t *models.Task, from models.Task) { t.OwnerID = from.OwnerID },
+	"assignee_id":      func(t *models.Task, from models.Task) { t.AssigneeID = from.AssigneeID },
+	"due_at":           func(t *models.Task, from models.Task) { t.Due = from.Due },
+	"rrule":            func(t *models.Task, from models.Task) { t.RRule = from.RRule },
+	"project_id":       func(t *models.Task, from models.Task) { t.ProjectID = from.ProjectID },
+	"estimate_minutes": func(t *models.Task, from models.Task) { t.EstimateMinutes = from.EstimateMinutes },
+	"story_points":     func(t *models.Task, from models.Task) { t.StoryPoints = from.StoryPoints },
+	"custom_fields":    func(t *models.Task, from models.Task) { t.CustomFields = from.CustomFields },
+	"archived_at":      func(t *models.Task, from models.Task) { t.Archived = from.Archived },
+	"parent_id":        func(t *models.Task, from models.Task) { t.ParentID = from.ParentID },
+	"labels":           func(t *models.Task, from models.Task) { t.Labels = from.Labels },
+}
+
+// Patch writes only the given columns of the task and its update time, and
+// records the change as made by the action.
+func (r *TaskRepository) Patch(ctx context.Context, task models.Task, columns []string, action models.HistoryAction) (models.Task, error) {
+	return r.change(ctx, task.ID, action, func(stored *models.Task) error {
+		for _, column := range columns {
+			set, ok := patchColumns[column]
+			if !ok {
+				return fmt.Errorf("unknown task column: %s", column)
+			}
+			set(stored, task)
+		}
+		stored.Updated = task.Updated
+		return nil
+	})
+}
+
+// CompleteRecurring creates the next occurrence of the task and moves the
+// recurrence rule to it under one lock. It fails with models.ErrNotRecurring
+// when the rule has already been moved.
+func (r *TaskRepository) CompleteRecurring(ctx context.Context, id string, occurrence models.Task, updated time.Time) (models.Task, models.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	old, ok := r.db.tasks[id]
+	if !ok || !old.Deleted.IsZero() {
+		return models.Task{}, models.Task{}, models.ErrTaskNotFound
+	}
+	if old.RRule == "" {
+		return models.Task{}, models.Task{}, models.ErrNotRecurring
+	}
+
+	next, err := r.insert(occurrence)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't schedule next occurrence of: %s", id)
+		return models.Task{}, models.Task{}, err
+	}
+	done := cloneTask(old)
+	done.RRule = ""
+	done.Updated = updated
+	r.db.put(done)
+
+	r.db.record(ctx, models.ActionUpdated, old, done)
+	r.db.record(ctx, models.ActionCreated, models.Task{}, next)
+	return cloneTask(done), next, nil
+}
+
+// CreateTree creates a task with its subtasks, the created tasks are returned
+// parents first. The checklist items aren't kept, only counted.
+func (r *TaskRepository) CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	created, err := r.createTree(tree, tree.Task.ParentID, nil)
+	if err != nil {
+		// roll back, the tasks were never visible
+		for _, task := range created {
+			delete(r.db.tasks, task.ID)
+			delete(r.db.changes, task.ID)
+			delete(r.db.statusChanged, task.ID)
+		}
+		r.log.Error().Err(err).Msgf("can't create task tree: %s", tree.Task.Title)
+		return nil, err
+	}
+	for _, task := range created {
+		r.db.record(ctx, models.ActionCreated, models.Task{}, task)
+	}
+	return created, nil
+}
+
+func (r *TaskRepository) createTree(tree models.TaskTree, parentID string, created []models.Task) ([]models.Task, error) {
+	task := tree.Task
+	task.ParentID = parentID
+	task.ChecklistProgress = models.ChecklistProgress{Total: len(tree.Checklist)}
+
+	task, err := r.insert(task)
+	if err != nil {
+		return created, err
+	}
+	created = append(created, task)
+
+	for _, subtask := range tree.Subtasks {
+		created, err = r.createTree(subtask, task.ID, created)
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// List returns the tasks matching the filter, the oldest first.
+func (r *TaskRepository) List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	tasks := r.find(func(task models.Task) bool {
+		return task.Deleted.IsZero() && filter.Match(task)
+	})
+	slices.SortFunc(tasks, func(a, b models.Task) int {
+		return cmp.Or(a.Created.Compare(b.Created), strings.Compare(a.ID, b.ID))
+	})
+	return tasks, nil
+}
+
+// Stats rolls up the tasks matching the filter. Tasks in statuses of the
+// closed category don't count as open. Work logs aren't kept in memory, so
+// the remaining minutes are the whole estimates.
+func (r *TaskRepository) Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	closed := map[models.TaskStatus]bool{}
+	for _, status := range r.db.statuses {
+		closed[status.Name] = status.Category == models.CategoryClosed
+	}
+
+	groups := map[string]*models.TaskStats{}
+	for _, task := range r.db.tasks {
+		if !task.Deleted.IsZero() || !filter.TaskFilter.Match(task) {
+			continue
+		}
+
+		var key string
+		switch filter.GroupBy {
+		case models.StatsByOwner:
+			key = task.OwnerID
+		case models.StatsByProject:
+			key = task.ProjectID
+		default:
+			key = task.Status.String()
+		}
+
+		stats, ok := groups[key]
+		if !ok {
+			stats = &models.TaskStats{Key: key}
+			groups[key] = stats
+		}
+		stats.Tasks++
+		stats.StoryPoints += task.StoryPoints
+		stats.EstimateMinutes += task.EstimateMinutes
+		if !closed[task.Status] {
+			stats.OpenStoryPoints += task.StoryPoints
+			stats.RemainingMinutes += task.EstimateMinutes
+		}
+	}
+
+	res := make([]models.TaskStats, 0, len(groups))
+	for _, stats := range groups {
+		res = append(res, *stats)
+	}
+	slices.SortFunc(res, func(a, b models.TaskStats) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return res, nil
+}
+
+// Changes lists up to limit tasks changed after the position since in the
+// order of the changes, the deleted ones as tombstones.
+func (r *TaskRepository) Changes(ctx context.Context, since uint64, limit int) (models.TaskChanges, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	if since > 0 && since < r.db.horizon {
+		return models.TaskChanges{}, models.ErrSyncTokenExpired
+	}
+
+	var ids []string
+	for id, seq := range r.db.changes {
+		if seq > since {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		return cmp.Compare(r.db.changes[a], r.db.changes[b])
+	})
+
+	res := models.TaskChanges{
+		Tasks:   []models.Task{},
+		Deleted: []string{},
+		Token:   models.SyncToken(since),
+		More:    len(ids) > limit,
+	}
+	if res.More {
+		ids = ids[:limit]
+	}
+	for _, id := range ids {
+		task := r.db.tasks[id]
+		if !task.Deleted.IsZero() {
+			res.Deleted = append(res.Deleted, id)
+		} else {
+			res.Tasks = append(res.Tasks, cloneTask(task))
+		}
+		res.Token = models.SyncToken(r.db.changes[id])
+	}
+	return res, nil
+}
+
+// change applies fn to a task which isn't in the trash, stores the result
+// and records the revision made by the action.
+func (r *TaskRepository) change(ctx context.Context, id string, action models.HistoryAction, fn func(task *models.Task) error) (models.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	old, ok := r.db.tasks[id]
+	if !ok || !old.Deleted.IsZero() {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+	task := cloneTask(old)
+	if err := fn(&task); err != nil {
+		r.log.Error().Err(err).Msgf("can't update: %s", id)
+		return models.Task{}, err
+	}
+
+	r.db.put(task)
+	r.db.record(ctx, action, old, task)
+	return cloneTask(task), nil
+}
+
+// find returns copies of the tasks for which keep reports true.
+func (r *TaskRepository) find(keep func(task models.Task) bool) []models.Task {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	res := []models.Task{}
+	for _, task := range r.db.tasks {
+		if keep(task) {
+			res = append(res, cloneTask(task))
+		}
+	}
+	return res
+}
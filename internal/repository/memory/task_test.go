@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/VikaPaz/task_tracker/internal/repository/repotest"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/rs/zerolog"
+)
+
+func TestTaskRepository(t *testing.T) {
+	logger := zerolog.Nop()
+	var db *DB
+	repotest.Run(t, repotest.Backend{
+		NewRepo: func(t *testing.T) service.Repo {
+			db = NewDB()
+			return NewTaskRepository(db, &logger)
+		},
+		History: func(t *testing.T) service.HistoryRepo {
+			return NewHistoryRepository(db, &logger)
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type WorkflowRepository struct {
+	db  *DB
+	log *zerolog.Logger
+}
+
+func NewWorkflowRepository(db *DB, logger *zerolog.Logger) *WorkflowRepository {
+	return &WorkflowRepository{
+		db:  db,
+		log: logger,
+	}
+}
+
+func (r *WorkflowRepository) ListStatuses(ctx context.Context) ([]models.WorkflowStatus, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	res := slices.Clone(r.db.statuses)
+	slices.SortFunc(res, func(a, b models.WorkflowStatus) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.Name, b.Name))
+	})
+	return res, nil
+}
+
+func (r *WorkflowRepository) CreateStatus(ctx context.Context, status models.WorkflowStatus) (models.WorkflowStatus, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, val := range r.db.statuses {
+		if val.Name == status.Name {
+			return models.WorkflowStatus{}, models.ErrStatusExists
+		}
+	}
+	r.db.statuses = append(r.db.statuses, status)
+	return status, nil
+}
+
+func (r *WorkflowRepository) DeleteStatus(ctx context.Context, name string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, task := range r.db.tasks {
+		if task.Status.String() == name {
+			return models.ErrStatusInUse
+		}
+	}
+
+	i := slices.IndexFunc(r.db.statuses, func(status models.WorkflowStatus) bool {
+		return status.Name.String() == name
+	})
+	if i < 0 {
+		return models.ErrStatusNotFound
+	}
+	r.db.statuses = slices.Delete(r.db.statuses, i, i+1)
+	return nil
+}
+
+func (r *WorkflowRepository) ListTransitions(ctx context.Context) ([]models.Transition, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	res := slices.Clone(r.db.transitions)
+	slices.SortFunc(res, func(a, b models.Transition) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+	return res, nil
+}
+
+func (r *WorkflowRepository) CreateTransition(ctx context.Context, transition models.Transition) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if !slices.Contains(r.db.transitions, transition) {
+		r.db.transitions = append(r.db.transitions, transition)
+	}
+	return nil
+}
+
+func (r *WorkflowRepository) DeleteTransition(ctx context.Context, transition models.Transition) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := slices.Index(r.db.transitions, transition)
+	if i < 0 {
+		return models.ErrTransitionNotFound
+	}
+	r.db.transitions = slices.Delete(r.db.transitions, i, i+1)
+	return nil
+}
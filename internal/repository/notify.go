@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// eventsChannel carries the IDs of the relayed outbox events.
+const eventsChannel = "task_events"
+
+// pingChannelPrefix starts the name of the channel a listener pings itself on.
+const pingChannelPrefix = "task_events_ping_"
+
+const (
+	// listenTimeout is how long the listener waits before it pings an idle connection
+	listenTimeout = 30 * time.Second
+	// listenRetry is the delay before reconnecting a broken listener
+	listenRetry = 5 * time.Second
+	// listenCatchUp is the number of events passed after a reconnect at most,
+	// as many handled events are remembered to skip them
+	listenCatchUp = 1000
+)
+
+// EventNotifier spreads the relayed task events to every instance with
+// Postgres NOTIFY. A notification carries the ID of the outbox event only,
+// as the payload of NOTIFY is limited to 8000 bytes.
+type EventNotifier struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewEventNotifier(conn *bun.DB, logger *zerolog.Logger) *EventNotifier {
+	return &EventNotifier{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+// Notify tells the listening instances about the event. Within Relay the
+// notification is sent in its transaction, so the listeners are told once
+// the event is marked relayed and not about an event whose relay failed.
+func (n *EventNotifier) Notify(ctx context.Context, event models.TaskEvent) error {
+	var conn bun.IConn = n.conn
+	if tx, ok := ctx.Value(relayTxKey{}).(bun.Tx); ok {
+		conn = tx
+	}
+	_, err := conn.ExecContext(ctx, "NOTIFY ?, ?", bun.Ident(eventsChannel), strconv.FormatUint(event.ID, 10))
+	if err != nil {
+		n.log.Error().Err(err).Msgf("can't notify about event: %d", event.ID)
+		return err
+	}
+	return nil
+}
+
+// Listen passes the events notified by any instance to handle until the
+// context is done. A broken connection is reestablished and the events
+// relayed while it was down are passed first.
+func (n *EventNotifier) Listen(ctx context.Context, handle func(ctx context.Context, event models.TaskEvent)) {
+	seen := newSeenEvents(listenCatchUp)
+	for {
+		err := n.listen(ctx, seen, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		n.log.Error().Err(err).Msgf("task events listener failed, reconnecting in %s", listenRetry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
+		}
+	}
+}
+
+func (n *EventNotifier) listen(ctx context.Context, seen *seenEvents, handle func(ctx context.Context, event models.TaskEvent)) error {
+	ln := pgdriver.NewListener(n.conn)
+	defer ln.Close()
+
+	// the listener doesn't expose its connection to query it, an idle one is
+	// pinged on a channel of its own, which wakes no other instance
+	pingChannel := pingChannelPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if err := ln.Listen(ctx, eventsChannel, pingChannel); err != nil {
+		return err
+	}
+
+	// concurrent relays don't relay the events in the order of their IDs, so
+	// the missed ones are looked for from the oldest seen event on
+	if oldest, ok := seen.oldest(); ok {
+		var missed []OutboxEvent
+		err := n.conn.NewSelect().
+			Model(&missed).
+			Where("id > ?", oldest).
+			Where("relayed_at IS NOT NULL").
+			Order("id").
+			Limit(listenCatchUp + seen.len()).
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+		for _, event := range missed {
+			if seen.add(event.ID) {
+				handle(ctx, modelsEvent(event))
+			}
+		}
+	}
+
+	pinged := false
+	for {
+		channel, payload, err := ln.ReceiveTimeout(ctx, listenTimeout)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() && !pinged && ctx.Err() == nil {
+			// an idle connection may be broken without an error, the ping
+			// comes back only if it is still alive
+			if err := pgdriver.Notify(ctx, n.conn, pingChannel, ""); err != nil {
+				return err
+			}
+			pinged = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pinged = false
+		if channel != eventsChannel || payload == "" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(payload, 10, 64)
+		if err != nil {
+			n.log.Warn().Msgf("invalid task event notification: %q", payload)
+			continue
+		}
+
+		var event OutboxEvent
+		err = n.conn.NewSelect().Model(&event).Where("id = ?", id).Scan(ctx)
+		if err != nil {
+			n.log.Error().Err(err).Msgf("can't get notified event: %d", id)
+			continue
+		}
+		if seen.add(id) {
+			handle(ctx, modelsEvent(event))
+		}
+	}
+}
+
+// seenEvents remembers the IDs of the last handled events.
+type seenEvents struct {
+	ids  []uint64
+	next int
+	set  map[uint64]struct{}
+}
+
+func newSeenEvents(size int) *seenEvents {
+	return &seenEvents{
+		ids: make([]uint64, 0, size),
+		set: make(map[uint64]struct{}, size),
+	}
+}
+
+// add remembers the ID in place of the oldest one once full and reports
+// whether it wasn't seen yet.
+func (s *seenEvents) add(id uint64) bool {
+	if _, ok := s.set[id]; ok {
+		return false
+	}
+	if len(s.ids) < cap(s.ids) {
+		s.ids = append(s.ids, id)
+	} else {
+		delete(s.set, s.ids[s.next])
+		s.ids[s.next] = id
+		s.next = (s.next + 1) % len(s.ids)
+	}
+	s.set[id] = struct{}{}
+	return true
+}
+
+// oldest returns the lowest remembered ID, false when there are none.
+func (s *seenEvents) oldest() (uint64, bool) {
+	if len(s.ids) == 0 {
+		return 0, false
+	}
+	return slices.Min(s.ids), true
+}
+
+func (s *seenEvents) len() int {
+	return len(s.ids)
+}
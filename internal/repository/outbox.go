@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+// OutboxEvent is a task event written in the transaction of the task change,
+// so it is relayed even if the process dies right after the commit.
+type OutboxEvent struct {
+	bun.BaseModel `bun:"table:outbox,alias:ob"`
+
+	ID        uint64      `bun:"id,pk,autoincrement"`
+	TaskID    string      `bun:"task_id,notnull,type:uuid"`
+	EventType string      `bun:"event_type,notnull"`
+	Payload   models.Task `bun:"payload,type:jsonb,notnull"`
+	CreatedAt time.Time   `bun:"created_at,notnull,default:current_timestamp"`
+	RelayedAt time.Time   `bun:"relayed_at,nullzero"`
+}
+
+func modelsEvent(event OutboxEvent) models.TaskEvent {
+	return models.TaskEvent{
+		ID:      event.ID,
+		Type:    models.EventType(event.EventType),
+		Task:    event.Payload,
+		Created: event.CreatedAt,
+	}
+}
+
+type OutboxRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewOutboxRepository(conn *bun.DB, logger *zerolog.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+// addEvents writes the events of the changed tasks to the outbox within tx.
+func addEvents(ctx context.Context, tx bun.Tx, eventType models.EventType, tasks ...Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	events := make([]OutboxEvent, len(tasks))
+	for i, task := range tasks {
+		events[i] = OutboxEvent{
+			TaskID:    task.ID,
+			EventType: string(eventType),
+			Payload:   modelsTask(task),
+			CreatedAt: now,
+		}
+	}
+	_, err := tx.NewInsert().Model(&events).ExcludeColumn("id").Exec(ctx)
+	return err
+}
+
+// relayTxKey carries the transaction of Relay to the handler, which may
+// write within it, like EventNotifier.Notify does.
+type relayTxKey struct{}
+
+// Relay passes up to limit pending events to handle in the order they were
+// written and marks the handled ones relayed. The events are locked with
+// SKIP LOCKED, so concurrent relays handle different events. It stops at
+// the first event handle fails on, which is retried by the next call.
+func (r *OutboxRepository) Relay(ctx context.Context, limit int, handle func(ctx context.Context, event models.TaskEvent) error) (int, error) {
+	relayed := 0
+	var handleErr error
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var events []OutboxEvent
+		err := tx.NewSelect().
+			Model(&events).
+			Where("relayed_at IS NULL").
+			Order("id").
+			Limit(limit).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		var ids []uint64
+		ctx = context.WithValue(ctx, relayTxKey{}, tx)
+		for _, event := range events {
+			handleErr = handle(ctx, modelsEvent(event))
+			if handleErr != nil {
+				break
+			}
+			ids = append(ids, event.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*OutboxEvent)(nil)).
+			Set("relayed_at = ?", time.Now().UTC()).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		relayed = len(ids)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msg("can't relay outbox events")
+		return 0, err
+	}
+
+	return relayed, handleErr
+}
+
+// PurgeRelayed deletes the events relayed before the given time.
+func (r *OutboxRepository) PurgeRelayed(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.conn.NewDelete().
+		Model((*OutboxEvent)(nil)).
+		Where("relayed_at < ?", before).
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't purge outbox events relayed before: %s", before)
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Project struct {
+	bun.BaseModel `bun:"table:projects"`
+
+	ID          string    `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	Name        string    `bun:"name,notnull"`
+	Description string    `bun:"description"`
+	OwnerID     string    `bun:"owner_id,notnull,type:uuid"`
+	CreatedAt   time.Time `bun:"created_at,notnull,default:current_timestamp"`
+	UpdatedAt   time.Time `bun:"updated_at,nullzero,default:current_timestamp"`
+}
+
+type ProjectMember struct {
+	bun.BaseModel `bun:"table:project_members"`
+
+	ProjectID string    `bun:"project_id,pk,type:uuid"`
+	UserID    string    `bun:"user_id,pk,type:uuid"`
+	Role      string    `bun:"role,notnull"`
+	AddedAt   time.Time `bun:"added_at,notnull,default:current_timestamp"`
+}
+
+type ProjectRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewProjectRepository(conn *bun.DB, logger *zerolog.Logger) *ProjectRepository {
+	return &ProjectRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsProject(project Project) models.Project {
+	return models.Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		OwnerID:     project.OwnerID,
+		Created:     project.CreatedAt,
+		Updated:     project.UpdatedAt,
+	}
+}
+
+func repoProject(project models.Project) Project {
+	return Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		OwnerID:     project.OwnerID,
+		CreatedAt:   project.Created,
+		UpdatedAt:   project.Updated,
+	}
+}
+
+func modelsMember(member ProjectMember) models.ProjectMember {
+	return models.ProjectMember{
+		ProjectID: member.ProjectID,
+		UserID:    member.UserID,
+		Role:      models.ProjectRole(member.Role),
+		Added:     member.AddedAt,
+	}
+}
+
+// Create stores the project and makes its owner the first member.
+func (r *ProjectRepository) Create(ctx context.Context, project models.Project) (models.Project, error) {
+	repoProject := repoProject(project)
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(&repoProject).ExcludeColumn("id").Returning("*").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		owner := ProjectMember{
+			ProjectID: repoProject.ID,
+			UserID:    repoProject.OwnerID,
+			Role:      string(models.RoleOwner),
+			AddedAt:   repoProject.CreatedAt,
+		}
+		_, err = tx.NewInsert().Model(&owner).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", project)
+		return models.Project{}, err
+	}
+
+	return modelsProject(repoProject), nil
+}
+
+func (r *ProjectRepository) Get(ctx context.Context, id string) (models.Project, error) {
+	var repoProject Project
+	err := r.conn.NewSelect().Model(&repoProject).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't receiving project: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Project{}, models.ErrProjectNotFound
+		}
+		return models.Project{}, err
+	}
+
+	return modelsProject(repoProject), nil
+}
+
+// List returns the projects the user is a member of.
+func (r *ProjectRepository) List(ctx context.Context, userID string) ([]models.Project, error) {
+	var projects []Project
+	err := r.conn.NewSelect().
+		Model(&projects).
+		Join("JOIN project_members AS m ON m.project_id = project.id").
+		Where("m.user_id = ?", userID).
+		Order("project.name", "project.id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list projects of user: %s", userID)
+		return nil, err
+	}
+
+	res := make([]models.Project, 0, len(projects))
+	for _, val := range projects {
+		res = append(res, modelsProject(val))
+	}
+	return res, nil
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project models.Project) (models.Project, error) {
+	repoProject := repoProject(project)
+	res, err := r.conn.NewUpdate().
+		Model(&repoProject).
+		Column("name", "description", "updated_at").
+		Where("id = ?", repoProject.ID).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't updating: %v", project)
+		return models.Project{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't update: %v", project)
+		return models.Project{}, err
+	}
+	if affected != 1 {
+		return models.Project{}, models.ErrProjectNotFound
+	}
+
+	return modelsProject(repoProject), nil
+}
+
+// Delete removes the project and detaches its tasks in one transaction. The
+// tasks lose their custom field values, which belong to the project, and
+// their IDs are returned.
+func (r *ProjectRepository) Delete(ctx context.Context, id string) ([]string, error) {
+	var tasks []Task
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// the rows are locked, so the revisions hold the values the update drops
+		var old []Task
+		err := tx.NewSelect().
+			Model(&old).
+			WhereAllWithDeleted().
+			Where("project_id = ?", id).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*Task)(nil)).
+			WhereAllWithDeleted().
+			Set("project_id = NULL").
+			Set("custom_fields = '{}'").
+			Where("project_id = ?", id).
+			Returning("*").
+			Exec(ctx, &tasks)
+		if err != nil {
+			return err
+		}
+
+		before := make(map[string]Task, len(old))
+		for _, task := range old {
+			before[task.ID] = task
+		}
+		// trashed tasks are detached without an event, nobody sees them
+		var changed []Task
+		for _, task := range tasks {
+			if task.DeletedAt.IsZero() {
+				changed = append(changed, task)
+			}
+			if err := addRevision(ctx, tx, models.ActionMoved, before[task.ID], task); err != nil {
+				return err
+			}
+		}
+		if err := addEvents(ctx, tx, models.EventUpdated, changed...); err != nil {
+			return err
+		}
+
+		res, err := tx.NewDelete().Model((*Project)(nil)).Where("id = ?", id).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return models.ErrProjectNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete project: %s", id)
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids, nil
+}
+
+func (r *ProjectRepository) Member(ctx context.Context, projectID, userID string) (models.ProjectMember, error) {
+	var member ProjectMember
+	err := r.conn.NewSelect().
+		Model(&member).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ProjectMember{}, models.ErrMemberNotFound
+		}
+		r.log.Error().Err(err).Msgf("can't receiving member %s of project %s", userID, projectID)
+		return models.ProjectMember{}, err
+	}
+
+	return modelsMember(member), nil
+}
+
+func (r *ProjectRepository) Members(ctx context.Context, projectID string) ([]models.ProjectMember, error) {
+	var members []ProjectMember
+	err := r.conn.NewSelect().
+		Model(&members).
+		Where("project_id = ?", projectID).
+		Order("added_at", "user_id").
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list members of project: %s", projectID)
+		return nil, err
+	}
+
+	res := make([]models.ProjectMember, 0, len(members))
+	for _, val := range members {
+		res = append(res, modelsMember(val))
+	}
+	return res, nil
+}
+
+func (r *ProjectRepository) AddMember(ctx context.Context, member models.ProjectMember) (models.ProjectMember, error) {
+	repoMember := ProjectMember{
+		ProjectID: member.ProjectID,
+		UserID:    member.UserID,
+		Role:      string(member.Role),
+		AddedAt:   member.Added,
+	}
+	res, err := r.conn.NewInsert().Model(&repoMember).On("CONFLICT DO NOTHING").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't add member: %v", member)
+		return models.ProjectMember{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't add member: %v", member)
+		return models.ProjectMember{}, err
+	}
+	if affected != 1 {
+		return models.ProjectMember{}, models.ErrMemberExists
+	}
+
+	return modelsMember(repoMember), nil
+}
+
+func (r *ProjectRepository) RemoveMember(ctx context.Context, projectID, userID string) error {
+	res, err := r.conn.NewDelete().
+		Model((*ProjectMember)(nil)).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't remove member %s of project %s", userID, projectID)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't remove member %s of project %s", userID, projectID)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrMemberNotFound
+	}
+
+	return nil
+}
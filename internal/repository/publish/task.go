@@ -0,0 +1,194 @@
+// Package publish passes the task changes of the repositories without an
+// outbox, in memory and SQLite, to the event bus of the process.
+package publish
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/google/uuid"
+)
+
+// Bus receives the published events, eventbus.Bus is one.
+type Bus interface {
+	Publish(event models.TaskEvent) bool
+}
+
+// TaskRepository publishes an event for every task changed through it, the
+// same events the Postgres repository writes to its outbox. The changes are
+// made one at a time, as both storages write one at a time anyway, so the
+// events are published and numbered in the order of the changes. Purged
+// tasks aren't published, like in the outbox.
+type TaskRepository struct {
+	service.Repo
+	bus Bus
+
+	// mu is held for a change and its events
+	mu     sync.Mutex
+	lastID uint64
+}
+
+func NewTaskRepository(repo service.Repo, bus Bus) *TaskRepository {
+	return &TaskRepository{
+		Repo: repo,
+		bus:  bus,
+	}
+}
+
+// publish sends the events of the tasks, the caller holds mu.
+func (r *TaskRepository) publish(eventType models.EventType, tasks ...models.Task) {
+	now := time.Now().UTC()
+	for _, task := range tasks {
+		r.lastID++
+		r.bus.Publish(models.TaskEvent{
+			ID:      r.lastID,
+			Type:    eventType,
+			Task:    task,
+			Created: now,
+		})
+	}
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.Create(ctx, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventCreated, res)
+	return res, nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, req models.Task) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.Update(ctx, req)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventUpdated, res)
+	return res, nil
+}
+
+func (r *TaskRepository) SetAssignee(ctx context.Context, id string, assigneeID string, updated time.Time) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.SetAssignee(ctx, id, assigneeID, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventUpdated, res)
+	return res, nil
+}
+
+func (r *TaskRepository) SetProject(ctx context.Context, id string, projectID string, updated time.Time) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.SetProject(ctx, id, projectID, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventUpdated, res)
+	return res, nil
+}
+
+// Delete publishes the task as it was read right before it was trashed.
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taskID, err := uuid.Parse(id)
+	if err != nil {
+		return models.ErrTaskNotFound
+	}
+	task, err := r.Repo.Get(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if err := r.Repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(models.EventDeleted, task)
+	return nil
+}
+
+func (r *TaskRepository) Restore(ctx context.Context, id string, updated time.Time) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.Restore(ctx, id, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventUpdated, res)
+	return res, nil
+}
+
+func (r *TaskRepository) SetArchived(ctx context.Context, id string, archived time.Time, updated time.Time) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.SetArchived(ctx, id, archived, updated)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventUpdated, res)
+	return res, nil
+}
+
+func (r *TaskRepository) ArchiveStale(ctx context.Context, status models.TaskStatus, before time.Time, archived time.Time) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.ArchiveStale(ctx, status, before, archived)
+	if err != nil {
+		return nil, err
+	}
+	r.publish(models.EventUpdated, res...)
+	return res, nil
+}
+
+func (r *TaskRepository) Patch(ctx context.Context, task models.Task, columns []string, action models.HistoryAction) (models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.Patch(ctx, task, columns, action)
+	if err != nil {
+		return models.Task{}, err
+	}
+	r.publish(models.EventUpdated, res)
+	return res, nil
+}
+
+func (r *TaskRepository) CompleteRecurring(ctx context.Context, id string, occurrence models.Task, updated time.Time) (models.Task, models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	done, next, err := r.Repo.CompleteRecurring(ctx, id, occurrence, updated)
+	if err != nil {
+		return models.Task{}, models.Task{}, err
+	}
+	r.publish(models.EventUpdated, done)
+	r.publish(models.EventCreated, next)
+	return done, next, nil
+}
+
+func (r *TaskRepository) CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, err := r.Repo.CreateTree(ctx, tree)
+	if err != nil {
+		return nil, err
+	}
+	r.publish(models.EventCreated, res...)
+	return res, nil
+}
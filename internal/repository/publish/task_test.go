@@ -0,0 +1,76 @@
+package publish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/eventbus"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/repository/memory"
+	"github.com/VikaPaz/task_tracker/internal/repository/repotest"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func TestTaskRepository(t *testing.T) {
+	repotest.Run(t, repotest.Backend{
+		NewRepo: func(t *testing.T) service.Repo {
+			logger := zerolog.Nop()
+			return NewTaskRepository(memory.NewTaskRepository(memory.NewDB(), &logger), eventbus.New(100, 100))
+		},
+	})
+}
+
+func TestPublish(t *testing.T) {
+	ctx := context.Background()
+	logger := zerolog.Nop()
+	bus := eventbus.New(100, 100)
+	repo := NewTaskRepository(memory.NewTaskRepository(memory.NewDB(), &logger), bus)
+
+	events, cancel, err := bus.Subscribe(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	task, err := repo.Create(ctx, models.Task{Title: "Published", Status: "todo", OwnerID: uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Update(ctx, models.Task{ID: task.ID, Title: "Updated", Updated: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	// failed changes aren't published
+	if err := repo.Delete(ctx, task.ID); err != models.ErrTaskNotFound {
+		t.Errorf("Delete of a deleted task: got error %v, want %v", err, models.ErrTaskNotFound)
+	}
+
+	want := []struct {
+		typ   models.EventType
+		title string
+	}{
+		{models.EventCreated, "Published"},
+		{models.EventUpdated, "Updated"},
+		{models.EventDeleted, "Updated"},
+	}
+	for i, w := range want {
+		select {
+		case event := <-events:
+			if event.ID != uint64(i+1) || event.Type != w.typ || event.Task.ID != task.ID || event.Task.Title != w.title {
+				t.Errorf("event %d = %d %s %q, want %d %s %q", i, event.ID, event.Type, event.Task.Title, i+1, w.typ, w.title)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d wasn't published", i)
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %d %s", event.ID, event.Type)
+	default:
+	}
+}
@@ -0,0 +1,782 @@
+// Package repotest is the contract the task repositories of every storage
+// backend are verified against. A backend runs the same cases from its own
+// tests:
+//
+//	func TestTaskRepository(t *testing.T) {
+//		repotest.Run(t, repotest.Backend{NewRepo: newRepo})
+//	}
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/google/uuid"
+)
+
+// Backend creates the repositories the cases run against.
+type Backend struct {
+	// NewRepo returns a repository without tasks, it's called for every case
+	NewRepo func(t *testing.T) service.Repo
+	// NewProject returns the ID of a project tasks can belong to. Random IDs
+	// are used when it's nil, for backends which don't keep projects.
+	NewProject func(t *testing.T) string
+	// Rename begins a transaction changing the title of the task and returns
+	// the function committing it; transactions of several calls are open at
+	// once. It's nil for backends running one change at a time, where changes
+	// can't commit out of order.
+	Rename func(t *testing.T, id string, title string) (commit func())
+	// DeleteProject deletes the project made by NewProject, nil for a backend
+	// without projects
+	DeleteProject func(t *testing.T, id string)
+	// History returns the task history kept with the tasks of the repository
+	// NewRepo returned last
+	History func(t *testing.T) service.HistoryRepo
+}
+
+// Run runs every case of the contract as a subtest.
+func Run(t *testing.T, backend Backend) {
+	if backend.NewProject == nil {
+		backend.NewProject = func(t *testing.T) string { return uuid.NewString() }
+	}
+
+	cases := []struct {
+		name string
+		run  func(t *testing.T, backend Backend)
+	}{
+		{"CreateGet", testCreateGet},
+		{"Update", testUpdate},
+		{"UpdateExcludesEmptyFields", testUpdateExcludesEmptyFields},
+		{"Delete", testDelete},
+		{"Trash", testTrash},
+		{"NotFound", testNotFound},
+		{"History", testHistory},
+		{"CompleteRecurring", testCompleteRecurring},
+		{"DeleteProject", testDeleteProject},
+		{"ArchiveStale", testArchiveStale},
+		{"ListFilters", testListFilters},
+		{"ListFilterCombinations", testListFilterCombinations},
+		{"Changes", testChanges},
+		{"ChangesCommittedOutOfOrder", testChangesCommittedOutOfOrder},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, backend)
+		})
+	}
+}
+
+// now is truncated to microseconds, the precision of the databases.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func newTask(title string) models.Task {
+	created := now()
+	return models.Task{
+		Title:           title,
+		Description:     "Description of " + title,
+		Status:          "todo",
+		OwnerID:         uuid.NewString(),
+		AssigneeID:      uuid.NewString(),
+		Created:         created,
+		Updated:         created,
+		Due:             created.Add(24 * time.Hour),
+		RRule:           "FREQ=WEEKLY;BYDAY=MO",
+		EstimateMinutes: 90,
+		StoryPoints:     3,
+		CustomFields:    map[string]any{"severity": "high", "points": 5},
+		Labels:          []string{"docs", "q3"},
+	}
+}
+
+func create(t *testing.T, repo service.Repo, task models.Task) models.Task {
+	t.Helper()
+	created, err := repo.Create(context.Background(), task)
+	if err != nil {
+		t.Fatalf("Create(%q): %v", task.Title, err)
+	}
+	if created.ID == "" {
+		t.Fatalf("Create(%q) returned no ID", task.Title)
+	}
+	return created
+}
+
+func get(t *testing.T, repo service.Repo, id string) models.Task {
+	t.Helper()
+	task, err := repo.Get(context.Background(), uuid.MustParse(id))
+	if err != nil {
+		t.Fatalf("Get(%s): %v", id, err)
+	}
+	return task
+}
+
+// assertTask compares the fields a repository keeps, nil and empty
+// collections are the same.
+func assertTask(t *testing.T, got, want models.Task) {
+	t.Helper()
+
+	check := func(field string, equal bool, got, want any) {
+		t.Helper()
+		if !equal {
+			t.Errorf("%s = %v, want %v", field, got, want)
+		}
+	}
+	check("ID", want.ID == "" || got.ID == want.ID, got.ID, want.ID)
+	check("Title", got.Title == want.Title, got.Title, want.Title)
+	check("Description", got.Description == want.Description, got.Description, want.Description)
+	check("Status", got.Status == want.Status, got.Status, want.Status)
+	check("OwnerID", got.OwnerID == want.OwnerID, got.OwnerID, want.OwnerID)
+	check("AssigneeID", got.AssigneeID == want.AssigneeID, got.AssigneeID, want.AssigneeID)
+	check("ProjectID", got.ProjectID == want.ProjectID, got.ProjectID, want.ProjectID)
+	check("ParentID", got.ParentID == want.ParentID, got.ParentID, want.ParentID)
+	check("Created", got.Created.Equal(want.Created), got.Created, want.Created)
+	check("Updated", got.Updated.Equal(want.Updated), got.Updated, want.Updated)
+	check("Due", got.Due.Equal(want.Due), got.Due, want.Due)
+	check("RRule", got.RRule == want.RRule, got.RRule, want.RRule)
+	check("EstimateMinutes", got.EstimateMinutes == want.EstimateMinutes, got.EstimateMinutes, want.EstimateMinutes)
+	check("StoryPoints", got.StoryPoints == want.StoryPoints, got.StoryPoints, want.StoryPoints)
+	check("CustomFields", jsonEqual(got.CustomFields, want.CustomFields), got.CustomFields, want.CustomFields)
+	check("Labels", slices.Equal(got.Labels, want.Labels), got.Labels, want.Labels)
+}
+
+// jsonEqual compares custom fields the way they are stored, numbers read
+// back from json are float64.
+func jsonEqual(got, want map[string]any) bool {
+	if len(got) == 0 || len(want) == 0 {
+		return len(got) == len(want)
+	}
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		return false
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	return string(gotJSON) == string(wantJSON)
+}
+
+func assertNotFound(t *testing.T, op string, err error) {
+	t.Helper()
+	if !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("%s: got error %v, want %v", op, err, models.ErrTaskNotFound)
+	}
+}
+
+func testCreateGet(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	parent := create(t, repo, newTask("Parent"))
+
+	want := newTask("Child")
+	want.ProjectID = backend.NewProject(t)
+	want.ParentID = parent.ID
+
+	created := create(t, repo, want)
+	assertTask(t, created, want)
+	assertTask(t, get(t, repo, created.ID), created)
+}
+
+func testUpdate(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	task := create(t, repo, newTask("Draft"))
+
+	req := models.Task{
+		ID:              task.ID,
+		Title:           "Final",
+		Description:     "Ready for review",
+		Status:          "in_progress",
+		OwnerID:         uuid.NewString(),
+		Updated:         task.Updated.Add(time.Minute),
+		Due:             task.Due.Add(time.Hour),
+		RRule:           "FREQ=DAILY",
+		EstimateMinutes: 120,
+		StoryPoints:     5,
+		CustomFields:    map[string]any{"severity": "low"},
+		Labels:          []string{"review"},
+	}
+	updated, err := repo.Update(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	want := req
+	want.AssigneeID = task.AssigneeID
+	want.Created = task.Created
+	assertTask(t, updated, want)
+	assertTask(t, get(t, repo, task.ID), want)
+}
+
+func testUpdateExcludesEmptyFields(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	parent := create(t, repo, newTask("Parent"))
+	task := newTask("Draft")
+	task.ProjectID = backend.NewProject(t)
+	task.ParentID = parent.ID
+	task = create(t, repo, task)
+
+	// the assignee, the project and the parent have their own methods
+	req := models.Task{
+		ID:         task.ID,
+		Title:      "Final",
+		Updated:    task.Updated.Add(time.Minute),
+		AssigneeID: uuid.NewString(),
+		ProjectID:  backend.NewProject(t),
+		ParentID:   uuid.NewString(),
+	}
+	updated, err := repo.Update(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	want := task
+	want.Title = req.Title
+	want.Updated = req.Updated
+	assertTask(t, updated, want)
+	assertTask(t, get(t, repo, task.ID), want)
+}
+
+func testDelete(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	repo := backend.NewRepo(t)
+	task := create(t, repo, newTask("Obsolete"))
+	kept := create(t, repo, newTask("Kept"))
+
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	_, err := repo.Get(ctx, uuid.MustParse(task.ID))
+	assertNotFound(t, "Get of a deleted task", err)
+	_, err = repo.Update(ctx, models.Task{ID: task.ID, Title: "Revived", Updated: now()})
+	assertNotFound(t, "Update of a deleted task", err)
+	assertNotFound(t, "Delete of a deleted task", repo.Delete(ctx, task.ID))
+
+	list, err := repo.List(ctx, models.TaskFilter{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	assertIDs(t, "List", list, kept.ID)
+}
+
+func testTrash(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	repo := backend.NewRepo(t)
+	task := create(t, repo, newTask("Trashed"))
+	create(t, repo, newTask("Kept"))
+
+	assertNotFound(t, "Purge of a task not in the trash", repo.Purge(ctx, task.ID))
+	_, err := repo.Restore(ctx, task.ID, now())
+	assertNotFound(t, "Restore of a task not in the trash", err)
+
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	trash, err := repo.Trash(ctx, models.TaskFilter{})
+	if err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	assertIDs(t, "Trash", trash, task.ID)
+
+	restored, err := repo.Restore(ctx, task.ID, task.Updated.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	want := task
+	want.Updated = task.Updated.Add(time.Minute)
+	assertTask(t, restored, want)
+	assertTask(t, get(t, repo, task.ID), want)
+
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Purge(ctx, task.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	assertNotFound(t, "Purge of a purged task", repo.Purge(ctx, task.ID))
+	_, err = repo.Restore(ctx, task.ID, now())
+	assertNotFound(t, "Restore of a purged task", err)
+
+	trash, err = repo.Trash(ctx, models.TaskFilter{})
+	if err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	assertIDs(t, "Trash", trash)
+}
+
+func testNotFound(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	repo := backend.NewRepo(t)
+	create(t, repo, newTask("Existing"))
+	id := uuid.NewString()
+
+	_, err := repo.Get(ctx, uuid.MustParse(id))
+	assertNotFound(t, "Get", err)
+	_, err = repo.Update(ctx, models.Task{ID: id, Title: "Missing", Updated: now()})
+	assertNotFound(t, "Update", err)
+	assertNotFound(t, "Delete", repo.Delete(ctx, id))
+	_, err = repo.SetAssignee(ctx, id, uuid.NewString(), now())
+	assertNotFound(t, "SetAssignee", err)
+	_, err = repo.SetProject(ctx, id, "", now())
+	assertNotFound(t, "SetProject", err)
+	_, err = repo.SetArchived(ctx, id, now(), now())
+	assertNotFound(t, "SetArchived", err)
+	_, err = repo.Patch(ctx, models.Task{ID: id, Title: "Missing", Updated: now()}, []string{"title"}, models.ActionUpdated)
+	assertNotFound(t, "Patch", err)
+	_, err = repo.Restore(ctx, id, now())
+	assertNotFound(t, "Restore", err)
+	assertNotFound(t, "Purge", repo.Purge(ctx, id))
+}
+
+// fixture are the tasks the list cases filter. Target and other differ in
+// every filtered field, archived has the fields of target but the ID and
+// the parent.
+type fixture struct {
+	root, target, other, archived models.Task
+}
+
+func newFixture(t *testing.T, backend Backend, repo service.Repo) fixture {
+	t.Helper()
+
+	var f fixture
+	root := newTask("Plan the release")
+	root.Description = "Roadmap items"
+	root.Status = "blocked"
+	root.AssigneeID = ""
+	root.CustomFields = nil
+	root.Labels = nil
+	f.root = create(t, repo, root)
+
+	target := newTask("Write quarterly report")
+	target.Description = "Quarterly numbers for the board"
+	target.ProjectID = backend.NewProject(t)
+	target.ParentID = f.root.ID
+	f.target = create(t, repo, target)
+
+	other := newTask("Review REPORT draft")
+	other.Description = "Check the NUMBERS"
+	other.Status = "in_progress"
+	other.ProjectID = backend.NewProject(t)
+	other.CustomFields = map[string]any{"severity": "medium", "points": 8}
+	other.Labels = []string{"review"}
+	other.ParentID = f.target.ID
+	f.other = create(t, repo, other)
+
+	archived := target
+	archived.ParentID = ""
+	archived = create(t, repo, archived)
+	archived, err := repo.SetArchived(context.Background(), archived.ID, now(), now())
+	if err != nil {
+		t.Fatalf("SetArchived: %v", err)
+	}
+	f.archived = archived
+
+	return f
+}
+
+func list(t *testing.T, repo service.Repo, filter models.TaskFilter) []models.Task {
+	t.Helper()
+	tasks, err := repo.List(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("List(%+v): %v", filter, err)
+	}
+	return tasks
+}
+
+// assertIDs checks the tasks are the wanted ones in any order.
+func assertIDs(t *testing.T, op string, tasks []models.Task, want ...string) {
+	t.Helper()
+	got := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		got = append(got, task.ID)
+	}
+	slices.Sort(got)
+	want = slices.Clone(want)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("%s = %v, want %v", op, got, want)
+	}
+}
+
+func testListFilters(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	f := newFixture(t, backend, repo)
+
+	cases := []struct {
+		name   string
+		filter models.TaskFilter
+		want   []string
+	}{
+		{"Empty", models.TaskFilter{}, []string{f.root.ID, f.target.ID, f.other.ID}},
+		{"IncludeArchived", models.TaskFilter{IncludeArchived: true}, []string{f.root.ID, f.target.ID, f.other.ID, f.archived.ID}},
+		{"IDs", models.TaskFilter{ID: []string{f.root.ID, f.archived.ID}}, []string{f.root.ID}},
+		{"IDsIncludeArchived", models.TaskFilter{ID: []string{f.root.ID, f.archived.ID}, IncludeArchived: true}, []string{f.root.ID, f.archived.ID}},
+		{"UnknownID", models.TaskFilter{ID: []string{uuid.NewString()}}, nil},
+		{"TitleIgnoresCase", models.TaskFilter{Title: "REPORT"}, []string{f.target.ID, f.other.ID}},
+		{"TitleSubstring", models.TaskFilter{Title: "rt dr"}, []string{f.other.ID}},
+		{"DescriptionIgnoresCase", models.TaskFilter{Description: "numbers"}, []string{f.target.ID, f.other.ID}},
+		{"Status", models.TaskFilter{Status: "todo"}, []string{f.target.ID}},
+		{"UnknownStatus", models.TaskFilter{Status: "unknown"}, nil},
+		{"OwnerIncludeArchived", models.TaskFilter{OwnerID: f.target.OwnerID, IncludeArchived: true}, []string{f.target.ID, f.archived.ID}},
+		{"Assignee", models.TaskFilter{AssigneeID: f.other.AssigneeID}, []string{f.other.ID}},
+		{"Project", models.TaskFilter{ProjectID: f.other.ProjectID}, []string{f.other.ID}},
+		{"CustomFieldText", models.TaskFilter{CustomFields: map[string]string{"severity": "medium"}}, []string{f.other.ID}},
+		{"CustomFieldNumber", models.TaskFilter{CustomFields: map[string]string{"points": "5"}}, []string{f.target.ID}},
+		{"CustomFieldsAll", models.TaskFilter{CustomFields: map[string]string{"severity": "high", "points": "8"}}, nil},
+		{"UnknownCustomField", models.TaskFilter{CustomFields: map[string]string{"missing": "high"}}, nil},
+		{"SecondLabel", models.TaskFilter{Label: "q3"}, []string{f.target.ID}},
+		{"LabelIsWhole", models.TaskFilter{Label: "doc"}, nil},
+		{"Parent", models.TaskFilter{ParentID: f.root.ID}, []string{f.target.ID}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assertIDs(t, "List", list(t, repo, c.filter), c.want...)
+		})
+	}
+}
+
+// filterField sets a field of the filter to match the task.
+type filterField struct {
+	name string
+	set  func(filter *models.TaskFilter, task models.Task)
+}
+
+var filterFields = []filterField{
+	{"ID", func(filter *models.TaskFilter, task models.Task) { filter.ID = []string{task.ID} }},
+	{"Title", func(filter *models.TaskFilter, task models.Task) { filter.Title = strings.Fields(task.Title)[0] }},
+	{"Description", func(filter *models.TaskFilter, task models.Task) {
+		filter.Description = strings.Fields(task.Description)[0]
+	}},
+	{"Status", func(filter *models.TaskFilter, task models.Task) { filter.Status = task.Status.String() }},
+	{"OwnerID", func(filter *models.TaskFilter, task models.Task) { filter.OwnerID = task.OwnerID }},
+	{"AssigneeID", func(filter *models.TaskFilter, task models.Task) { filter.AssigneeID = task.AssigneeID }},
+	{"ProjectID", func(filter *models.TaskFilter, task models.Task) { filter.ProjectID = task.ProjectID }},
+	{"CustomFields", func(filter *models.TaskFilter, task models.Task) {
+		filter.CustomFields = map[string]string{"severity": task.CustomFields["severity"].(string)}
+	}},
+	{"Label", func(filter *models.TaskFilter, task models.Task) { filter.Label = task.Labels[0] }},
+	{"ParentID", func(filter *models.TaskFilter, task models.Task) { filter.ParentID = task.ParentID }},
+}
+
+// testListFilterCombinations lists with every combination of the filter
+// fields set to the values of the target task, with and without archived
+// tasks. Then it swaps the last field for the value of the other task.
+func testListFilterCombinations(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	f := newFixture(t, backend, repo)
+
+	for mask := 1; mask < 1<<len(filterFields); mask++ {
+		var names []string
+		var last filterField
+		var filter models.TaskFilter
+		for i, field := range filterFields {
+			if mask&(1<<i) != 0 {
+				names = append(names, field.name)
+				last = field
+				field.set(&filter, f.target)
+			}
+		}
+		name := strings.Join(names, "+")
+
+		// the archived task is told apart by its ID and parent only
+		matchesArchived := !slices.Contains(names, "ID") && !slices.Contains(names, "ParentID")
+		for _, includeArchived := range []bool{false, true} {
+			filter.IncludeArchived = includeArchived
+			want := []string{f.target.ID}
+			if includeArchived && matchesArchived {
+				want = append(want, f.archived.ID)
+			}
+			assertIDs(t, name, list(t, repo, filter), want...)
+		}
+
+		filter.IncludeArchived = true
+		last.set(&filter, f.other)
+		var want []string
+		if len(names) == 1 {
+			want = []string{f.other.ID}
+		}
+		assertIDs(t, name+" with "+last.name+" of another task", list(t, repo, filter), want...)
+	}
+}
+
+func changes(t *testing.T, repo service.Repo, token string, limit int) models.TaskChanges {
+	t.Helper()
+	since, err := models.ParseSyncToken(token)
+	if err != nil {
+		t.Fatalf("ParseSyncToken(%q): %v", token, err)
+	}
+	res, err := repo.Changes(context.Background(), since, limit)
+	if err != nil {
+		t.Fatalf("Changes(%q): %v", token, err)
+	}
+	return res
+}
+
+func testChanges(t *testing.T, backend Backend) {
+	ctx := context.Background()
+	repo := backend.NewRepo(t)
+	first := create(t, repo, newTask("First"))
+	second := create(t, repo, newTask("Second"))
+
+	all := changes(t, repo, "", 10)
+	assertIDs(t, "Changes from the start", all.Tasks, first.ID, second.ID)
+	if all.More {
+		t.Error("Changes from the start has more")
+	}
+
+	page := changes(t, repo, "", 1)
+	assertIDs(t, "First page", page.Tasks, first.ID)
+	if !page.More {
+		t.Error("First page has no more")
+	}
+	page = changes(t, repo, page.Token, 1)
+	assertIDs(t, "Second page", page.Tasks, second.ID)
+	if page.Token != all.Token {
+		t.Errorf("Second page token = %q, want %q", page.Token, all.Token)
+	}
+
+	none := changes(t, repo, all.Token, 10)
+	assertIDs(t, "Changes without changes", none.Tasks)
+	if none.Token != all.Token {
+		t.Errorf("Changes without changes token = %q, want %q", none.Token, all.Token)
+	}
+
+	if _, err := repo.Update(ctx, models.Task{ID: first.ID, Title: "First updated", Updated: now()}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.Delete(ctx, second.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	delta := changes(t, repo, all.Token, 10)
+	assertIDs(t, "Changed tasks", delta.Tasks, first.ID)
+	if !slices.Equal(delta.Deleted, []string{second.ID}) {
+		t.Errorf("Deleted tasks = %v, want %v", delta.Deleted, []string{second.ID})
+	}
+}
+
+// testChangesCommittedOutOfOrder syncs between the commits of two changes,
+// the one started first commits last and must still be synced.
+func testChangesCommittedOutOfOrder(t *testing.T, backend Backend) {
+	if backend.Rename == nil {
+		t.Skip("the backend runs one change at a time")
+	}
+
+	repo := backend.NewRepo(t)
+	first := create(t, repo, newTask("First"))
+	second := create(t, repo, newTask("Second"))
+	token := changes(t, repo, "", 10).Token
+
+	commitFirst := backend.Rename(t, first.ID, "First renamed")
+	commitSecond := backend.Rename(t, second.ID, "Second renamed")
+
+	commitSecond()
+	synced := changes(t, repo, token, 10)
+	assertIDs(t, "Changes after the second commit", synced.Tasks, second.ID)
+
+	commitFirst()
+	synced = changes(t, repo, synced.Token, 10)
+	assertIDs(t, "Changes after the first commit", synced.Tasks, first.ID)
+}
+
+// testCompleteRecurring checks that the rule moves to the next occurrence
+// once: completing the task again doesn't create another one.
+func testCompleteRecurring(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	ctx := context.Background()
+
+	task := create(t, repo, newTask("Weekly"))
+	occurrence := newTask("Weekly")
+	occurrence.Due = task.Due.Add(7 * 24 * time.Hour)
+	updated := now()
+
+	done, next, err := repo.CompleteRecurring(ctx, task.ID, occurrence, updated)
+	if err != nil {
+		t.Fatalf("CompleteRecurring: %v", err)
+	}
+	want := task
+	want.RRule = ""
+	want.Updated = updated
+	assertTask(t, done, want)
+	assertTask(t, get(t, repo, task.ID), want)
+	assertTask(t, get(t, repo, next.ID), occurrence)
+
+	if _, _, err := repo.CompleteRecurring(ctx, task.ID, occurrence, now()); !errors.Is(err, models.ErrNotRecurring) {
+		t.Fatalf("CompleteRecurring again: %v, want %v", err, models.ErrNotRecurring)
+	}
+	tasks, err := repo.List(ctx, models.TaskFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("List returned %d tasks, want the task and one occurrence", len(tasks))
+	}
+	if _, _, err := repo.CompleteRecurring(ctx, uuid.NewString(), occurrence, now()); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("CompleteRecurring of a missing task: %v, want %v", err, models.ErrTaskNotFound)
+	}
+}
+
+// testDeleteProject checks that the tasks of a deleted project are kept
+// without the project and the values of its custom fields, and are synced.
+func testDeleteProject(t *testing.T, backend Backend) {
+	if backend.DeleteProject == nil {
+		t.Skip("backend doesn't keep projects")
+	}
+	repo := backend.NewRepo(t)
+	projectID := backend.NewProject(t)
+
+	task := newTask("Detached")
+	task.ProjectID = projectID
+	task = create(t, repo, task)
+	other := create(t, repo, newTask("Elsewhere"))
+	token := changes(t, repo, "", 10).Token
+
+	backend.DeleteProject(t, projectID)
+
+	assertIDs(t, "Changes after deleting the project", changes(t, repo, token, 10).Tasks, task.ID)
+
+	got := get(t, repo, task.ID)
+	if got.ProjectID != "" {
+		t.Errorf("ProjectID = %q, want none", got.ProjectID)
+	}
+	if len(got.CustomFields) != 0 {
+		t.Errorf("CustomFields = %v, want none", got.CustomFields)
+	}
+	assertTask(t, get(t, repo, other.ID), other)
+
+	if backend.History == nil {
+		return
+	}
+	revisions, err := backend.History(t).List(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if last := revisions[len(revisions)-1]; last.Action != models.ActionMoved {
+		t.Errorf("last revision is %s, want %s", last.Action, models.ActionMoved)
+	}
+}
+
+// testArchiveStale checks that tasks are archived after they have been in
+// the status long enough, however recently they were edited.
+func testArchiveStale(t *testing.T, backend Backend) {
+	repo := backend.NewRepo(t)
+	ctx := context.Background()
+	long := now().Add(-10 * 24 * time.Hour)
+
+	done := newTask("Done long ago")
+	done.Status = models.Done
+	done.Created, done.Updated = long, long
+	done = create(t, repo, done)
+	if _, err := repo.Update(ctx, models.Task{ID: done.ID, Title: "Edited since", Updated: now()}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	recent := newTask("Done recently")
+	recent.Created, recent.Updated = long, long
+	recent = create(t, repo, recent)
+	if _, err := repo.Update(ctx, models.Task{ID: recent.ID, Status: models.Done, Updated: now()}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	open := newTask("Open")
+	open.Created, open.Updated = long, long
+	open = create(t, repo, open)
+
+	archived, err := repo.ArchiveStale(ctx, models.Done, now().Add(-5*24*time.Hour), now())
+	if err != nil {
+		t.Fatalf("ArchiveStale: %v", err)
+	}
+	assertIDs(t, "ArchiveStale", archived, done.ID)
+	if got := get(t, repo, done.ID); got.Archived.IsZero() {
+		t.Error("the task done long ago isn't archived")
+	}
+	for _, id := range []string{recent.ID, open.ID} {
+		if got := get(t, repo, id); !got.Archived.IsZero() {
+			t.Errorf("task %q is archived", got.Title)
+		}
+	}
+}
+
+// testHistory checks that every change writes its revision, made by the user
+// of the context.
+func testHistory(t *testing.T, backend Backend) {
+	if backend.History == nil {
+		t.Skip("backend doesn't keep the task history")
+	}
+	repo := backend.NewRepo(t)
+	history := backend.History(t)
+	actorID := uuid.NewString()
+	ctx := auth.WithUser(context.Background(), actorID)
+
+	task, err := repo.Create(ctx, newTask("Tracked"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	id := task.ID
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"Update", func() error {
+			_, err := repo.Update(ctx, models.Task{ID: id, Title: "Renamed", Updated: now()})
+			return err
+		}},
+		{"Update without changes", func() error {
+			_, err := repo.Update(ctx, models.Task{ID: id, Title: "Renamed", Updated: now()})
+			return err
+		}},
+		{"SetAssignee", func() error {
+			_, err := repo.SetAssignee(ctx, id, "", now())
+			return err
+		}},
+		{"SetArchived", func() error {
+			_, err := repo.SetArchived(ctx, id, now(), now())
+			return err
+		}},
+		{"Patch", func() error {
+			_, err := repo.Patch(ctx, models.Task{ID: id, Title: "Tracked", Updated: now()}, []string{"title"}, models.ActionReverted)
+			return err
+		}},
+		{"Delete", func() error { return repo.Delete(ctx, id) }},
+		{"Restore", func() error {
+			_, err := repo.Restore(ctx, id, now())
+			return err
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+	}
+
+	revisions, err := history.List(context.Background(), id)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	var actions []models.HistoryAction
+	for _, rev := range revisions {
+		actions = append(actions, rev.Action)
+		if rev.ActorID != actorID {
+			t.Errorf("%s revision by %q, want %q", rev.Action, rev.ActorID, actorID)
+		}
+	}
+	want := []models.HistoryAction{
+		models.ActionCreated, models.ActionUpdated, models.ActionUnassigned, models.ActionArchived,
+		models.ActionReverted, models.ActionDeleted, models.ActionRestored,
+	}
+	if !slices.Equal(actions, want) {
+		t.Fatalf("History = %v, want %v", actions, want)
+	}
+
+	renamed := revisions[1].Changes
+	if len(renamed) != 1 || renamed[0].Field != "title" || renamed[0].Old != "Tracked" || renamed[0].New != "Renamed" {
+		t.Errorf("changes of the update = %+v, want the title from Tracked to Renamed", renamed)
+	}
+}
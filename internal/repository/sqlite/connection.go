@@ -0,0 +1,55 @@
+// Package sqlite keeps the tasks, the workflow and the task history in a
+// SQLite database file. It serves single node deployments without a
+// Postgres server, the schema is created by the migrations in
+// migrations/sqlite.
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+)
+
+type Config struct {
+	// Path is the database file, ":memory:" keeps the database in memory
+	Path string
+}
+
+// Connection opens the database file, creating it if it doesn't exist.
+// SQLite allows a single writer, so the connection isn't pooled.
+func Connection(conf Config, logger *zerolog.Logger) (*bun.DB, error) {
+	sqlDB, err := sql.Open(sqliteshim.ShimName, conf.Path)
+	if err != nil {
+		return nil, err
+	}
+	// the pragmas are set per connection, keep the only one open
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+
+	for _, pragma := range []string{"PRAGMA foreign_keys = ON", "PRAGMA busy_timeout = 5000"} {
+		if _, err := sqlDB.Exec(pragma); err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+	}
+
+	db := bun.NewDB(sqlDB, sqlitedialect.New())
+	logger.Debug().Msgf("connection: %s", conf.Path)
+	return db, nil
+}
+
+type TaskRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewTaskRepository(conn *bun.DB, logger *zerolog.Logger) *TaskRepository {
+	return &TaskRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
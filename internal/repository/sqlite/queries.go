@@ -0,0 +1,772 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Task is the row of the tasks table. SQLite has no uuid and array types,
+// the IDs are generated here and the labels and watchers kept as json.
+type Task struct {
+	ID          string `bun:"id,pk"`
+	Title       string `bun:"column:notnull"`
+	Description string
+	CreatedAt   time.Time `bun:"column:notnull,default:current_timestamp"`
+	UpdatedAt   time.Time `bun:"column:nullzero,default:current_timestamp"`
+	Status      string    `bun:"column:notnull"`
+	OwnerID     string    `bun:"column:notnull"`
+	AssigneeID  string    `bun:"assignee_id,nullzero"`
+	DueAt       time.Time `bun:"due_at,nullzero"`
+	RRule       string    `bun:"rrule,nullzero"`
+	ProjectID   string    `bun:"project_id,nullzero"`
+
+	EstimateMinutes int `bun:"estimate_minutes,notnull"`
+	StoryPoints     int `bun:"story_points,notnull"`
+
+	ChecklistChecked int `bun:"checklist_checked,notnull"`
+	ChecklistTotal   int `bun:"checklist_total,notnull"`
+
+	CustomFields map[string]any `bun:"custom_fields,nullzero"`
+
+	DeletedAt  time.Time `bun:"deleted_at,soft_delete,nullzero"`
+	ArchivedAt time.Time `bun:"archived_at,nullzero"`
+	ParentID   string    `bun:"parent_id,nullzero"`
+	Labels     []string  `bun:"labels,nullzero"`
+	Watchers   []string  `bun:"watchers,nullzero"`
+
+	// ChangeSeq is advanced by the database on every insert and update
+	ChangeSeq uint64 `bun:"change_seq,scanonly"`
+	// StatusChangedAt is set by the database when the task gets its status
+	StatusChangedAt time.Time `bun:"status_changed_at,nullzero,scanonly"`
+}
+
+var _ bun.BeforeAppendModelHook = (*Task)(nil)
+
+// BeforeAppendModel generates the ID of an inserted task.
+func (t *Task) BeforeAppendModel(ctx context.Context, query bun.Query) error {
+	if _, ok := query.(*bun.InsertQuery); ok && t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	return nil
+}
+
+type ChecklistItem struct {
+	bun.BaseModel `bun:"table:checklist_items"`
+
+	ID       string `bun:"id,pk"`
+	TaskID   string `bun:"task_id,notnull"`
+	Text     string `bun:"text,notnull"`
+	Checked  bool   `bun:"checked,notnull"`
+	Position int    `bun:"position,notnull"`
+}
+
+type taskStats struct {
+	Key              string `bun:"key"`
+	Tasks            int    `bun:"tasks"`
+	StoryPoints      int    `bun:"story_points"`
+	OpenStoryPoints  int    `bun:"open_story_points"`
+	EstimateMinutes  int    `bun:"estimate_minutes"`
+	RemainingMinutes int    `bun:"remaining_minutes"`
+}
+
+func modelsTask(task Task) models.Task {
+	res := models.Task{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Created:     task.CreatedAt,
+		Updated:     task.UpdatedAt,
+		Status:      models.TaskStatus(task.Status),
+		OwnerID:     task.OwnerID,
+		AssigneeID:  task.AssigneeID,
+		Due:         task.DueAt,
+		RRule:       task.RRule,
+		ProjectID:   task.ProjectID,
+
+		EstimateMinutes: task.EstimateMinutes,
+		StoryPoints:     task.StoryPoints,
+
+		ChecklistProgress: models.ChecklistProgress{
+			Checked: task.ChecklistChecked,
+			Total:   task.ChecklistTotal,
+		},
+		CustomFields: task.CustomFields,
+		Deleted:      task.DeletedAt,
+		Archived:     task.ArchivedAt,
+		ParentID:     task.ParentID,
+		Labels:       task.Labels,
+		Watchers:     task.Watchers,
+	}
+	return res
+}
+
+func repoTask(task models.Task) Task {
+	res := Task{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		CreatedAt:   task.Created,
+		UpdatedAt:   task.Updated,
+		Status:      task.Status.String(),
+		OwnerID:     task.OwnerID,
+		AssigneeID:  task.AssigneeID,
+		DueAt:       task.Due,
+		RRule:       task.RRule,
+		ProjectID:   task.ProjectID,
+
+		EstimateMinutes: task.EstimateMinutes,
+		StoryPoints:     task.StoryPoints,
+
+		ChecklistChecked: task.ChecklistProgress.Checked,
+		ChecklistTotal:   task.ChecklistProgress.Total,
+
+		CustomFields: task.CustomFields,
+		DeletedAt:    task.Deleted,
+		ArchivedAt:   task.Archived,
+		ParentID:     task.ParentID,
+		Labels:       task.Labels,
+		Watchers:     task.Watchers,
+	}
+	return res
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task models.Task) (models.Task, error) {
+	repoTask := repoTask(task)
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(&repoTask).Returning("*").Exec(ctx); err != nil {
+			return err
+		}
+		return addRevision(ctx, tx, models.ActionCreated, Task{}, repoTask)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", task)
+		return models.Task{}, err
+	}
+	r.log.Debug().Msgf("maked struct %v", task)
+
+	return modelsTask(repoTask), nil
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	var repoTask Task
+	err := r.conn.NewSelect().Model(&repoTask).Where("id = ?", id.String()).Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't receiving: %v", repoTask)
+		if err == sql.ErrNoRows {
+			return models.Task{}, models.ErrTaskNotFound
+		}
+		return models.Task{}, err
+	}
+	r.log.Debug().Msgf("received struct %v", repoTask)
+
+	return modelsTask(repoTask), nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, req models.Task) (models.Task, error) {
+	repoTask := repoTask(req)
+
+	err := r.update(ctx, &repoTask, models.ActionUpdated, func(tx bun.Tx) *bun.UpdateQuery {
+		query := tx.NewUpdate().
+			Model(&repoTask).
+			WherePK("id").
+			ExcludeColumn("created_at", "assignee_id", "project_id", "checklist_checked", "checklist_total", "deleted_at", "archived_at", "parent_id", "watchers").
+			Returning("*")
+
+		if repoTask.Title == "" {
+			query.ExcludeColumn("title")
+		}
+		if repoTask.Description == "" {
+			query.ExcludeColumn("description")
+		}
+		if repoTask.Status == "" {
+			query.ExcludeColumn("status")
+		}
+		if repoTask.OwnerID == "" {
+			query.ExcludeColumn("owner_id")
+		}
+		if repoTask.DueAt.IsZero() {
+			query.ExcludeColumn("due_at")
+		}
+		if repoTask.RRule == "" {
+			query.ExcludeColumn("rrule")
+		}
+		if repoTask.EstimateMinutes == 0 {
+			query.ExcludeColumn("estimate_minutes")
+		}
+		if repoTask.StoryPoints == 0 {
+			query.ExcludeColumn("story_points")
+		}
+		if repoTask.CustomFields == nil {
+			query.ExcludeColumn("custom_fields")
+		}
+		if repoTask.Labels == nil {
+			query.ExcludeColumn("labels")
+		}
+		return query
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't update: %v", repoTask)
+		return models.Task{}, err
+	}
+
+	return modelsTask(repoTask), nil
+}
+
+// update runs the query changing a single task and writes the revision made
+// by the action in the same transaction. The query returns the task into
+// repoTask.
+func (r *TaskRepository) update(ctx context.Context, repoTask *Task, action models.HistoryAction, query func(tx bun.Tx) *bun.UpdateQuery) error {
+	return r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		return updateTx(ctx, tx, repoTask, action, query)
+	})
+}
+
+// updateTx is update within the transaction tx.
+func updateTx(ctx context.Context, tx bun.Tx, repoTask *Task, action models.HistoryAction, query func(tx bun.Tx) *bun.UpdateQuery) error {
+	var old Task
+	err := tx.NewSelect().Model(&old).WhereAllWithDeleted().Where("id = ?", repoTask.ID).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := query(tx).Exec(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return models.ErrTaskNotFound
+	}
+
+	return addRevision(ctx, tx, action, old, *repoTask)
+}
+
+// SetAssignee changes the assignee of a task, an empty assigneeID unassigns it.
+func (r *TaskRepository) SetAssignee(ctx context.Context, id string, assigneeID string, updated time.Time) (models.Task, error) {
+	repoTask := Task{
+		ID:         id,
+		AssigneeID: assigneeID,
+		UpdatedAt:  updated,
+	}
+	action := models.ActionAssigned
+	if assigneeID == "" {
+		action = models.ActionUnassigned
+	}
+
+	err := r.update(ctx, &repoTask, action, func(tx bun.Tx) *bun.UpdateQuery {
+		return tx.NewUpdate().
+			Model(&repoTask).
+			Column("assignee_id", "updated_at").
+			Where("id = ?", id).
+			Returning("*")
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't assign: %v", repoTask)
+		return models.Task{}, err
+	}
+
+	return modelsTask(repoTask), nil
+}
+
+// SetProject moves a task to a project, an empty projectID removes it from its project.
+// Custom field values are dropped as they belong to the definitions of the previous project.
+func (r *TaskRepository) SetProject(ctx context.Context, id string, projectID string, updated time.Time) (models.Task, error) {
+	repoTask := Task{
+		ID:        id,
+		ProjectID: projectID,
+		UpdatedAt: updated,
+	}
+
+	err := r.update(ctx, &repoTask, models.ActionMoved, func(tx bun.Tx) *bun.UpdateQuery {
+		return tx.NewUpdate().
+			Model(&repoTask).
+			Column("project_id", "updated_at", "custom_fields").
+			Where("id = ?", id).
+			Returning("*")
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't move: %v", repoTask)
+		return models.Task{}, err
+	}
+
+	return modelsTask(repoTask), nil
+}
+
+// Delete moves a task to the trash, it stays there until it is purged.
+func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	task := &Task{ID: id}
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewDelete().Model(task).Where("id = ?", id).Returning("*").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return models.ErrTaskNotFound
+		}
+
+		return addRevision(ctx, tx, models.ActionDeleted, *task, *task)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete: %s", id)
+		return err
+	}
+
+	return nil
+}
+
+// SetArchived archives a task at the given time, a zero time unarchives it.
+func (r *TaskRepository) SetArchived(ctx context.Context, id string, archived time.Time, updated time.Time) (models.Task, error) {
+	repoTask := Task{
+		ID:         id,
+		UpdatedAt:  updated,
+		ArchivedAt: archived,
+	}
+	action := models.ActionArchived
+	if archived.IsZero() {
+		action = models.ActionUnarchived
+	}
+
+	err := r.update(ctx, &repoTask, action, func(tx bun.Tx) *bun.UpdateQuery {
+		return tx.NewUpdate().
+			Model(&repoTask).
+			Column("archived_at", "updated_at").
+			Where("id = ?", id).
+			Returning("*")
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't archive: %v", repoTask)
+		return models.Task{}, err
+	}
+
+	return modelsTask(repoTask), nil
+}
+
+// ArchiveStale archives the tasks which have been in the status since before
+// and returns them. updated_at isn't touched, so the tasks keep the time they
+// were last worked on. The database keeps the time the status was set in
+// status_changed_at, the tasks from before it was added fall back on updated_at.
+func (r *TaskRepository) ArchiveStale(ctx context.Context, status models.TaskStatus, before time.Time, archived time.Time) ([]models.Task, error) {
+	var tasks []Task
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*Task)(nil)).
+			Set("archived_at = ?", archived).
+			Where("status = ?", status).
+			Where("archived_at IS NULL").
+			Where("coalesce(status_changed_at, updated_at) < ?", before).
+			Returning("*").
+			Exec(ctx, &tasks)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			old := task
+			old.ArchivedAt = time.Time{}
+			if err := addRevision(ctx, tx, models.ActionArchived, old, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't archive tasks in %s since before: %s", status, before)
+		return nil, err
+	}
+
+	res := make([]models.Task, 0, len(tasks))
+	for _, val := range tasks {
+		res = append(res, modelsTask(val))
+	}
+	return res, nil
+}
+
+// Patch writes the given columns of the task, zero values included, and
+// records the change as made by the action.
+func (r *TaskRepository) Patch(ctx context.Context, task models.Task, columns []string, action models.HistoryAction) (models.Task, error) {
+	repoTask := repoTask(task)
+	err := r.update(ctx, &repoTask, action, func(tx bun.Tx) *bun.UpdateQuery {
+		return tx.NewUpdate().
+			Model(&repoTask).
+			Column(columns...).
+			Column("updated_at").
+			Where("id = ?", repoTask.ID).
+			Returning("*")
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't patch %v: %v", columns, repoTask)
+		return models.Task{}, err
+	}
+
+	return modelsTask(repoTask), nil
+}
+
+// CompleteRecurring moves the recurrence rule of the task to its next
+// occurrence: it creates the occurrence and clears the rule of the task in
+// one transaction. It fails with models.ErrNotRecurring when the rule has
+// already been moved, so the occurrence isn't created twice.
+func (r *TaskRepository) CompleteRecurring(ctx context.Context, id string, occurrence models.Task, updated time.Time) (models.Task, models.Task, error) {
+	done := Task{ID: id, UpdatedAt: updated}
+	next := repoTask(occurrence)
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var current Task
+		err := tx.NewSelect().Model(&current).Column("rrule").Where("id = ?", id).Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if current.RRule == "" {
+			return models.ErrNotRecurring
+		}
+
+		err = updateTx(ctx, tx, &done, models.ActionUpdated, func(tx bun.Tx) *bun.UpdateQuery {
+			return tx.NewUpdate().
+				Model(&done).
+				Column("rrule", "updated_at").
+				Where("id = ?", id).
+				Returning("*")
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.NewInsert().Model(&next).Returning("*").Exec(ctx); err != nil {
+			return err
+		}
+		return addRevision(ctx, tx, models.ActionCreated, Task{}, next)
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't schedule next occurrence of: %s", id)
+		return models.Task{}, models.Task{}, err
+	}
+
+	return modelsTask(done), modelsTask(next), nil
+}
+
+// Trash lists the trashed tasks matching the filter, the most recently deleted first.
+// Archived tasks are listed as well.
+func (r *TaskRepository) Trash(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	filter.IncludeArchived = true
+	query := filterTasks(r.conn.NewSelect().Model(&Task{}).WhereDeleted(), filter).
+		Order("task.deleted_at DESC")
+
+	var tasks []Task
+	err := query.Scan(ctx, &tasks)
+	if err != nil {
+		r.log.Error().Err(err).Msg("failed to list trashed tasks")
+		return nil, err
+	}
+
+	res := make([]models.Task, 0, len(tasks))
+	for _, val := range tasks {
+		res = append(res, modelsTask(val))
+	}
+	return res, nil
+}
+
+// Restore takes a task out of the trash.
+func (r *TaskRepository) Restore(ctx context.Context, id string, updated time.Time) (models.Task, error) {
+	repoTask := Task{ID: id}
+	err := r.update(ctx, &repoTask, models.ActionRestored, func(tx bun.Tx) *bun.UpdateQuery {
+		return tx.NewUpdate().
+			Model(&repoTask).
+			WhereDeleted().
+			Set("deleted_at = NULL").
+			Set("updated_at = ?", updated).
+			Where("id = ?", id).
+			Returning("*")
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't restore: %s", id)
+		return models.Task{}, err
+	}
+
+	return modelsTask(repoTask), nil
+}
+
+// Purge permanently deletes a trashed task, the database removes the rows bound to it.
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	res, err := r.conn.NewDelete().
+		Model((*Task)(nil)).
+		WhereDeleted().
+		Where("id = ?", id).
+		ForceDelete().
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't purge: %s", id)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't purge: %s", id)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently deletes the tasks trashed before the given time and returns their IDs.
+func (r *TaskRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	var ids []string
+	_, err := r.conn.NewDelete().
+		Model((*Task)(nil)).
+		WhereDeleted().
+		Where("deleted_at < ?", before).
+		ForceDelete().
+		Returning("id").
+		Exec(ctx, &ids)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't purge tasks deleted before: %s", before)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *TaskRepository) List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	query := filterTasks(r.conn.NewSelect().Model(&Task{}), filter)
+
+	var tasks []Task
+	err := query.Scan(ctx, &tasks)
+	if err != nil {
+		r.log.Error().Err(err).Msg("failed to list tasks")
+		return nil, err
+	}
+
+	res := make([]models.Task, 0, len(tasks))
+	for _, val := range tasks {
+		res = append(res, modelsTask(val))
+	}
+	return res, nil
+}
+
+var statsKeys = map[models.StatsGroup]string{
+	models.StatsByStatus:  "task.status",
+	models.StatsByOwner:   "task.owner_id",
+	models.StatsByProject: "coalesce(task.project_id, '')",
+}
+
+// Stats rolls up the tasks matching the filter. Tasks in statuses of the
+// closed category don't count as open. Work logs aren't kept in SQLite, so
+// the remaining minutes are the estimates of the open tasks.
+func (r *TaskRepository) Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error) {
+	key, ok := statsKeys[filter.GroupBy]
+	if !ok {
+		key = statsKeys[models.StatsByStatus]
+	}
+
+	query := r.conn.NewSelect().
+		Model((*Task)(nil)).
+		ColumnExpr(key+" AS key").
+		ColumnExpr("count(*) AS tasks").
+		ColumnExpr("coalesce(sum(task.story_points), 0) AS story_points").
+		ColumnExpr("coalesce(sum(task.story_points) FILTER (WHERE ws.category IS NOT ?), 0) AS open_story_points", string(models.CategoryClosed)).
+		ColumnExpr("coalesce(sum(task.estimate_minutes), 0) AS estimate_minutes").
+		ColumnExpr("coalesce(sum(task.estimate_minutes) FILTER (WHERE ws.category IS NOT ?), 0) AS remaining_minutes", string(models.CategoryClosed)).
+		Join("LEFT JOIN workflow_statuses AS ws ON ws.name = task.status").
+		GroupExpr("key").
+		OrderExpr("key")
+	query = filterTasks(query, filter.TaskFilter)
+
+	var rows []taskStats
+	err := query.Scan(ctx, &rows)
+	if err != nil {
+		r.log.Error().Err(err).Msg("failed to roll up tasks")
+		return nil, err
+	}
+
+	res := make([]models.TaskStats, 0, len(rows))
+	for _, val := range rows {
+		res = append(res, models.TaskStats(val))
+	}
+	return res, nil
+}
+
+// customFieldText reads a custom field as text the way Postgres ->> does:
+// strings unquoted, other values as json and json null as NULL.
+const customFieldText = `CASE json_type(task.custom_fields, ?0)
+	WHEN 'null' THEN NULL
+	WHEN 'text' THEN task.custom_fields ->> ?0
+	ELSE task.custom_fields -> ?0 END`
+
+func filterTasks(query *bun.SelectQuery, filter models.TaskFilter) *bun.SelectQuery {
+	if len(filter.ID) > 0 {
+		query = query.Where("task.id IN (?)", bun.In(filter.ID))
+	}
+
+	// LIKE ignores the case of ASCII letters only
+	if filter.Title != "" {
+		query = query.Where("task.title LIKE ?", "%"+filter.Title+"%")
+	}
+
+	if filter.Description != "" {
+		query = query.Where("task.description LIKE ?", "%"+filter.Description+"%")
+	}
+
+	if filter.Status != "" {
+		query = query.Where("task.status = ?", filter.Status)
+	}
+
+	if filter.OwnerID != "" {
+		query = query.Where("task.owner_id = ?", filter.OwnerID)
+	}
+
+	if filter.AssigneeID != "" {
+		query = query.Where("task.assignee_id = ?", filter.AssigneeID)
+	}
+
+	if filter.ProjectID != "" {
+		query = query.Where("task.project_id = ?", filter.ProjectID)
+	}
+
+	for name, value := range filter.CustomFields {
+		query = query.Where("("+customFieldText+") = ?1", `$."`+name+`"`, value)
+	}
+
+	if filter.Label != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM json_each(task.labels) WHERE value = ?)", filter.Label)
+	}
+
+	if filter.ParentID != "" {
+		query = query.Where("task.parent_id = ?", filter.ParentID)
+	}
+
+	if !filter.IncludeArchived {
+		query = query.Where("task.archived_at IS NULL")
+	}
+
+	return query
+}
+
+// CreateTree creates a task with its checklist and subtasks in one transaction.
+// The created tasks are returned parents first.
+func (r *TaskRepository) CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error) {
+	var res []models.Task
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		var err error
+		res, err = createTree(ctx, tx, tree, tree.Task.ParentID, nil)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't create task tree: %s", tree.Task.Title)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func createTree(ctx context.Context, tx bun.Tx, tree models.TaskTree, parentID string, created []models.Task) ([]models.Task, error) {
+	repoTask := repoTask(tree.Task)
+	repoTask.ParentID = parentID
+	repoTask.ChecklistChecked, repoTask.ChecklistTotal = 0, len(tree.Checklist)
+
+	_, err := tx.NewInsert().Model(&repoTask).Returning("*").Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := addRevision(ctx, tx, models.ActionCreated, Task{}, repoTask); err != nil {
+		return nil, err
+	}
+	created = append(created, modelsTask(repoTask))
+
+	if len(tree.Checklist) > 0 {
+		items := make([]ChecklistItem, len(tree.Checklist))
+		for i, text := range tree.Checklist {
+			items[i] = ChecklistItem{ID: uuid.NewString(), TaskID: repoTask.ID, Text: text, Position: i}
+		}
+		_, err = tx.NewInsert().Model(&items).Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, subtask := range tree.Subtasks {
+		created, err = createTree(ctx, tx, subtask, repoTask.ID, created)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// Changes lists up to limit tasks changed after the position since in the
+// order of the changes, the deleted ones as tombstones. SQLite runs one
+// write transaction at a time, so changes commit in the order of change_seq
+// and none shows up below a token already returned.
+func (r *TaskRepository) Changes(ctx context.Context, since uint64, limit int) (models.TaskChanges, error) {
+	if since > 0 {
+		var horizon uint64
+		err := r.conn.NewSelect().
+			Table("task_sync_horizon").
+			Column("change_seq").
+			Where("id = 1").
+			Scan(ctx, &horizon)
+		if err != nil {
+			r.log.Error().Err(err).Msg("failed to get the sync horizon")
+			return models.TaskChanges{}, err
+		}
+		if since < horizon {
+			return models.TaskChanges{}, models.ErrSyncTokenExpired
+		}
+	}
+
+	var changes []Task
+	err := r.conn.NewSelect().
+		Model(&changes).
+		ColumnExpr("task.*").
+		WhereAllWithDeleted().
+		Where("change_seq > ?", since).
+		Order("change_seq").
+		Limit(limit + 1).
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list tasks changed after: %d", since)
+		return models.TaskChanges{}, err
+	}
+
+	res := models.TaskChanges{
+		Tasks:   []models.Task{},
+		Deleted: []string{},
+		Token:   models.SyncToken(since),
+		More:    len(changes) > limit,
+	}
+	if res.More {
+		changes = changes[:limit]
+	}
+	for _, change := range changes {
+		if !change.DeletedAt.IsZero() {
+			res.Deleted = append(res.Deleted, change.ID)
+		} else {
+			res.Tasks = append(res.Tasks, modelsTask(change))
+		}
+		res.Token = models.SyncToken(change.ChangeSeq)
+	}
+	return res, nil
+}
@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/VikaPaz/task_tracker/internal/repository/repotest"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/pressly/goose"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+func TestTaskRepository(t *testing.T) {
+	logger := zerolog.Nop()
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	goose.SetLogger(log.New(io.Discard, "", 0))
+
+	var db *bun.DB
+	repotest.Run(t, repotest.Backend{
+		NewRepo: func(t *testing.T) service.Repo {
+			var err error
+			db, err = Connection(Config{Path: ":memory:"}, &logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Close() })
+
+			if err := goose.Up(db.DB, "../../../migrations/sqlite"); err != nil {
+				t.Fatal(err)
+			}
+			return NewTaskRepository(db, &logger)
+		},
+		History: func(t *testing.T) service.HistoryRepo {
+			return NewHistoryRepository(db, &logger)
+		},
+	})
+}
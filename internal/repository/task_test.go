@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/repository/repotest"
+	"github.com/VikaPaz/task_tracker/internal/service"
+	"github.com/google/uuid"
+	"github.com/pressly/goose"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+// testDB connects to the database at TEST_DATABASE_URL and migrates it, the
+// test is skipped without one.
+func testDB(t *testing.T) *bun.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	logger := zerolog.Nop()
+	db := Connection(Config{DSN: dsn}, &logger)
+	t.Cleanup(func() { db.Close() })
+
+	if err := goose.SetDialect("postgres"); err != nil {
+		t.Fatal(err)
+	}
+	goose.SetLogger(log.New(io.Discard, "", 0))
+	if err := goose.Up(db.DB, "../../migrations"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// truncate deletes the tasks, the projects and the outbox.
+func truncate(t *testing.T, db *bun.DB) {
+	_, err := db.NewTruncateTable().Table("tasks", "projects", "outbox").Cascade().Exec(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTaskRepository runs against the database at TEST_DATABASE_URL, its
+// tasks and projects are deleted before every case.
+func TestTaskRepository(t *testing.T) {
+	db := testDB(t)
+	logger := zerolog.Nop()
+
+	projects := NewProjectRepository(db, &logger)
+	repotest.Run(t, repotest.Backend{
+		NewRepo: func(t *testing.T) service.Repo {
+			truncate(t, db)
+			return NewTaskRepository(db, &logger)
+		},
+		History: func(t *testing.T) service.HistoryRepo {
+			return NewHistoryRepository(db, &logger)
+		},
+		NewProject: func(t *testing.T) string {
+			project, err := projects.Create(context.Background(), models.Project{Name: "Contract", OwnerID: uuid.NewString(), Created: time.Now().UTC()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return project.ID
+		},
+		DeleteProject: func(t *testing.T, id string) {
+			if _, err := projects.Delete(context.Background(), id); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Rename: func(t *testing.T, id string, title string) func() {
+			ctx := context.Background()
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// an open transaction would block truncating the tasks of the next case
+			t.Cleanup(func() { tx.Rollback() })
+
+			_, err = tx.NewUpdate().Model((*Task)(nil)).Set("title = ?", title).Where("id = ?", id).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return func() {
+				if err := tx.Commit(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		},
+	})
+}
+
+// TestDeleteProjectEvents checks that the tasks detached from a deleted
+// project are published.
+func TestDeleteProjectEvents(t *testing.T) {
+	db := testDB(t)
+	truncate(t, db)
+	logger := zerolog.Nop()
+	ctx := context.Background()
+	projects := NewProjectRepository(db, &logger)
+	tasks := NewTaskRepository(db, &logger)
+	outbox := NewOutboxRepository(db, &logger)
+
+	project, err := projects.Create(ctx, models.Project{Name: "Doomed", OwnerID: uuid.NewString(), Created: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := tasks.Create(ctx, models.Task{
+		Title:        "Detached",
+		Status:       "todo",
+		OwnerID:      uuid.NewString(),
+		ProjectID:    project.ID,
+		CustomFields: map[string]any{"severity": "high"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := outbox.Relay(ctx, 10, func(ctx context.Context, event models.TaskEvent) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := projects.Delete(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != task.ID {
+		t.Errorf("Delete detached %v, want [%s]", ids, task.ID)
+	}
+
+	var events []models.TaskEvent
+	_, err = outbox.Relay(ctx, 10, func(ctx context.Context, event models.TaskEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != models.EventUpdated || events[0].Task.ID != task.ID {
+		t.Fatalf("events = %+v, want the update of task %s", events, task.ID)
+	}
+	if got := events[0].Task; got.ProjectID != "" || len(got.CustomFields) != 0 {
+		t.Errorf("event task in project %q with fields %v, want neither", got.ProjectID, got.CustomFields)
+	}
+}
+
+// TestChangesOfCascadeUpdates checks that the tasks updated by foreign key
+// actions, which run within a trigger, get a new change_seq.
+func TestChangesOfCascadeUpdates(t *testing.T) {
+	db := testDB(t)
+	truncate(t, db)
+	logger := zerolog.Nop()
+	ctx := context.Background()
+	projects := NewProjectRepository(db, &logger)
+	tasks := NewTaskRepository(db, &logger)
+
+	project, err := projects.Create(ctx, models.Project{Name: "Cascade", OwnerID: uuid.NewString(), Created: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inProject, err := tasks.Create(ctx, models.Task{Title: "In project", Status: "todo", OwnerID: uuid.NewString(), ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := tasks.Create(ctx, models.Task{Title: "Parent", Status: "todo", OwnerID: uuid.NewString()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := tasks.Create(ctx, models.Task{Title: "Child", Status: "todo", OwnerID: uuid.NewString(), ParentID: parent.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tasks.Delete(ctx, parent.ID); err != nil {
+		t.Fatal(err)
+	}
+	start, err := tasks.Changes(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	since, err := models.ParseSyncToken(start.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// past the repository, so only the foreign keys change the tasks
+	if _, err := db.NewDelete().Model((*Project)(nil)).Where("id = ?", project.ID).Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tasks.Purge(ctx, parent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := tasks.Changes(ctx, since, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, task := range res.Tasks {
+		ids = append(ids, task.ID)
+	}
+	slices.Sort(ids)
+	want := []string{inProject.ID, child.ID}
+	slices.Sort(want)
+	if !slices.Equal(ids, want) {
+		t.Errorf("Changes = %v, want the detached task and the orphaned child %v", ids, want)
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Template struct {
+	bun.BaseModel `bun:"table:task_templates,alias:tpl"`
+
+	ID        string              `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	OwnerID   string              `bun:"owner_id,notnull,type:uuid"`
+	Name      string              `bun:"name,notnull"`
+	Root      models.TemplateTask `bun:"root,type:jsonb,notnull"`
+	CreatedAt time.Time           `bun:"created_at,notnull,default:current_timestamp"`
+	UpdatedAt time.Time           `bun:"updated_at,nullzero,default:current_timestamp"`
+}
+
+type TemplateRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewTemplateRepository(conn *bun.DB, logger *zerolog.Logger) *TemplateRepository {
+	return &TemplateRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsTemplate(template Template) models.Template {
+	return models.Template{
+		ID:      template.ID,
+		OwnerID: template.OwnerID,
+		Name:    template.Name,
+		Root:    template.Root,
+		Created: template.CreatedAt,
+		Updated: template.UpdatedAt,
+	}
+}
+
+func repoTemplate(template models.Template) Template {
+	return Template{
+		ID:        template.ID,
+		OwnerID:   template.OwnerID,
+		Name:      template.Name,
+		Root:      template.Root,
+		CreatedAt: template.Created,
+		UpdatedAt: template.Updated,
+	}
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, template models.Template) (models.Template, error) {
+	repoTemplate := repoTemplate(template)
+	_, err := r.conn.NewInsert().Model(&repoTemplate).ExcludeColumn("id").Returning("*").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", template)
+		return models.Template{}, err
+	}
+
+	return modelsTemplate(repoTemplate), nil
+}
+
+func (r *TemplateRepository) Get(ctx context.Context, id string) (models.Template, error) {
+	var repoTemplate Template
+	err := r.conn.NewSelect().Model(&repoTemplate).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't receiving template: %s", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Template{}, models.ErrTemplateNotFound
+		}
+		return models.Template{}, err
+	}
+
+	return modelsTemplate(repoTemplate), nil
+}
+
+func (r *TemplateRepository) List(ctx context.Context) ([]models.Template, error) {
+	var templates []Template
+	err := r.conn.NewSelect().Model(&templates).Order("name", "id").Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msg("failed to list templates")
+		return nil, err
+	}
+
+	res := make([]models.Template, 0, len(templates))
+	for _, val := range templates {
+		res = append(res, modelsTemplate(val))
+	}
+	return res, nil
+}
+
+func (r *TemplateRepository) Update(ctx context.Context, template models.Template) (models.Template, error) {
+	repoTemplate := repoTemplate(template)
+	res, err := r.conn.NewUpdate().
+		Model(&repoTemplate).
+		Column("name", "root", "updated_at").
+		Where("id = ?", repoTemplate.ID).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't updating: %v", template)
+		return models.Template{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't update: %v", template)
+		return models.Template{}, err
+	}
+	if affected != 1 {
+		return models.Template{}, models.ErrTemplateNotFound
+	}
+
+	return modelsTemplate(repoTemplate), nil
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.conn.NewDelete().Model((*Template)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete template: %s", id)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete template: %s", id)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrTemplateNotFound
+	}
+
+	return nil
+}
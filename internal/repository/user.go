@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+type User struct {
+	bun.BaseModel `bun:"table:users"`
+
+	ID       string `bun:"id,pk,type:uuid"`
+	Username string `bun:"username,notnull"`
+}
+
+type UserRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewUserRepository(conn *bun.DB, logger *zerolog.Logger) *UserRepository {
+	return &UserRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+// SetUsername stores the username of the user, replacing the previous one.
+func (r *UserRepository) SetUsername(ctx context.Context, user models.User) (models.User, error) {
+	repoUser := User{ID: user.ID, Username: user.Username}
+	_, err := r.conn.NewInsert().
+		Model(&repoUser).
+		On("CONFLICT (id) DO UPDATE").
+		Set("username = EXCLUDED.username").
+		Exec(ctx)
+	if err != nil {
+		var pgErr pgdriver.Error
+		if errors.As(err, &pgErr) && pgErr.IntegrityViolation() {
+			return models.User{}, models.ErrUsernameTaken
+		}
+		r.log.Error().Err(err).Msgf("can't set username: %v", user)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// FindByUsernames returns the users having the usernames, unknown ones are skipped.
+func (r *UserRepository) FindByUsernames(ctx context.Context, usernames []string) ([]models.User, error) {
+	var users []User
+	err := r.conn.NewSelect().
+		Model(&users).
+		Where("username IN (?)", bun.In(usernames)).
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to find users: %v", usernames)
+		return nil, err
+	}
+
+	res := make([]models.User, 0, len(users))
+	for _, val := range users {
+		res = append(res, models.User{ID: val.ID, Username: val.Username})
+	}
+	return res, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Watcher struct {
+	bun.BaseModel `bun:"table:task_watchers"`
+
+	TaskID    string    `bun:"task_id,pk,type:uuid"`
+	UserID    string    `bun:"user_id,pk,type:uuid"`
+	CreatedAt time.Time `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+type WatcherRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewWatcherRepository(conn *bun.DB, logger *zerolog.Logger) *WatcherRepository {
+	return &WatcherRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func (r *WatcherRepository) List(ctx context.Context, taskID string) ([]string, error) {
+	watchers := []string{}
+	err := r.conn.NewSelect().
+		Model((*Watcher)(nil)).
+		Column("user_id").
+		Where("task_id = ?", taskID).
+		Order("created_at", "user_id").
+		Scan(ctx, &watchers)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list watchers of task: %s", taskID)
+		return nil, err
+	}
+
+	return watchers, nil
+}
+
+// Add makes the users watch the task, users already watching it are skipped.
+// The current watchers of the task are returned.
+func (r *WatcherRepository) Add(ctx context.Context, taskID string, userIDs []string, created time.Time) ([]string, error) {
+	var watchers []string
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if err := lockTask(ctx, tx, taskID); err != nil {
+			return err
+		}
+
+		rows := make([]Watcher, len(userIDs))
+		for i, userID := range userIDs {
+			rows[i] = Watcher{TaskID: taskID, UserID: userID, CreatedAt: created}
+		}
+		_, err := tx.NewInsert().Model(&rows).On("CONFLICT (task_id, user_id) DO NOTHING").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		watchers, err = refreshWatchers(ctx, tx, taskID)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't add watchers of task: %s", taskID)
+		return nil, err
+	}
+
+	return watchers, nil
+}
+
+// Remove stops the user watching the task and returns the remaining watchers.
+func (r *WatcherRepository) Remove(ctx context.Context, taskID string, userID string) ([]string, error) {
+	var watchers []string
+	err := r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if err := lockTask(ctx, tx, taskID); err != nil {
+			return err
+		}
+
+		_, err := tx.NewDelete().
+			Model((*Watcher)(nil)).
+			Where("task_id = ?", taskID).
+			Where("user_id = ?", userID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		watchers, err = refreshWatchers(ctx, tx, taskID)
+		return err
+	})
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't remove watcher of task: %s", taskID)
+		return nil, err
+	}
+
+	return watchers, nil
+}
+
+// lockTask serializes changes of the task watchers.
+func lockTask(ctx context.Context, tx bun.Tx, taskID string) error {
+	err := tx.NewSelect().Model((*Task)(nil)).Column("id").Where("id = ?", taskID).For("UPDATE").Scan(ctx, new(string))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrTaskNotFound
+	}
+	return err
+}
+
+// refreshWatchers copies the watchers into the task, writes the event of the
+// changed task and returns the watchers in the order they started watching.
+func refreshWatchers(ctx context.Context, tx bun.Tx, taskID string) ([]string, error) {
+	var task Task
+	_, err := tx.NewUpdate().
+		Model(&task).
+		Set("watchers = array(SELECT user_id FROM task_watchers WHERE task_id = ? ORDER BY created_at, user_id)", taskID).
+		Where("id = ?", taskID).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addEvents(ctx, tx, models.EventUpdated, task); err != nil {
+		return nil, err
+	}
+	if task.Watchers == nil {
+		return []string{}, nil
+	}
+	return task.Watchers, nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks,alias:hook"`
+
+	ID         string            `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	OwnerID    string            `bun:"owner_id,notnull,type:uuid"`
+	URL        string            `bun:"url,notnull"`
+	Secret     string            `bun:"secret,notnull"`
+	EventTypes []string          `bun:"event_types,array"`
+	Filter     models.TaskFilter `bun:"filter,type:jsonb,notnull"`
+	CreatedAt  time.Time         `bun:"created_at,notnull,default:current_timestamp"`
+}
+
+type Delivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:dlv"`
+
+	ID            string          `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	WebhookID     string          `bun:"webhook_id,notnull,type:uuid"`
+	EventID       uint64          `bun:"event_id,notnull"`
+	EventType     string          `bun:"event_type,notnull"`
+	Payload       json.RawMessage `bun:"payload,type:jsonb,notnull"`
+	Status        string          `bun:"status,notnull"`
+	Attempts      int             `bun:"attempts,notnull"`
+	NextAttemptAt time.Time       `bun:"next_attempt_at,nullzero"`
+	ResponseCode  int             `bun:"response_code,nullzero"`
+	LastError     string          `bun:"last_error,nullzero"`
+	CreatedAt     time.Time       `bun:"created_at,notnull,default:current_timestamp"`
+	DeliveredAt   time.Time       `bun:"delivered_at,nullzero"`
+}
+
+type WebhookRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewWebhookRepository(conn *bun.DB, logger *zerolog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsWebhook(webhook Webhook) models.Webhook {
+	res := models.Webhook{
+		ID:      webhook.ID,
+		OwnerID: webhook.OwnerID,
+		URL:     webhook.URL,
+		Secret:  webhook.Secret,
+		Filter:  webhook.Filter,
+		Created: webhook.CreatedAt,
+	}
+	for _, eventType := range webhook.EventTypes {
+		res.EventTypes = append(res.EventTypes, models.EventType(eventType))
+	}
+	return res
+}
+
+func modelsDelivery(delivery Delivery) models.Delivery {
+	return models.Delivery{
+		ID:           delivery.ID,
+		WebhookID:    delivery.WebhookID,
+		EventID:      delivery.EventID,
+		EventType:    models.EventType(delivery.EventType),
+		Payload:      delivery.Payload,
+		Status:       models.DeliveryStatus(delivery.Status),
+		Attempts:     delivery.Attempts,
+		NextAttempt:  delivery.NextAttemptAt,
+		ResponseCode: delivery.ResponseCode,
+		LastError:    delivery.LastError,
+		Created:      delivery.CreatedAt,
+		Delivered:    delivery.DeliveredAt,
+	}
+}
+
+func repoDelivery(delivery models.Delivery) Delivery {
+	return Delivery{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		EventType:     string(delivery.EventType),
+		Payload:       delivery.Payload,
+		Status:        string(delivery.Status),
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttempt,
+		ResponseCode:  delivery.ResponseCode,
+		LastError:     delivery.LastError,
+		CreatedAt:     delivery.Created,
+		DeliveredAt:   delivery.Delivered,
+	}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	repoWebhook := Webhook{
+		OwnerID:   webhook.OwnerID,
+		URL:       webhook.URL,
+		Secret:    webhook.Secret,
+		Filter:    webhook.Filter,
+		CreatedAt: webhook.Created,
+	}
+	for _, eventType := range webhook.EventTypes {
+		repoWebhook.EventTypes = append(repoWebhook.EventTypes, string(eventType))
+	}
+
+	_, err := r.conn.NewInsert().Model(&repoWebhook).ExcludeColumn("id").Returning("*").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't create webhook: %s", webhook.URL)
+		return models.Webhook{}, err
+	}
+
+	return modelsWebhook(repoWebhook), nil
+}
+
+func (r *WebhookRepository) Get(ctx context.Context, id string) (models.Webhook, error) {
+	var webhook Webhook
+	err := r.conn.NewSelect().Model(&webhook).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Webhook{}, models.ErrWebhookNotFound
+		}
+		r.log.Error().Err(err).Msgf("failed to get webhook: %s", id)
+		return models.Webhook{}, err
+	}
+
+	return modelsWebhook(webhook), nil
+}
+
+// List returns the webhooks of the owner, all webhooks when ownerID is empty.
+func (r *WebhookRepository) List(ctx context.Context, ownerID string) ([]models.Webhook, error) {
+	var webhooks []Webhook
+	query := r.conn.NewSelect().Model(&webhooks).Order("created_at", "id")
+	if ownerID != "" {
+		query = query.Where("owner_id = ?", ownerID)
+	}
+	if err := query.Scan(ctx); err != nil {
+		r.log.Error().Err(err).Msgf("failed to list webhooks of: %s", ownerID)
+		return nil, err
+	}
+
+	res := make([]models.Webhook, 0, len(webhooks))
+	for _, val := range webhooks {
+		res = append(res, modelsWebhook(val))
+	}
+	return res, nil
+}
+
+// Delete removes the webhook together with its deliveries.
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.conn.NewDelete().Model((*Webhook)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete webhook: %s", id)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete webhook: %s", id)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) AddDeliveries(ctx context.Context, deliveries []models.Delivery) ([]models.Delivery, error) {
+	rows := make([]Delivery, len(deliveries))
+	for i, delivery := range deliveries {
+		rows[i] = repoDelivery(delivery)
+	}
+
+	_, err := r.conn.NewInsert().Model(&rows).ExcludeColumn("id").Returning("*").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't add %d webhook deliveries", len(deliveries))
+		return nil, err
+	}
+
+	res := make([]models.Delivery, 0, len(rows))
+	for _, val := range rows {
+		res = append(res, modelsDelivery(val))
+	}
+	return res, nil
+}
+
+// ClaimDue returns up to limit pending deliveries due at now and postpones
+// them until leased, so other workers don't attempt them meanwhile.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, leased time.Time, limit int) ([]models.Delivery, error) {
+	due := r.conn.NewSelect().
+		Model((*Delivery)(nil)).
+		Column("id").
+		Where("status = ?", models.DeliveryPending).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	var deliveries []Delivery
+	_, err := r.conn.NewUpdate().
+		Model((*Delivery)(nil)).
+		Set("next_attempt_at = ?", leased).
+		Where("id IN (?)", due).
+		Returning("*").
+		Exec(ctx, &deliveries)
+	if err != nil {
+		r.log.Error().Err(err).Msg("can't claim due webhook deliveries")
+		return nil, err
+	}
+
+	res := make([]models.Delivery, 0, len(deliveries))
+	for _, val := range deliveries {
+		res = append(res, modelsDelivery(val))
+	}
+	return res, nil
+}
+
+// SaveAttempt stores the outcome of a delivery attempt.
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, delivery models.Delivery) error {
+	repoDelivery := repoDelivery(delivery)
+	_, err := r.conn.NewUpdate().
+		Model(&repoDelivery).
+		Column("status", "attempts", "next_attempt_at", "response_code", "last_error", "delivered_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't save attempt of webhook delivery: %s", delivery.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (models.Delivery, error) {
+	var delivery Delivery
+	err := r.conn.NewSelect().Model(&delivery).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Delivery{}, models.ErrDeliveryNotFound
+		}
+		r.log.Error().Err(err).Msgf("failed to get webhook delivery: %s", id)
+		return models.Delivery{}, err
+	}
+
+	return modelsDelivery(delivery), nil
+}
+
+// ListDeliveries returns the last deliveries of the webhook, the newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]models.Delivery, error) {
+	var deliveries []Delivery
+	err := r.conn.NewSelect().
+		Model(&deliveries).
+		Where("webhook_id = ?", webhookID).
+		OrderExpr("created_at DESC, id").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list deliveries of webhook: %s", webhookID)
+		return nil, err
+	}
+
+	res := make([]models.Delivery, 0, len(deliveries))
+	for _, val := range deliveries {
+		res = append(res, modelsDelivery(val))
+	}
+	return res, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type WorkflowStatus struct {
+	bun.BaseModel `bun:"table:workflow_statuses"`
+
+	Name     string `bun:"name,pk"`
+	Title    string `bun:"title"`
+	Category string `bun:"category,notnull"`
+	Position int    `bun:"position,notnull"`
+}
+
+type WorkflowTransition struct {
+	bun.BaseModel `bun:"table:workflow_transitions"`
+
+	FromStatus string `bun:"from_status,pk"`
+	ToStatus   string `bun:"to_status,pk"`
+}
+
+type WorkflowRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewWorkflowRepository(conn *bun.DB, logger *zerolog.Logger) *WorkflowRepository {
+	return &WorkflowRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsStatus(status WorkflowStatus) models.WorkflowStatus {
+	return models.WorkflowStatus{
+		Name:     models.TaskStatus(status.Name),
+		Title:    status.Title,
+		Category: models.StatusCategory(status.Category),
+		Position: status.Position,
+	}
+}
+
+func repoStatus(status models.WorkflowStatus) WorkflowStatus {
+	return WorkflowStatus{
+		Name:     status.Name.String(),
+		Title:    status.Title,
+		Category: status.Category.String(),
+		Position: status.Position,
+	}
+}
+
+func (r *WorkflowRepository) ListStatuses(ctx context.Context) ([]models.WorkflowStatus, error) {
+	var statuses []WorkflowStatus
+	err := r.conn.NewSelect().Model(&statuses).Order("position", "name").Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msg("failed to list statuses")
+		return nil, err
+	}
+
+	res := make([]models.WorkflowStatus, 0, len(statuses))
+	for _, val := range statuses {
+		res = append(res, modelsStatus(val))
+	}
+	return res, nil
+}
+
+func (r *WorkflowRepository) CreateStatus(ctx context.Context, status models.WorkflowStatus) (models.WorkflowStatus, error) {
+	repoStatus := repoStatus(status)
+	res, err := r.conn.NewInsert().Model(&repoStatus).On("CONFLICT DO NOTHING").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", status)
+		return models.WorkflowStatus{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't create: %v", status)
+		return models.WorkflowStatus{}, err
+	}
+	if affected != 1 {
+		return models.WorkflowStatus{}, models.ErrStatusExists
+	}
+
+	return modelsStatus(repoStatus), nil
+}
+
+func (r *WorkflowRepository) DeleteStatus(ctx context.Context, name string) error {
+	return r.conn.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// trashed tasks can be restored, so they keep the status in use
+		used, err := tx.NewSelect().Model((*Task)(nil)).WhereAllWithDeleted().Where("status = ?", name).Exists(ctx)
+		if err != nil {
+			r.log.Error().Err(err).Msgf("can't check usage of status: %s", name)
+			return err
+		}
+		if used {
+			return models.ErrStatusInUse
+		}
+
+		res, err := tx.NewDelete().Model((*WorkflowStatus)(nil)).Where("name = ?", name).Exec(ctx)
+		if err != nil {
+			r.log.Error().Err(err).Msgf("can't delete status: %s", name)
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			r.log.Error().Err(err).Msgf("can't delete status: %s", name)
+			return err
+		}
+		if affected != 1 {
+			return models.ErrStatusNotFound
+		}
+
+		return nil
+	})
+}
+
+func (r *WorkflowRepository) ListTransitions(ctx context.Context) ([]models.Transition, error) {
+	var transitions []WorkflowTransition
+	err := r.conn.NewSelect().Model(&transitions).Order("from_status", "to_status").Scan(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msg("failed to list transitions")
+		return nil, err
+	}
+
+	res := make([]models.Transition, 0, len(transitions))
+	for _, val := range transitions {
+		res = append(res, models.Transition{
+			From: models.TaskStatus(val.FromStatus),
+			To:   models.TaskStatus(val.ToStatus),
+		})
+	}
+	return res, nil
+}
+
+func (r *WorkflowRepository) CreateTransition(ctx context.Context, transition models.Transition) error {
+	repoTransition := WorkflowTransition{
+		FromStatus: transition.From.String(),
+		ToStatus:   transition.To.String(),
+	}
+	_, err := r.conn.NewInsert().Model(&repoTransition).On("CONFLICT DO NOTHING").Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", transition)
+		return err
+	}
+	return nil
+}
+
+func (r *WorkflowRepository) DeleteTransition(ctx context.Context, transition models.Transition) error {
+	res, err := r.conn.NewDelete().
+		Model((*WorkflowTransition)(nil)).
+		Where("from_status = ?", transition.From).
+		Where("to_status = ?", transition.To).
+		Exec(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete: %v", transition)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't delete: %v", transition)
+		return err
+	}
+	if affected != 1 {
+		return models.ErrTransitionNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+	"github.com/uptrace/bun"
+)
+
+type WorkLog struct {
+	bun.BaseModel `bun:"table:work_logs,alias:wl"`
+
+	ID        string        `bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
+	TaskID    string        `bun:"task_id,notnull,type:uuid"`
+	UserID    string        `bun:"user_id,notnull,type:uuid"`
+	StartedAt time.Time     `bun:"started_at,notnull"`
+	Duration  sql.NullInt64 `bun:"duration"`
+	Note      string        `bun:"note"`
+}
+
+type workReportRow struct {
+	Day       time.Time `bun:"day"`
+	ProjectID string    `bun:"project_id"`
+	Duration  int64     `bun:"duration"`
+}
+
+type WorkLogRepository struct {
+	conn *bun.DB
+	log  *zerolog.Logger
+}
+
+func NewWorkLogRepository(conn *bun.DB, logger *zerolog.Logger) *WorkLogRepository {
+	return &WorkLogRepository{
+		conn: conn,
+		log:  logger,
+	}
+}
+
+func modelsWorkLog(log WorkLog) models.WorkLog {
+	return models.WorkLog{
+		ID:       log.ID,
+		TaskID:   log.TaskID,
+		UserID:   log.UserID,
+		Started:  log.StartedAt,
+		Duration: log.Duration.Int64,
+		Note:     log.Note,
+		Running:  !log.Duration.Valid,
+	}
+}
+
+func repoWorkLog(log models.WorkLog) WorkLog {
+	return WorkLog{
+		ID:        log.ID,
+		TaskID:    log.TaskID,
+		UserID:    log.UserID,
+		StartedAt: log.Started,
+		Duration:  sql.NullInt64{Int64: log.Duration, Valid: !log.Running},
+		Note:      log.Note,
+	}
+}
+
+// Create stores a log. A running log is rejected with ErrTimerRunning when
+// the user already has one.
+func (r *WorkLogRepository) Create(ctx context.Context, log models.WorkLog) (models.WorkLog, error) {
+	repoLog := repoWorkLog(log)
+	res, err := r.conn.NewInsert().
+		Model(&repoLog).
+		ExcludeColumn("id").
+		On("CONFLICT (user_id) WHERE duration IS NULL DO NOTHING").
+		Returning("*").
+		Exec(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WorkLog{}, models.ErrTimerRunning
+	}
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't creating: %v", log)
+		return models.WorkLog{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't create: %v", log)
+		return models.WorkLog{}, err
+	}
+	if affected != 1 {
+		return models.WorkLog{}, models.ErrTimerRunning
+	}
+
+	return modelsWorkLog(repoLog), nil
+}
+
+func (r *WorkLogRepository) Running(ctx context.Context, userID string) (models.WorkLog, error) {
+	var repoLog WorkLog
+	err := r.conn.NewSelect().
+		Model(&repoLog).
+		Where("user_id = ? AND duration IS NULL", userID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WorkLog{}, models.ErrTimerNotFound
+		}
+		r.log.Error().Err(err).Msgf("can't receiving running timer of user: %s", userID)
+		return models.WorkLog{}, err
+	}
+
+	return modelsWorkLog(repoLog), nil
+}
+
+// Stop finishes the running log of the user at the given time.
+func (r *WorkLogRepository) Stop(ctx context.Context, userID string, stopped time.Time) (models.WorkLog, error) {
+	var repoLog WorkLog
+	res, err := r.conn.NewUpdate().
+		Model(&repoLog).
+		Set("duration = greatest(extract(epoch from (?::timestamp - started_at))::bigint, 0)", stopped).
+		Where("user_id = ? AND duration IS NULL", userID).
+		Returning("*").
+		Exec(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WorkLog{}, models.ErrTimerNotFound
+	}
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't stop timer of user: %s", userID)
+		return models.WorkLog{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		r.log.Error().Err(err).Msgf("can't stop timer of user: %s", userID)
+		return models.WorkLog{}, err
+	}
+	if affected != 1 {
+		return models.WorkLog{}, models.ErrTimerNotFound
+	}
+
+	return modelsWorkLog(repoLog), nil
+}
+
+func (r *WorkLogRepository) List(ctx context.Context, taskID string, page models.Page) (models.WorkLogList, error) {
+	var logs []WorkLog
+	total, err := r.conn.NewSelect().
+		Model(&logs).
+		Where("task_id = ?", taskID).
+		Order("started_at", "id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		ScanAndCount(ctx)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to list work logs of task: %s", taskID)
+		return models.WorkLogList{}, err
+	}
+
+	res := models.WorkLogList{
+		WorkLogs: make([]models.WorkLog, 0, len(logs)),
+		Total:    total,
+	}
+	for _, val := range logs {
+		res.WorkLogs = append(res.WorkLogs, modelsWorkLog(val))
+	}
+	return res, nil
+}
+
+// Total sums the duration of the finished logs matching the filter.
+func (r *WorkLogRepository) Total(ctx context.Context, filter models.WorkLogFilter) (int64, error) {
+	var total int64
+	err := r.filtered(filter).
+		ColumnExpr("coalesce(sum(wl.duration), 0)").
+		Scan(ctx, &total)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to sum work logs: %v", filter)
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Report sums the duration of the finished logs by the requested groups.
+func (r *WorkLogRepository) Report(ctx context.Context, filter models.WorkReportFilter) ([]models.WorkReportRow, error) {
+	query := r.filtered(filter.WorkLogFilter).
+		ColumnExpr("sum(wl.duration) AS duration")
+
+	for _, group := range filter.GroupBy {
+		switch group {
+		case models.GroupByDay:
+			query = query.
+				ColumnExpr("date_trunc('day', wl.started_at) AS day").
+				GroupExpr("day").
+				OrderExpr("day")
+		case models.GroupByProject:
+			query = query.
+				ColumnExpr("t.project_id").
+				GroupExpr("t.project_id").
+				OrderExpr("t.project_id NULLS FIRST")
+		}
+	}
+
+	var rows []workReportRow
+	err := query.Scan(ctx, &rows)
+	if err != nil {
+		r.log.Error().Err(err).Msgf("failed to build work report: %v", filter)
+		return nil, err
+	}
+
+	res := make([]models.WorkReportRow, 0, len(rows))
+	for _, val := range rows {
+		res = append(res, models.WorkReportRow{
+			Day:       val.Day,
+			ProjectID: val.ProjectID,
+			Duration:  val.Duration,
+		})
+	}
+	return res, nil
+}
+
+func (r *WorkLogRepository) filtered(filter models.WorkLogFilter) *bun.SelectQuery {
+	query := r.conn.NewSelect().
+		TableExpr("work_logs AS wl").
+		Join("JOIN tasks AS t ON t.id = wl.task_id").
+		Where("wl.duration IS NOT NULL")
+
+	if filter.TaskID != "" {
+		query = query.Where("wl.task_id = ?", filter.TaskID)
+	}
+	if filter.UserID != "" {
+		query = query.Where("wl.user_id = ?", filter.UserID)
+	}
+	if filter.OwnerID != "" {
+		query = query.Where("t.owner_id = ?", filter.OwnerID)
+	}
+	if filter.ProjectID != "" {
+		query = query.Where("t.project_id = ?", filter.ProjectID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("wl.started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		// To is an inclusive date
+		query = query.Where("wl.started_at < ?", filter.To.AddDate(0, 0, 1))
+	}
+	return query
+}
@@ -0,0 +1,282 @@
+package rrule
+
+import (
+	"sort"
+	"time"
+)
+
+// maxEmptyPeriods stops iteration of rules which can never match,
+// e.g. FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30.
+const maxEmptyPeriods = 3000
+
+// Iterator yields occurrences of a rule in chronological order.
+type Iterator struct {
+	rule    Rule
+	dtstart time.Time
+	period  time.Time
+	pending []time.Time
+	emitted int
+	done    bool
+}
+
+// Iterator returns an iterator over the occurrences starting at dtstart.
+// As in RFC 5545 dtstart is the first occurrence when it matches the rule.
+func (r Rule) Iterator(dtstart time.Time) *Iterator {
+	return &Iterator{
+		rule:    r,
+		dtstart: dtstart,
+		period:  periodStart(r, dtstart),
+	}
+}
+
+// Next returns the next occurrence, false when the rule is exhausted.
+func (it *Iterator) Next() (time.Time, bool) {
+	for empty := 0; len(it.pending) == 0; empty++ {
+		if it.done || empty > maxEmptyPeriods {
+			it.done = true
+			return time.Time{}, false
+		}
+		it.pending = it.expand(it.period)
+		it.period = nextPeriod(it.rule, it.period)
+	}
+
+	next := it.pending[0]
+	it.pending = it.pending[1:]
+
+	if !it.rule.Until.IsZero() && next.After(it.rule.Until) {
+		it.done = true
+		it.pending = nil
+		return time.Time{}, false
+	}
+
+	it.emitted++
+	if it.rule.Count > 0 && it.emitted >= it.rule.Count {
+		it.done = true
+		it.pending = nil
+	}
+	return next, true
+}
+
+// Take returns up to n next occurrences.
+func (it *Iterator) Take(n int) []time.Time {
+	res := make([]time.Time, 0, n)
+	for len(res) < n {
+		next, ok := it.Next()
+		if !ok {
+			break
+		}
+		res = append(res, next)
+	}
+	return res
+}
+
+// After returns the first occurrence of the rule started at dtstart
+// which is strictly after t.
+func (r Rule) After(dtstart, t time.Time) (time.Time, bool) {
+	it := r.Iterator(dtstart)
+	for {
+		next, ok := it.Next()
+		if !ok || next.After(t) {
+			return next, ok
+		}
+	}
+}
+
+// expand returns the occurrences within the period which are not before dtstart.
+func (it *Iterator) expand(period time.Time) []time.Time {
+	days := candidates(it.rule, it.dtstart, period)
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	days = setPos(days, it.rule.BySetPos)
+
+	res := days[:0]
+	for _, day := range days {
+		occurrence := time.Date(day.Year(), day.Month(), day.Day(),
+			it.dtstart.Hour(), it.dtstart.Minute(), it.dtstart.Second(), it.dtstart.Nanosecond(),
+			it.dtstart.Location())
+		if !occurrence.Before(it.dtstart) {
+			res = append(res, occurrence)
+		}
+	}
+	return res
+}
+
+// periodStart aligns dtstart to the beginning of its period.
+func periodStart(r Rule, dtstart time.Time) time.Time {
+	day := date(dtstart.Year(), dtstart.Month(), dtstart.Day(), dtstart.Location())
+	switch r.Freq {
+	case Weekly:
+		offset := (int(day.Weekday()) - int(r.WeekStart) + 7) % 7
+		return day.AddDate(0, 0, -offset)
+	case Monthly:
+		return date(day.Year(), day.Month(), 1, day.Location())
+	case Yearly:
+		return date(day.Year(), time.January, 1, day.Location())
+	default:
+		return day
+	}
+}
+
+func nextPeriod(r Rule, period time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return period.AddDate(0, 0, 7*r.Interval)
+	case Monthly:
+		return period.AddDate(0, r.Interval, 0)
+	case Yearly:
+		return period.AddDate(r.Interval, 0, 0)
+	default:
+		return period.AddDate(0, 0, r.Interval)
+	}
+}
+
+// candidates returns the days of the period matching the BYxxx parts
+// following the expand/limit table of RFC 5545 section 3.3.10.
+func candidates(r Rule, dtstart, period time.Time) []time.Time {
+	loc := period.Location()
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		days = []time.Time{period}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := period.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			days = append(days, day)
+		}
+	case Monthly:
+		days = monthDays(period.Year(), period.Month(), loc)
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			days = filter(days, func(day time.Time) bool { return day.Day() == dtstart.Day() })
+		}
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+				months = []time.Month{dtstart.Month()}
+			} else {
+				for month := time.January; month <= time.December; month++ {
+					months = append(months, month)
+				}
+			}
+		}
+		for _, month := range months {
+			days = append(days, monthDays(period.Year(), month, loc)...)
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			days = filter(days, func(day time.Time) bool { return day.Day() == dtstart.Day() })
+		}
+	}
+
+	if len(r.ByMonth) > 0 {
+		days = filter(days, func(day time.Time) bool { return containsMonth(r.ByMonth, day.Month()) })
+	}
+	if len(r.ByMonthDay) > 0 {
+		days = filter(days, func(day time.Time) bool { return matchesMonthDay(r.ByMonthDay, day) })
+	}
+	if len(r.ByDay) > 0 {
+		// ordinals are relative to the year only for YEARLY rules without BYMONTH
+		yearScope := r.Freq == Yearly && len(r.ByMonth) == 0
+		days = filter(days, func(day time.Time) bool { return matchesByDay(r.ByDay, day, yearScope) })
+	}
+	return days
+}
+
+func date(year int, month time.Month, day int, loc *time.Location) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+func monthDays(year int, month time.Month, loc *time.Location) []time.Time {
+	n := daysIn(year, month)
+	days := make([]time.Time, n)
+	for i := range days {
+		days[i] = date(year, month, i+1, loc)
+	}
+	return days
+}
+
+func daysIn(year int, month time.Month) int {
+	return date(year, month+1, 0, time.UTC).Day()
+}
+
+func filter(days []time.Time, keep func(time.Time) bool) []time.Time {
+	res := days[:0]
+	for _, day := range days {
+		if keep(day) {
+			res = append(res, day)
+		}
+	}
+	return res
+}
+
+func containsMonth(months []time.Month, month time.Month) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(monthDays []int, day time.Time) bool {
+	n := daysIn(day.Year(), day.Month())
+	for _, monthDay := range monthDays {
+		if monthDay == day.Day() || (monthDay < 0 && n+monthDay+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesByDay(byDay []Weekday, day time.Time, yearScope bool) bool {
+	for _, weekday := range byDay {
+		if weekday.Day != day.Weekday() {
+			continue
+		}
+		if weekday.N == 0 {
+			return true
+		}
+
+		var index, total int
+		if yearScope {
+			index = (day.YearDay() - 1) / 7
+			total = (yearLength(day.Year()) - day.YearDay()) / 7
+		} else {
+			index = (day.Day() - 1) / 7
+			total = (daysIn(day.Year(), day.Month()) - day.Day()) / 7
+		}
+		// index counts the same weekdays before the day, total the ones after it
+		if weekday.N > 0 && weekday.N == index+1 {
+			return true
+		}
+		if weekday.N < 0 && -weekday.N == total+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func yearLength(year int) int {
+	return date(year, time.December, 31, time.UTC).YearDay()
+}
+
+// setPos picks the occurrences of a period by their 1-based position,
+// negative positions count from the end.
+func setPos(days []time.Time, positions []int) []time.Time {
+	if len(positions) == 0 {
+		return days
+	}
+
+	var res []time.Time
+	for i, day := range days {
+		for _, pos := range positions {
+			if pos == i+1 || pos == i-len(days) {
+				res = append(res, day)
+				break
+			}
+		}
+	}
+	return res
+}
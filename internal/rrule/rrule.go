@@ -0,0 +1,306 @@
+// Package rrule parses RFC 5545 recurrence rules and iterates over their occurrences.
+//
+// Supported rule parts are FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL,
+// COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS and WKST. Time of day
+// and location of the occurrences are taken from DTSTART.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRule     = errors.New("invalid recurrence rule")
+	ErrUnsupportedPart = errors.New("unsupported recurrence rule part")
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencies = map[string]Frequency{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+	"YEARLY":  Yearly,
+}
+
+func (f Frequency) String() string {
+	for name, freq := range frequencies {
+		if freq == f {
+			return name
+		}
+	}
+	return ""
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+func weekdayName(day time.Weekday) string {
+	return strings.ToUpper(day.String()[:2])
+}
+
+// Weekday is a BYDAY value. N is the optional ordinal: 1MO is the first
+// Monday, -1FR the last Friday of the month or year. Zero means every such day.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+func (w Weekday) String() string {
+	if w.N == 0 {
+		return weekdayName(w.Day)
+	}
+	return strconv.Itoa(w.N) + weekdayName(w.Day)
+}
+
+// unsupported lists valid RFC 5545 parts which this package doesn't handle.
+var unsupported = map[string]bool{
+	"BYSECOND":  true,
+	"BYMINUTE":  true,
+	"BYHOUR":    true,
+	"BYYEARDAY": true,
+	"BYWEEKNO":  true,
+}
+
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// Count limits the number of occurrences, zero means no limit.
+	Count int
+	// Until is the last possible occurrence, zero means no limit.
+	Until      time.Time
+	ByDay      []Weekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// Parse parses a rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE".
+// An optional "RRULE:" prefix is accepted.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := Rule{
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return Rule{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return Rule{}, fmt.Errorf("%w: duplicated part %s", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			freq, ok := frequencies[value]
+			if !ok {
+				if value == "HOURLY" || value == "MINUTELY" || value == "SECONDLY" {
+					return Rule{}, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedPart, value)
+				}
+				return Rule{}, fmt.Errorf("%w: unknown frequency %s", ErrInvalidRule, value)
+			}
+			rule.Freq = freq
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(value)
+		case "COUNT":
+			rule.Count, err = parsePositive(value)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseInts(value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(value, 1, 12)
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			rule.BySetPos, err = parseInts(value, -366, 366)
+		case "WKST":
+			day, ok := weekdays[value]
+			if !ok {
+				return Rule{}, fmt.Errorf("%w: unknown week start %s", ErrInvalidRule, value)
+			}
+			rule.WeekStart = day
+		default:
+			if unsupported[name] {
+				return Rule{}, fmt.Errorf("%w: %s", ErrUnsupportedPart, name)
+			}
+			return Rule{}, fmt.Errorf("%w: unknown part %s", ErrInvalidRule, name)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %s: %v", ErrInvalidRule, name, err)
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return Rule{}, err
+	}
+	return rule, nil
+}
+
+func (r Rule) validate() error {
+	if r.Freq == 0 {
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return fmt.Errorf("%w: COUNT and UNTIL can't be used together", ErrInvalidRule)
+	}
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return fmt.Errorf("%w: BYDAY ordinals are allowed only with MONTHLY or YEARLY", ErrInvalidRule)
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return fmt.Errorf("%w: BYMONTHDAY can't be used with WEEKLY", ErrInvalidRule)
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return fmt.Errorf("%w: BYSETPOS requires another BYxxx part", ErrInvalidRule)
+	}
+	return nil
+}
+
+func parsePositive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		return 0, errors.New("must be positive")
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		if layout == "20060102" {
+			// a date only UNTIL includes the whole day
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %s", value)
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	var res []Weekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		weekday := Weekday{Day: day}
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", item)
+			}
+			weekday.N = n
+		}
+		res = append(res, weekday)
+	}
+	return res, nil
+}
+
+func parseInts(value string, min, max int) ([]int, error) {
+	var res []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("%d is out of range", n)
+		}
+		res = append(res, n)
+	}
+	return res, nil
+}
+
+// String formats the rule back into its RFC 5545 representation.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayName(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(values []int) string {
+	res := make([]string, len(values))
+	for i, value := range values {
+		res[i] = strconv.Itoa(value)
+	}
+	return strings.Join(res, ",")
+}
@@ -0,0 +1,365 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const layout = "2006-01-02 15:04"
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	res, err := time.Parse(layout, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func format(times []time.Time) []string {
+	res := make([]string, len(times))
+	for i, t := range times {
+		res[i] = t.Format(layout)
+	}
+	return res
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart string
+		take    int
+		want    []string
+	}{
+		{
+			name:    "daily with count",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: "1997-09-02 09:00",
+			take:    10,
+			want:    []string{"1997-09-02 09:00", "1997-09-03 09:00", "1997-09-04 09:00"},
+		},
+		{
+			name:    "every other day",
+			rule:    "FREQ=DAILY;INTERVAL=2",
+			dtstart: "1997-09-02 09:00",
+			take:    4,
+			want:    []string{"1997-09-02 09:00", "1997-09-04 09:00", "1997-09-06 09:00", "1997-09-08 09:00"},
+		},
+		{
+			name:    "daily limited to a month",
+			rule:    "FREQ=DAILY;BYMONTH=1;COUNT=3",
+			dtstart: "1997-12-30 09:00",
+			take:    10,
+			want:    []string{"1998-01-01 09:00", "1998-01-02 09:00", "1998-01-03 09:00"},
+		},
+		{
+			name:    "weekly",
+			rule:    "FREQ=WEEKLY;COUNT=3",
+			dtstart: "1997-09-02 09:00",
+			take:    10,
+			want:    []string{"1997-09-02 09:00", "1997-09-09 09:00", "1997-09-16 09:00"},
+		},
+		{
+			name:    "every other week on tuesday and thursday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;WKST=SU;BYDAY=TU,TH;COUNT=8",
+			dtstart: "1997-09-02 09:00",
+			take:    10,
+			want: []string{
+				"1997-09-02 09:00", "1997-09-04 09:00", "1997-09-16 09:00", "1997-09-18 09:00",
+				"1997-09-30 09:00", "1997-10-02 09:00", "1997-10-14 09:00", "1997-10-16 09:00",
+			},
+		},
+		{
+			name:    "week start changes every other week",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+			dtstart: "1997-08-05 09:00",
+			take:    10,
+			want:    []string{"1997-08-05 09:00", "1997-08-17 09:00", "1997-08-19 09:00", "1997-08-31 09:00"},
+		},
+		{
+			name:    "first friday of the month",
+			rule:    "FREQ=MONTHLY;COUNT=4;BYDAY=1FR",
+			dtstart: "1997-09-05 09:00",
+			take:    10,
+			want:    []string{"1997-09-05 09:00", "1997-10-03 09:00", "1997-11-07 09:00", "1997-12-05 09:00"},
+		},
+		{
+			name:    "first and last sunday",
+			rule:    "FREQ=MONTHLY;INTERVAL=2;COUNT=4;BYDAY=1SU,-1SU",
+			dtstart: "1997-09-07 09:00",
+			take:    10,
+			want:    []string{"1997-09-07 09:00", "1997-09-28 09:00", "1997-11-02 09:00", "1997-11-30 09:00"},
+		},
+		{
+			name:    "third to the last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-3",
+			dtstart: "1997-09-28 09:00",
+			take:    6,
+			want: []string{
+				"1997-09-28 09:00", "1997-10-29 09:00", "1997-11-28 09:00",
+				"1997-12-29 09:00", "1998-01-29 09:00", "1998-02-26 09:00",
+			},
+		},
+		{
+			name:    "month days every 18 months",
+			rule:    "FREQ=MONTHLY;INTERVAL=18;COUNT=10;BYMONTHDAY=10,11,12,13,14,15",
+			dtstart: "1997-09-10 09:00",
+			take:    20,
+			want: []string{
+				"1997-09-10 09:00", "1997-09-11 09:00", "1997-09-12 09:00", "1997-09-13 09:00",
+				"1997-09-14 09:00", "1997-09-15 09:00", "1999-03-10 09:00", "1999-03-11 09:00",
+				"1999-03-12 09:00", "1999-03-13 09:00",
+			},
+		},
+		{
+			name:    "monthly skips months without the day",
+			rule:    "FREQ=MONTHLY;COUNT=5",
+			dtstart: "2024-01-31 10:00",
+			take:    10,
+			want: []string{
+				"2024-01-31 10:00", "2024-03-31 10:00", "2024-05-31 10:00",
+				"2024-07-31 10:00", "2024-08-31 10:00",
+			},
+		},
+		{
+			name:    "last work day of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: "1997-09-30 09:00",
+			take:    7,
+			want: []string{
+				"1997-09-30 09:00", "1997-10-31 09:00", "1997-11-28 09:00", "1997-12-31 09:00",
+				"1998-01-30 09:00", "1998-02-27 09:00", "1998-03-31 09:00",
+			},
+		},
+		{
+			name:    "third instance of tuesday, wednesday or thursday",
+			rule:    "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3",
+			dtstart: "1997-09-04 09:00",
+			take:    10,
+			want:    []string{"1997-09-04 09:00", "1997-10-07 09:00", "1997-11-06 09:00"},
+		},
+		{
+			name:    "friday the 13th",
+			rule:    "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			dtstart: "1997-09-02 09:00",
+			take:    5,
+			want: []string{
+				"1998-02-13 09:00", "1998-03-13 09:00", "1998-11-13 09:00",
+				"1999-08-13 09:00", "2000-10-13 09:00",
+			},
+		},
+		{
+			name:    "yearly in june and july",
+			rule:    "FREQ=YEARLY;COUNT=4;BYMONTH=6,7",
+			dtstart: "1997-06-10 09:00",
+			take:    10,
+			want:    []string{"1997-06-10 09:00", "1997-07-10 09:00", "1998-06-10 09:00", "1998-07-10 09:00"},
+		},
+		{
+			name:    "monday of week 20",
+			rule:    "FREQ=YEARLY;BYDAY=20MO",
+			dtstart: "1997-05-19 09:00",
+			take:    3,
+			want:    []string{"1997-05-19 09:00", "1998-05-18 09:00", "1999-05-17 09:00"},
+		},
+		{
+			name:    "last sunday of october",
+			rule:    "FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+			dtstart: "2020-01-01 03:00",
+			take:    3,
+			want:    []string{"2020-10-25 03:00", "2021-10-31 03:00", "2022-10-30 03:00"},
+		},
+		{
+			name:    "leap day",
+			rule:    "FREQ=YEARLY",
+			dtstart: "2024-02-29 12:00",
+			take:    3,
+			want:    []string{"2024-02-29 12:00", "2028-02-29 12:00", "2032-02-29 12:00"},
+		},
+		{
+			name:    "until is inclusive",
+			rule:    "FREQ=WEEKLY;UNTIL=19970916T090000Z",
+			dtstart: "1997-09-02 09:00",
+			take:    10,
+			want:    []string{"1997-09-02 09:00", "1997-09-09 09:00", "1997-09-16 09:00"},
+		},
+		{
+			name:    "date only until",
+			rule:    "FREQ=DAILY;UNTIL=19970904",
+			dtstart: "1997-09-02 09:00",
+			take:    10,
+			want:    []string{"1997-09-02 09:00", "1997-09-03 09:00", "1997-09-04 09:00"},
+		},
+		{
+			name:    "never matching rule",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			dtstart: "2024-01-01 09:00",
+			take:    3,
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.rule, err)
+			}
+
+			got := format(rule.Iterator(mustTime(t, tt.dtstart)).Take(tt.take))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIteratorKeepsLocalTime(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	rule, err := Parse("FREQ=DAILY;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// daylight saving time ends on 2024-10-27
+	dtstart := time.Date(2024, time.October, 26, 9, 30, 0, 0, loc)
+	for _, occurrence := range rule.Iterator(dtstart).Take(3) {
+		if occurrence.Hour() != 9 || occurrence.Minute() != 30 || occurrence.Location() != loc {
+			t.Errorf("occurrence %v isn't at 09:30 in %s", occurrence, loc)
+		}
+	}
+}
+
+func TestIteratorExhausted(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;COUNT=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := rule.Iterator(mustTime(t, "2024-01-01 09:00"))
+	if _, ok := it.Next(); !ok {
+		t.Fatal("expected the first occurrence")
+	}
+	for i := 0; i < 2; i++ {
+		if next, ok := it.Next(); ok {
+			t.Fatalf("expected no more occurrences, got %v", next)
+		}
+	}
+}
+
+func TestAfter(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO,FR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dtstart := mustTime(t, "2024-01-01 10:00")
+	tests := []struct {
+		after string
+		want  string
+	}{
+		{after: "2024-01-01 10:00", want: "2024-01-05 10:00"},
+		{after: "2024-01-01 09:59", want: "2024-01-01 10:00"},
+		{after: "2024-01-05 12:00", want: "2024-01-08 10:00"},
+	}
+
+	for _, tt := range tests {
+		got, ok := rule.After(dtstart, mustTime(t, tt.after))
+		if !ok {
+			t.Fatalf("After(%s) found nothing", tt.after)
+		}
+		if got.Format(layout) != tt.want {
+			t.Errorf("After(%s) = %s, want %s", tt.after, got.Format(layout), tt.want)
+		}
+	}
+
+	limited, err := Parse("FREQ=DAILY;COUNT=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, ok := limited.After(dtstart, mustTime(t, "2024-01-02 10:00")); ok {
+		t.Errorf("expected the series to be over, got %v", next)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want error
+	}{
+		{rule: "", want: ErrInvalidRule},
+		{rule: "INTERVAL=2", want: ErrInvalidRule},
+		{rule: "FREQ=FORTNIGHTLY", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;INTERVAL=0", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;COUNT=x", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20240101", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", want: ErrInvalidRule},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", want: ErrInvalidRule},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=1", want: ErrInvalidRule},
+		{rule: "FREQ=MONTHLY;BYDAY=XX", want: ErrInvalidRule},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", want: ErrInvalidRule},
+		{rule: "FREQ=YEARLY;BYMONTH=13", want: ErrInvalidRule},
+		{rule: "FREQ=MONTHLY;BYSETPOS=1", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;UNTIL=tomorrow", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;COLOR=RED", want: ErrInvalidRule},
+		{rule: "FREQ=DAILY;", want: ErrInvalidRule},
+		{rule: "FREQ=HOURLY", want: ErrUnsupportedPart},
+		{rule: "FREQ=YEARLY;BYWEEKNO=20", want: ErrUnsupportedPart},
+		{rule: "FREQ=DAILY;BYHOUR=9", want: ErrUnsupportedPart},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.rule); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("RRULE:freq=monthly;interval=2;byday=-1fr,2MO;bysetpos=1;wkst=su;until=20241231T235959Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rule.Freq != Monthly || rule.Interval != 2 || rule.WeekStart != time.Sunday {
+		t.Errorf("unexpected rule %+v", rule)
+	}
+	wantDays := []Weekday{{Day: time.Friday, N: -1}, {Day: time.Monday, N: 2}}
+	if len(rule.ByDay) != len(wantDays) || rule.ByDay[0] != wantDays[0] || rule.ByDay[1] != wantDays[1] {
+		t.Errorf("ByDay = %v, want %v", rule.ByDay, wantDays)
+	}
+	if want := time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC); !rule.Until.Equal(want) {
+		t.Errorf("Until = %v, want %v", rule.Until, want)
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	rules := []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;COUNT=8;BYDAY=TU,TH;WKST=SU",
+		"FREQ=MONTHLY;BYMONTHDAY=-3,1",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"FREQ=YEARLY;UNTIL=20301231T000000Z;BYMONTH=10;BYDAY=-1SU",
+	}
+
+	for _, s := range rules {
+		rule, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", s, err)
+		}
+		if got := rule.String(); got != s {
+			t.Errorf("String() = %q, want %q", got, s)
+		}
+	}
+}
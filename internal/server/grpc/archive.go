@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"github.com/google/uuid"
+)
+
+func (h *TaskHandler) ArchiveTask(ctx context.Context, req *pb.ArchiveTaskRequest) (*pb.ArchiveTaskResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.ArchiveTaskResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	task, err := h.service.Archive(ctx, id)
+	if err != nil {
+		return &pb.ArchiveTaskResponse{}, statusError(err, "failed to archive task")
+	}
+
+	return &pb.ArchiveTaskResponse{Task: pbTask(task)}, nil
+}
+
+func (h *TaskHandler) UnarchiveTask(ctx context.Context, req *pb.UnarchiveTaskRequest) (*pb.UnarchiveTaskResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.UnarchiveTaskResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	task, err := h.service.Unarchive(ctx, id)
+	if err != nil {
+		return &pb.UnarchiveTaskResponse{}, statusError(err, "failed to unarchive task")
+	}
+
+	return &pb.UnarchiveTaskResponse{Task: pbTask(task)}, nil
+}
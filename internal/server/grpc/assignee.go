@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"github.com/google/uuid"
+)
+
+func (h *TaskHandler) AssignTask(ctx context.Context, req *pb.AssignTaskRequest) (*pb.AssignTaskResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.AssignTaskResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	if err := h.validate.Var(req.AssigneeId, "required,uuid4"); err != nil {
+		return &pb.AssignTaskResponse{}, fmt.Errorf("invalid assignee: %w", err)
+	}
+
+	task, err := h.service.Assign(ctx, id, req.AssigneeId)
+	if err != nil {
+		return &pb.AssignTaskResponse{}, statusError(err, "failed to assign task")
+	}
+
+	return &pb.AssignTaskResponse{Task: pbTask(task)}, nil
+}
+
+func (h *TaskHandler) UnassignTask(ctx context.Context, req *pb.UnassignTaskRequest) (*pb.UnassignTaskResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.UnassignTaskResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	task, err := h.service.Unassign(ctx, id)
+	if err != nil {
+		return &pb.UnassignTaskResponse{}, statusError(err, "failed to unassign task")
+	}
+
+	return &pb.UnassignTaskResponse{Task: pbTask(task)}, nil
+}
+
+func (h *TaskHandler) MoveTask(ctx context.Context, req *pb.MoveTaskRequest) (*pb.MoveTaskResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.MoveTaskResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	if err := h.validate.Var(req.ProjectId, "omitempty,uuid4"); err != nil {
+		return &pb.MoveTaskResponse{}, fmt.Errorf("invalid project: %w", err)
+	}
+
+	task, err := h.service.Move(ctx, id, req.ProjectId)
+	if err != nil {
+		return &pb.MoveTaskResponse{}, statusError(err, "failed to move task")
+	}
+
+	return &pb.MoveTaskResponse{Task: pbTask(task)}, nil
+}
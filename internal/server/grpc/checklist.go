@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ChecklistServise interface {
+	List(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error)
+	Add(ctx context.Context, taskID uuid.UUID, text string) (models.ChecklistItem, error)
+	Edit(ctx context.Context, id uuid.UUID, text string) (models.ChecklistItem, error)
+	SetChecked(ctx context.Context, id uuid.UUID, checked bool) (models.ChecklistItem, error)
+	Reorder(ctx context.Context, taskID uuid.UUID, ids []uuid.UUID) ([]models.ChecklistItem, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+var errChecklistsDisabled = status.Error(codes.Unimplemented, "checklists aren't available with this storage")
+
+// WithChecklists enables the checklist RPCs.
+func (h *TaskHandler) WithChecklists(svc ChecklistServise) *TaskHandler {
+	h.checklists = svc
+	return h
+}
+
+func (h *TaskHandler) ListChecklist(ctx context.Context, req *pb.ListChecklistRequest) (*pb.ListChecklistResponse, error) {
+	if h.checklists == nil {
+		return &pb.ListChecklistResponse{}, errChecklistsDisabled
+	}
+
+	taskID, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.ListChecklistResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	items, err := h.checklists.List(ctx, taskID)
+	if err != nil {
+		return &pb.ListChecklistResponse{}, statusError(err, "failed to list checklist")
+	}
+
+	return &pb.ListChecklistResponse{Items: pbChecklistItems(items)}, nil
+}
+
+func (h *TaskHandler) AddChecklistItem(ctx context.Context, req *pb.AddChecklistItemRequest) (*pb.AddChecklistItemResponse, error) {
+	if h.checklists == nil {
+		return &pb.AddChecklistItemResponse{}, errChecklistsDisabled
+	}
+
+	taskID, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.AddChecklistItemResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	if err := h.validate.Var(req.Text, "required,max=1000"); err != nil {
+		return &pb.AddChecklistItemResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	item, err := h.checklists.Add(ctx, taskID, req.Text)
+	if err != nil {
+		return &pb.AddChecklistItemResponse{}, statusError(err, "failed to add checklist item")
+	}
+
+	return &pb.AddChecklistItemResponse{Item: pbChecklistItem(item)}, nil
+}
+
+func (h *TaskHandler) EditChecklistItem(ctx context.Context, req *pb.EditChecklistItemRequest) (*pb.EditChecklistItemResponse, error) {
+	if h.checklists == nil {
+		return &pb.EditChecklistItemResponse{}, errChecklistsDisabled
+	}
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return &pb.EditChecklistItemResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	if err := h.validate.Var(req.Text, "required,max=1000"); err != nil {
+		return &pb.EditChecklistItemResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	item, err := h.checklists.Edit(ctx, id, req.Text)
+	if err != nil {
+		return &pb.EditChecklistItemResponse{}, statusError(err, "failed to edit checklist item")
+	}
+
+	return &pb.EditChecklistItemResponse{Item: pbChecklistItem(item)}, nil
+}
+
+func (h *TaskHandler) SetChecklistItemChecked(ctx context.Context, req *pb.SetChecklistItemCheckedRequest) (*pb.SetChecklistItemCheckedResponse, error) {
+	if h.checklists == nil {
+		return &pb.SetChecklistItemCheckedResponse{}, errChecklistsDisabled
+	}
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return &pb.SetChecklistItemCheckedResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	item, err := h.checklists.SetChecked(ctx, id, req.Checked)
+	if err != nil {
+		return &pb.SetChecklistItemCheckedResponse{}, statusError(err, "failed to toggle checklist item")
+	}
+
+	return &pb.SetChecklistItemCheckedResponse{Item: pbChecklistItem(item)}, nil
+}
+
+func (h *TaskHandler) ReorderChecklist(ctx context.Context, req *pb.ReorderChecklistRequest) (*pb.ReorderChecklistResponse, error) {
+	if h.checklists == nil {
+		return &pb.ReorderChecklistResponse{}, errChecklistsDisabled
+	}
+
+	taskID, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.ReorderChecklistResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(req.ItemIds))
+	for i, raw := range req.ItemIds {
+		ids[i], err = uuid.Parse(raw)
+		if err != nil {
+			return &pb.ReorderChecklistResponse{}, fmt.Errorf("invalid item UUID: %w", err)
+		}
+	}
+
+	items, err := h.checklists.Reorder(ctx, taskID, ids)
+	if err != nil {
+		return &pb.ReorderChecklistResponse{}, statusError(err, "failed to reorder checklist")
+	}
+
+	return &pb.ReorderChecklistResponse{Items: pbChecklistItems(items)}, nil
+}
+
+func (h *TaskHandler) DeleteChecklistItem(ctx context.Context, req *pb.DeleteChecklistItemRequest) (*pb.DeleteChecklistItemResponse, error) {
+	if h.checklists == nil {
+		return &pb.DeleteChecklistItemResponse{}, errChecklistsDisabled
+	}
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return &pb.DeleteChecklistItemResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	if err := h.checklists.Delete(ctx, id); err != nil {
+		return &pb.DeleteChecklistItemResponse{}, statusError(err, "failed to delete checklist item")
+	}
+
+	return &pb.DeleteChecklistItemResponse{Success: true}, nil
+}
+
+func pbChecklistItems(items []models.ChecklistItem) []*pb.ChecklistItem {
+	res := make([]*pb.ChecklistItem, len(items))
+	for i, item := range items {
+		res[i] = pbChecklistItem(item)
+	}
+	return res
+}
+
+func pbChecklistItem(item models.ChecklistItem) *pb.ChecklistItem {
+	return &pb.ChecklistItem{
+		Id:       item.ID,
+		TaskId:   item.TaskID,
+		Text:     item.Text,
+		Checked:  item.Checked,
+		Position: int32(item.Position),
+	}
+}
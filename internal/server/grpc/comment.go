@@ -0,0 +1,162 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type CommentServise interface {
+	Create(ctx context.Context, comment models.Comment) (models.Comment, error)
+	Update(ctx context.Context, comment models.Comment) (models.Comment, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, taskID string, page models.Page) (models.CommentList, error)
+	History(ctx context.Context, id string) ([]models.CommentEdit, error)
+}
+
+var errCommentsDisabled = status.Error(codes.Unimplemented, "comments aren't available with this storage")
+
+// WithComments enables the comment RPCs.
+func (h *TaskHandler) WithComments(svc CommentServise) *TaskHandler {
+	h.comments = svc
+	return h
+}
+
+func (h *TaskHandler) ListComments(ctx context.Context, req *pb.ListCommentsRequest) (*pb.ListCommentsResponse, error) {
+	if h.comments == nil {
+		return &pb.ListCommentsResponse{}, errCommentsDisabled
+	}
+
+	if _, err := uuid.Parse(req.TaskId); err != nil {
+		return &pb.ListCommentsResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	page := models.Page{
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if err := h.validate.Struct(page); err != nil {
+		return &pb.ListCommentsResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	comments, err := h.comments.List(ctx, req.TaskId, page)
+	if err != nil {
+		return &pb.ListCommentsResponse{}, statusError(err, "failed to list comments")
+	}
+
+	resp := &pb.ListCommentsResponse{
+		Comments: make([]*pb.Comment, len(comments.Comments)),
+		Total:    int32(comments.Total),
+	}
+	for i, comment := range comments.Comments {
+		resp.Comments[i] = pbComment(comment)
+	}
+	return resp, nil
+}
+
+func (h *TaskHandler) CreateComment(ctx context.Context, req *pb.CreateCommentRequest) (*pb.CreateCommentResponse, error) {
+	if h.comments == nil {
+		return &pb.CreateCommentResponse{}, errCommentsDisabled
+	}
+
+	comment := models.Comment{
+		TaskID: req.TaskId,
+		Body:   req.Body,
+	}
+	if err := h.validate.Struct(comment); err != nil {
+		return &pb.CreateCommentResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	comment, err := h.comments.Create(ctx, comment)
+	if err != nil {
+		return &pb.CreateCommentResponse{}, statusError(err, "failed to create comment")
+	}
+
+	return &pb.CreateCommentResponse{Comment: pbComment(comment)}, nil
+}
+
+func (h *TaskHandler) UpdateComment(ctx context.Context, req *pb.UpdateCommentRequest) (*pb.UpdateCommentResponse, error) {
+	if h.comments == nil {
+		return &pb.UpdateCommentResponse{}, errCommentsDisabled
+	}
+
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return &pb.UpdateCommentResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	if err := h.validate.Var(req.Body, "required,max=10000"); err != nil {
+		return &pb.UpdateCommentResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	comment, err := h.comments.Update(ctx, models.Comment{
+		ID:   req.Id,
+		Body: req.Body,
+	})
+	if err != nil {
+		return &pb.UpdateCommentResponse{}, statusError(err, "failed to update comment")
+	}
+
+	return &pb.UpdateCommentResponse{Comment: pbComment(comment)}, nil
+}
+
+func (h *TaskHandler) DeleteComment(ctx context.Context, req *pb.DeleteCommentRequest) (*pb.DeleteCommentResponse, error) {
+	if h.comments == nil {
+		return &pb.DeleteCommentResponse{}, errCommentsDisabled
+	}
+
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return &pb.DeleteCommentResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	if err := h.comments.Delete(ctx, req.Id); err != nil {
+		return &pb.DeleteCommentResponse{}, statusError(err, "failed to delete comment")
+	}
+
+	return &pb.DeleteCommentResponse{Success: true}, nil
+}
+
+func (h *TaskHandler) GetCommentHistory(ctx context.Context, req *pb.GetCommentHistoryRequest) (*pb.GetCommentHistoryResponse, error) {
+	if h.comments == nil {
+		return &pb.GetCommentHistoryResponse{}, errCommentsDisabled
+	}
+
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return &pb.GetCommentHistoryResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	edits, err := h.comments.History(ctx, req.Id)
+	if err != nil {
+		return &pb.GetCommentHistoryResponse{}, statusError(err, "failed to receive comment history")
+	}
+
+	resp := &pb.GetCommentHistoryResponse{
+		Edits: make([]*pb.CommentEdit, len(edits)),
+	}
+	for i, edit := range edits {
+		resp.Edits[i] = &pb.CommentEdit{
+			Id:        edit.ID,
+			CommentId: edit.CommentID,
+			EditorId:  edit.EditorID,
+			Body:      edit.Body,
+			Edited:    timestamppb.New(edit.Edited),
+		}
+	}
+	return resp, nil
+}
+
+func pbComment(comment models.Comment) *pb.Comment {
+	return &pb.Comment{
+		Id:       comment.ID,
+		TaskId:   comment.TaskID,
+		AuthorId: comment.AuthorID,
+		Body:     comment.Body,
+		Created:  timestamppb.New(comment.Created),
+		Updated:  timestamppb.New(comment.Updated),
+		Edited:   comment.Edited,
+	}
+}
@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WatchTasks streams the changes of the tasks matching the filter. A stream
+// ended with Unavailable fell behind and can be resumed after the last received event.
+func (h *TaskHandler) WatchTasks(req *pb.WatchTasksRequest, stream grpc.ServerStreamingServer[pb.TaskEvent]) error {
+	ctx := stream.Context()
+
+	var filter models.TaskFilter
+	if req.Filter != nil {
+		filter = modelsTaskFilter(req.Filter)
+	}
+	if err := h.validate.Struct(filter); err != nil {
+		return fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	events, err := h.service.Watch(ctx, filter, req.LastEventId)
+	if err != nil {
+		return statusError(err, "failed to watch tasks")
+	}
+
+	for event := range events {
+		err := stream.Send(&pb.TaskEvent{
+			Id:      event.ID,
+			Type:    string(event.Type),
+			Task:    pbTask(event.Task),
+			Created: timestamppb.New(event.Created),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Unavailable, "watcher fell behind, resume after the last received event")
+}
@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *TaskHandler) GetTaskHistory(ctx context.Context, req *pb.GetTaskHistoryRequest) (*pb.GetTaskHistoryResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.GetTaskHistoryResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	revisions, err := h.service.History(ctx, id)
+	if err != nil {
+		return &pb.GetTaskHistoryResponse{}, statusError(err, "failed to receive task history")
+	}
+
+	resp := &pb.GetTaskHistoryResponse{
+		Revisions: make([]*pb.Revision, len(revisions)),
+	}
+	for i, val := range revisions {
+		resp.Revisions[i] = pbRevision(val)
+	}
+	return resp, nil
+}
+
+func (h *TaskHandler) RevertTask(ctx context.Context, req *pb.RevertTaskRequest) (*pb.RevertTaskResponse, error) {
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.RevertTaskResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	revisionID, err := uuid.Parse(req.RevisionId)
+	if err != nil {
+		return &pb.RevertTaskResponse{}, fmt.Errorf("invalid revision UUID: %w", err)
+	}
+
+	task, err := h.service.Revert(ctx, id, revisionID)
+	if err != nil {
+		return &pb.RevertTaskResponse{}, statusError(err, "failed to revert task")
+	}
+
+	return &pb.RevertTaskResponse{Task: pbTask(task)}, nil
+}
+
+func pbRevision(revision models.Revision) *pb.Revision {
+	res := &pb.Revision{
+		Id:      revision.ID,
+		TaskId:  revision.TaskID,
+		ActorId: revision.ActorID,
+		Action:  string(revision.Action),
+		Changes: make([]*pb.FieldChange, len(revision.Changes)),
+		Created: timestamppb.New(revision.Created),
+	}
+	for i, change := range revision.Changes {
+		// values are decoded from JSON, so the conversion can't fail
+		oldValue, _ := structpb.NewValue(change.Old)
+		newValue, _ := structpb.NewValue(change.New)
+		res.Changes[i] = &pb.FieldChange{
+			Field:    change.Field,
+			OldValue: oldValue,
+			NewValue: newValue,
+		}
+	}
+	return res
+}
@@ -2,34 +2,54 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 
+	"github.com/VikaPaz/task_tracker/internal/auth"
 	"github.com/VikaPaz/task_tracker/internal/models"
 	pb "github.com/VikaPaz/task_tracker/proto/task"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type TaskServise interface {
 	Update(ctx context.Context, req models.Task) (models.Task, error)
 	List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error)
+	Assign(ctx context.Context, id uuid.UUID, assigneeID string) (models.Task, error)
+	Unassign(ctx context.Context, id uuid.UUID) (models.Task, error)
+	Move(ctx context.Context, id uuid.UUID, projectID string) (models.Task, error)
+	Archive(ctx context.Context, id uuid.UUID) (models.Task, error)
+	Unarchive(ctx context.Context, id uuid.UUID) (models.Task, error)
+	History(ctx context.Context, id uuid.UUID) ([]models.Revision, error)
+	Revert(ctx context.Context, id uuid.UUID, revisionID uuid.UUID) (models.Task, error)
+	Watch(ctx context.Context, filter models.TaskFilter, afterID uint64) (<-chan models.TaskEvent, error)
+	Sync(ctx context.Context, token string) (models.TaskChanges, error)
 }
 
 type TaskHandler struct {
 	pb.UnimplementedTaskServiceServer
-	router   *grpc.Server
-	service  TaskServise
-	validate *validator.Validate
-	log      *zerolog.Logger
+	router     *grpc.Server
+	service    TaskServise
+	workflow   WorkflowServise
+	comments   CommentServise
+	worklogs   WorkLogServise
+	checklists ChecklistServise
+	validate   *validator.Validate
+	log        *zerolog.Logger
 }
 
 func NewTaskHandler(svc TaskServise, log *zerolog.Logger) *TaskHandler {
-	router := grpc.NewServer()
+	router := grpc.NewServer(grpc.UnaryInterceptor(identify), grpc.StreamInterceptor(identifyStream))
 	validate := validator.New()
 	return &TaskHandler{
 		router:   router,
@@ -61,7 +81,7 @@ func (h *TaskHandler) GetTasks(ctx context.Context, req *pb.GetTasksRequest) (*p
 
 	tasks, err := h.service.List(ctx, filter)
 	if err != nil {
-		return &pb.GetTasksResponse{}, fmt.Errorf("failed to list tasks: %w", err)
+		return &pb.GetTasksResponse{}, statusError(err, "failed to list tasks")
 
 	}
 
@@ -72,9 +92,124 @@ func (h *TaskHandler) GetTasks(ctx context.Context, req *pb.GetTasksRequest) (*p
 	}, nil
 }
 
-// TODO: add auth
-func genOwner() string {
-	return uuid.New().String()
+func (h *TaskHandler) GetTaskStats(ctx context.Context, req *pb.GetTaskStatsRequest) (*pb.GetTaskStatsResponse, error) {
+	filter := models.TaskStatsFilter{
+		GroupBy: models.StatsGroup(req.GroupBy),
+	}
+	if req.Filter != nil {
+		filter.TaskFilter = modelsTaskFilter(req.Filter)
+	}
+
+	if err := h.validate.Struct(filter); err != nil {
+		return &pb.GetTaskStatsResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	stats, err := h.service.Stats(ctx, filter)
+	if err != nil {
+		return &pb.GetTaskStatsResponse{}, statusError(err, "failed to receive task stats")
+	}
+
+	resp := &pb.GetTaskStatsResponse{
+		Stats: make([]*pb.TaskStats, len(stats)),
+	}
+	for i, val := range stats {
+		resp.Stats[i] = &pb.TaskStats{
+			Key:              val.Key,
+			Tasks:            int32(val.Tasks),
+			StoryPoints:      int32(val.StoryPoints),
+			OpenStoryPoints:  int32(val.OpenStoryPoints),
+			EstimateMinutes:  int32(val.EstimateMinutes),
+			RemainingMinutes: int32(val.RemainingMinutes),
+		}
+	}
+	return resp, nil
+}
+
+// identify stores the user ID passed in the auth.MetadataKey into the request context.
+func identify(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get(auth.MetadataKey)) == 0 {
+		return handler(ctx, req)
+	}
+
+	userID, err := auth.Parse(md.Get(auth.MetadataKey)[0])
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid %s metadata: %v", auth.MetadataKey, err)
+	}
+
+	return handler(auth.WithUser(ctx, userID), req)
+}
+
+// identifyStream is identify for streaming RPCs.
+func identifyStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, ok := metadata.FromIncomingContext(stream.Context())
+	if !ok || len(md.Get(auth.MetadataKey)) == 0 {
+		return handler(srv, stream)
+	}
+
+	userID, err := auth.Parse(md.Get(auth.MetadataKey)[0])
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid %s metadata: %v", auth.MetadataKey, err)
+	}
+
+	return handler(srv, &identifiedStream{ServerStream: stream, ctx: auth.WithUser(stream.Context(), userID)})
+}
+
+type identifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identifiedStream) Context() context.Context {
+	return s.ctx
+}
+
+// statusError wraps a domain error into a gRPC status with a matching code.
+func statusError(err error, msg string) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound),
+		errors.Is(err, models.ErrCommentNotFound),
+		errors.Is(err, models.ErrProjectNotFound),
+		errors.Is(err, models.ErrMemberNotFound),
+		errors.Is(err, models.ErrTimerNotFound),
+		errors.Is(err, models.ErrChecklistItemNotFound),
+		errors.Is(err, models.ErrFieldNotFound),
+		errors.Is(err, models.ErrRevisionNotFound),
+		errors.Is(err, models.ErrTemplateNotFound),
+		errors.Is(err, models.ErrWebhookNotFound),
+		errors.Is(err, models.ErrDeliveryNotFound):
+		code = codes.NotFound
+	case errors.Is(err, models.ErrStatusNotFound),
+		errors.Is(err, models.ErrInvalidRRule),
+		errors.Is(err, models.ErrDueRequired),
+		errors.Is(err, models.ErrNotRecurring),
+		errors.Is(err, models.ErrInvalidRange),
+		errors.Is(err, models.ErrInvalidOrder),
+		errors.Is(err, models.ErrInvalidField),
+		errors.Is(err, models.ErrProjectRequired),
+		errors.Is(err, models.ErrParentNotFound),
+		errors.Is(err, models.ErrMissingVariable),
+		errors.Is(err, models.ErrInvalidUsername),
+		errors.Is(err, models.ErrInvalidSyncToken):
+		code = codes.InvalidArgument
+	case errors.Is(err, models.ErrTransitionNotAllowed),
+		errors.Is(err, models.ErrTimerRunning),
+		errors.Is(err, models.ErrNotRevertible),
+		errors.Is(err, models.ErrRevertConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, models.ErrFieldExists),
+		errors.Is(err, models.ErrUsernameTaken):
+		code = codes.AlreadyExists
+	case errors.Is(err, models.ErrUnauthenticated):
+		code = codes.Unauthenticated
+	case errors.Is(err, models.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, models.ErrEventsExpired),
+		errors.Is(err, models.ErrSyncTokenExpired):
+		code = codes.OutOfRange
+	}
+	return status.Errorf(code, "%s: %v", msg, err)
 }
 
 func (h *TaskHandler) UpdateTaskStatus(ctx context.Context, req *pb.UpdateTaskStatusRequest) (*pb.UpdateTaskStatusResponse, error) {
@@ -83,16 +218,12 @@ func (h *TaskHandler) UpdateTaskStatus(ctx context.Context, req *pb.UpdateTaskSt
 	}
 
 	task.ID = req.TaskId
-	switch req.NewStatus {
-	case pb.TaskStatus_IN_PROGRESS:
-		task.Status = models.InProgress
-	case pb.TaskStatus_DONE:
-		task.Status = models.Done
-	default:
-		task.Status = ""
+	task.Status = models.TaskStatus(req.Status)
+	if task.Status == "" {
+		// legacy clients still send the enum
+		task.Status = modelsLegacyStatus(req.NewStatus)
 	}
 
-	task.OwnerID = genOwner()
 	_, err := uuid.Parse(task.ID)
 	if err != nil {
 		return &pb.UpdateTaskStatusResponse{}, fmt.Errorf("invalid UUID: %w", err)
@@ -101,7 +232,7 @@ func (h *TaskHandler) UpdateTaskStatus(ctx context.Context, req *pb.UpdateTaskSt
 
 	updatedTask, err := h.service.Update(ctx, task)
 	if err != nil {
-		return &pb.UpdateTaskStatusResponse{}, fmt.Errorf("failed to update task: %w", err)
+		return &pb.UpdateTaskStatusResponse{}, statusError(err, "failed to update task")
 	}
 
 	return &pb.UpdateTaskStatusResponse{
@@ -115,40 +246,91 @@ func modelsTaskFilter(filter *pb.TaskFilter) models.TaskFilter {
 		ID:          filter.Id,
 		Title:       filter.Title,
 		Description: filter.Description,
+		Status:      filter.Status,
 		OwnerID:     filter.OwnerId,
+		AssigneeID:  filter.AssigneeId,
+		ProjectID:   filter.ProjectId,
+
+		CustomFields:    filter.CustomFields,
+		IncludeArchived: filter.IncludeArchived,
+		Label:           filter.Label,
+		ParentID:        filter.ParentId,
+	}
+
+	if res.Status == "" {
+		// legacy clients still send the enum
+		res.Status = modelsLegacyStatus(filter.LegacyStatus).String()
 	}
 
-	switch filter.Status {
+	return res
+}
+
+// modelsLegacyStatus maps the deprecated proto enum to a workflow status.
+func modelsLegacyStatus(status pb.TaskStatus) models.TaskStatus {
+	switch status {
 	case pb.TaskStatus_IN_PROGRESS:
-		res.Status = models.InProgress.String()
+		return models.InProgress
 	case pb.TaskStatus_DONE:
-		res.Status = models.Done.String()
+		return models.Done
 	default:
-		res.Status = ""
+		return ""
 	}
+}
 
-	return res
+// pbLegacyStatus fills the deprecated proto enum for clients that
+// don't know about string statuses yet.
+func pbLegacyStatus(status models.TaskStatus) pb.TaskStatus {
+	switch status {
+	case models.InProgress:
+		return pb.TaskStatus_IN_PROGRESS
+	case models.Done:
+		return pb.TaskStatus_DONE
+	default:
+		return pb.TaskStatus_TASK_STATUS_UNSPECIFIED
+	}
 }
 
 func pbTasks(tasks []models.Task) []*pb.Task {
 	req := make([]*pb.Task, len(tasks))
 	for i, task := range tasks {
-		req[i] = &pb.Task{
-			Id:          task.ID,
-			Title:       task.Title,
-			Description: task.Description,
-			OwnerId:     task.OwnerID,
-			Created:     timestamppb.New(task.Created),
-			Updated:     timestamppb.New(task.Updated),
-		}
-		switch task.Status {
-		case models.InProgress:
-			req[i].Status = pb.TaskStatus_IN_PROGRESS
-		case models.Done:
-			req[i].Status = pb.TaskStatus_DONE
-		default:
-			req[i].Status = pb.TaskStatus_TASK_STATUS_UNSPECIFIED
-		}
+		req[i] = pbTask(task)
 	}
 	return req
 }
+
+func pbTask(task models.Task) *pb.Task {
+	res := &pb.Task{
+		Id:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		OwnerId:      task.OwnerID,
+		AssigneeId:   task.AssigneeID,
+		Created:      timestamppb.New(task.Created),
+		Updated:      timestamppb.New(task.Updated),
+		Status:       task.Status.String(),
+		LegacyStatus: pbLegacyStatus(task.Status),
+		Rrule:        task.RRule,
+		ProjectId:    task.ProjectID,
+
+		EstimateMinutes: int32(task.EstimateMinutes),
+		StoryPoints:     int32(task.StoryPoints),
+		ChecklistProgress: &pb.ChecklistProgress{
+			Checked: int32(task.ChecklistProgress.Checked),
+			Total:   int32(task.ChecklistProgress.Total),
+		},
+		ParentId: task.ParentID,
+		Labels:   task.Labels,
+		Watchers: task.Watchers,
+	}
+	if !task.Due.IsZero() {
+		res.Due = timestamppb.New(task.Due)
+	}
+	if !task.Archived.IsZero() {
+		res.Archived = timestamppb.New(task.Archived)
+	}
+	if len(task.CustomFields) != 0 {
+		// values are validated JSON, so the conversion can't fail
+		res.CustomFields, _ = structpb.NewStruct(task.CustomFields)
+	}
+	return res
+}
@@ -0,0 +1,26 @@
+package grpc
+
+import (
+	"context"
+
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+)
+
+func (h *TaskHandler) SyncTasks(ctx context.Context, req *pb.SyncTasksRequest) (*pb.SyncTasksResponse, error) {
+	changes, err := h.service.Sync(ctx, req.Since)
+	if err != nil {
+		return &pb.SyncTasksResponse{}, statusError(err, "failed to sync tasks")
+	}
+
+	tasks := make([]*pb.Task, 0, len(changes.Tasks))
+	for _, task := range changes.Tasks {
+		tasks = append(tasks, pbTask(task))
+	}
+
+	return &pb.SyncTasksResponse{
+		Tasks:   tasks,
+		Deleted: changes.Deleted,
+		Token:   changes.Token,
+		More:    changes.More,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type WorkflowServise interface {
+	Get(ctx context.Context) (models.Workflow, error)
+}
+
+// WithWorkflow enables the workflow RPCs.
+func (h *TaskHandler) WithWorkflow(svc WorkflowServise) *TaskHandler {
+	h.workflow = svc
+	return h
+}
+
+func (h *TaskHandler) GetWorkflow(ctx context.Context, req *pb.GetWorkflowRequest) (*pb.GetWorkflowResponse, error) {
+	if h.workflow == nil {
+		return &pb.GetWorkflowResponse{}, status.Error(codes.Unimplemented, "the workflow isn't available with this storage")
+	}
+
+	workflow, err := h.workflow.Get(ctx)
+	if err != nil {
+		return &pb.GetWorkflowResponse{}, statusError(err, "failed to receive workflow")
+	}
+
+	resp := &pb.GetWorkflowResponse{
+		Statuses:    make([]*pb.WorkflowStatus, len(workflow.Statuses)),
+		Transitions: make([]*pb.Transition, len(workflow.Transitions)),
+	}
+	for i, status := range workflow.Statuses {
+		resp.Statuses[i] = &pb.WorkflowStatus{
+			Name:     status.Name.String(),
+			Title:    status.Title,
+			Category: pbStatusCategory(status.Category),
+			Position: int32(status.Position),
+		}
+	}
+	for i, transition := range workflow.Transitions {
+		resp.Transitions[i] = &pb.Transition{
+			From: transition.From.String(),
+			To:   transition.To.String(),
+		}
+	}
+
+	return resp, nil
+}
+
+func pbStatusCategory(category models.StatusCategory) pb.StatusCategory {
+	switch category {
+	case models.CategoryOpen:
+		return pb.StatusCategory_OPEN
+	case models.CategoryActive:
+		return pb.StatusCategory_ACTIVE
+	case models.CategoryClosed:
+		return pb.StatusCategory_CLOSED
+	default:
+		return pb.StatusCategory_STATUS_CATEGORY_UNSPECIFIED
+	}
+}
@@ -0,0 +1,216 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	pb "github.com/VikaPaz/task_tracker/proto/task"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type WorkLogServise interface {
+	Start(ctx context.Context, taskID uuid.UUID, note string) (models.WorkLog, error)
+	Stop(ctx context.Context) (models.WorkLog, error)
+	Log(ctx context.Context, log models.WorkLog) (models.WorkLog, error)
+	List(ctx context.Context, taskID string, page models.Page) (models.WorkLogList, error)
+	Total(ctx context.Context, filter models.WorkLogFilter) (int64, error)
+	Report(ctx context.Context, filter models.WorkReportFilter) ([]models.WorkReportRow, error)
+}
+
+var errWorkLogsDisabled = status.Error(codes.Unimplemented, "time tracking isn't available with this storage")
+
+// WithWorkLogs enables the time tracking RPCs.
+func (h *TaskHandler) WithWorkLogs(svc WorkLogServise) *TaskHandler {
+	h.worklogs = svc
+	return h
+}
+
+func (h *TaskHandler) StartTimer(ctx context.Context, req *pb.StartTimerRequest) (*pb.StartTimerResponse, error) {
+	if h.worklogs == nil {
+		return &pb.StartTimerResponse{}, errWorkLogsDisabled
+	}
+
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return &pb.StartTimerResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+	if err := h.validate.Var(req.Note, "max=1000"); err != nil {
+		return &pb.StartTimerResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	log, err := h.worklogs.Start(ctx, id, req.Note)
+	if err != nil {
+		return &pb.StartTimerResponse{}, statusError(err, "failed to start timer")
+	}
+
+	return &pb.StartTimerResponse{WorkLog: pbWorkLog(log)}, nil
+}
+
+func (h *TaskHandler) StopTimer(ctx context.Context, req *pb.StopTimerRequest) (*pb.StopTimerResponse, error) {
+	if h.worklogs == nil {
+		return &pb.StopTimerResponse{}, errWorkLogsDisabled
+	}
+
+	log, err := h.worklogs.Stop(ctx)
+	if err != nil {
+		return &pb.StopTimerResponse{}, statusError(err, "failed to stop timer")
+	}
+
+	return &pb.StopTimerResponse{WorkLog: pbWorkLog(log)}, nil
+}
+
+func (h *TaskHandler) LogWork(ctx context.Context, req *pb.LogWorkRequest) (*pb.LogWorkResponse, error) {
+	if h.worklogs == nil {
+		return &pb.LogWorkResponse{}, errWorkLogsDisabled
+	}
+
+	log := models.WorkLog{
+		TaskID:   req.TaskId,
+		Duration: req.Duration,
+		Note:     req.Note,
+	}
+	if req.Started != nil {
+		log.Started = req.Started.AsTime()
+	}
+	if err := h.validate.Struct(log); err != nil {
+		return &pb.LogWorkResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+	if log.Duration < 1 {
+		return &pb.LogWorkResponse{}, errors.New("failed to bind request: duration must be positive")
+	}
+
+	log, err := h.worklogs.Log(ctx, log)
+	if err != nil {
+		return &pb.LogWorkResponse{}, statusError(err, "failed to log work")
+	}
+
+	return &pb.LogWorkResponse{WorkLog: pbWorkLog(log)}, nil
+}
+
+func (h *TaskHandler) ListWorkLogs(ctx context.Context, req *pb.ListWorkLogsRequest) (*pb.ListWorkLogsResponse, error) {
+	if h.worklogs == nil {
+		return &pb.ListWorkLogsResponse{}, errWorkLogsDisabled
+	}
+
+	if _, err := uuid.Parse(req.TaskId); err != nil {
+		return &pb.ListWorkLogsResponse{}, fmt.Errorf("invalid UUID: %w", err)
+	}
+
+	page := models.Page{
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if err := h.validate.Struct(page); err != nil {
+		return &pb.ListWorkLogsResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	logs, err := h.worklogs.List(ctx, req.TaskId, page)
+	if err != nil {
+		return &pb.ListWorkLogsResponse{}, statusError(err, "failed to list work logs")
+	}
+
+	resp := &pb.ListWorkLogsResponse{
+		WorkLogs: make([]*pb.WorkLog, len(logs.WorkLogs)),
+		Total:    int32(logs.Total),
+	}
+	for i, log := range logs.WorkLogs {
+		resp.WorkLogs[i] = pbWorkLog(log)
+	}
+	return resp, nil
+}
+
+func (h *TaskHandler) GetWorkTotal(ctx context.Context, req *pb.GetWorkTotalRequest) (*pb.GetWorkTotalResponse, error) {
+	if h.worklogs == nil {
+		return &pb.GetWorkTotalResponse{}, errWorkLogsDisabled
+	}
+
+	filter := modelsWorkLogFilter(req.Filter)
+	if err := h.validate.Struct(filter); err != nil {
+		return &pb.GetWorkTotalResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	total, err := h.worklogs.Total(ctx, filter)
+	if err != nil {
+		return &pb.GetWorkTotalResponse{}, statusError(err, "failed to sum work logs")
+	}
+
+	return &pb.GetWorkTotalResponse{Duration: total}, nil
+}
+
+func (h *TaskHandler) GetWorkReport(ctx context.Context, req *pb.GetWorkReportRequest) (*pb.GetWorkReportResponse, error) {
+	if h.worklogs == nil {
+		return &pb.GetWorkReportResponse{}, errWorkLogsDisabled
+	}
+
+	filter := models.WorkReportFilter{
+		WorkLogFilter: modelsWorkLogFilter(req.Filter),
+	}
+	for _, group := range req.GroupBy {
+		filter.GroupBy = append(filter.GroupBy, models.ReportGroup(group))
+	}
+	if err := h.validate.Struct(filter); err != nil {
+		return &pb.GetWorkReportResponse{}, fmt.Errorf("failed to bind request: %w", err)
+	}
+
+	rows, err := h.worklogs.Report(ctx, filter)
+	if err != nil {
+		return &pb.GetWorkReportResponse{}, statusError(err, "failed to build work report")
+	}
+
+	resp := &pb.GetWorkReportResponse{
+		Rows: make([]*pb.WorkReportRow, len(rows)),
+	}
+	for i, row := range rows {
+		resp.Rows[i] = &pb.WorkReportRow{
+			ProjectId: row.ProjectID,
+			Duration:  row.Duration,
+		}
+		if !row.Day.IsZero() {
+			resp.Rows[i].Day = timestamppb.New(row.Day)
+		}
+	}
+	return resp, nil
+}
+
+func modelsWorkLogFilter(filter *pb.WorkLogFilter) models.WorkLogFilter {
+	if filter == nil {
+		return models.WorkLogFilter{}
+	}
+
+	res := models.WorkLogFilter{
+		TaskID:    filter.TaskId,
+		UserID:    filter.UserId,
+		OwnerID:   filter.OwnerId,
+		ProjectID: filter.ProjectId,
+	}
+	if filter.From != nil {
+		res.From = day(filter.From.AsTime())
+	}
+	if filter.To != nil {
+		res.To = day(filter.To.AsTime())
+	}
+	return res
+}
+
+// day truncates a timestamp to its UTC date.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func pbWorkLog(log models.WorkLog) *pb.WorkLog {
+	return &pb.WorkLog{
+		Id:       log.ID,
+		TaskId:   log.TaskID,
+		UserId:   log.UserID,
+		Started:  timestamppb.New(log.Started),
+		Duration: log.Duration,
+		Note:     log.Note,
+		Running:  log.Running,
+	}
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Archiving a task
+// @Description Handles request to archive a task, archived tasks are listed only with include_archived.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.Task "Archived task"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/archive [post]
+func (h *TaskHandler) ArchiveTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	task, err := h.service.Archive(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to archive task: %w", err))
+		return
+	}
+
+	h.Response(c, task, http.StatusOK, nil)
+}
+
+// @Summary Unarchiving a task
+// @Description Handles request to bring an archived task back to the task lists.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.Task "Unarchived task"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/unarchive [post]
+func (h *TaskHandler) UnarchiveTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	task, err := h.service.Unarchive(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to unarchive task: %w", err))
+		return
+	}
+
+	h.Response(c, task, http.StatusOK, nil)
+}
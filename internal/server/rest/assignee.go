@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AssignRequest struct {
+	AssigneeID string `validate:"required,uuid4"`
+}
+
+// @Summary Assigning a task
+// @Description Handles request to set the user working on a task. The change is recorded in the task history.
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body AssignRequest true "Assignee"
+// @Success 200 {object} models.Task "Updated task"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/assignee [put]
+func (h *TaskHandler) AssignTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	task, err := h.service.Assign(c.Request.Context(), id, req.AssigneeID)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to assign task: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"task": task}, http.StatusOK, nil)
+}
+
+// @Summary Unassigning a task
+// @Description Handles request to remove the assignee of a task. The change is recorded in the task history.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.Task "Updated task"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/assignee [delete]
+func (h *TaskHandler) UnassignTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	task, err := h.service.Unassign(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to unassign task: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"task": task}, http.StatusOK, nil)
+}
@@ -0,0 +1,206 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ChecksumHeader optionally carries the hex encoded SHA-256 of an uploaded file.
+const ChecksumHeader = "X-Checksum-SHA256"
+
+// multipartOverhead is allowed on top of the file size for multipart
+// boundaries and part headers.
+const multipartOverhead = 1 << 20
+
+type AttachmentServise interface {
+	MaxSize() int64
+	Upload(ctx context.Context, attachment models.Attachment, content io.Reader) (models.Attachment, error)
+	Open(ctx context.Context, id string) (models.Attachment, io.ReadCloser, error)
+	List(ctx context.Context, taskID string) ([]models.Attachment, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// WithAttachments enables the attachment endpoints.
+func (h *TaskHandler) WithAttachments(svc AttachmentServise) *TaskHandler {
+	h.attachments = svc
+	return h
+}
+
+func (h *TaskHandler) registerAttachmentRoutes() {
+	h.router.GET("/task/:id/attachments", h.ListAttachments)
+	h.router.POST("/task/:id/attachments", h.UploadAttachment)
+
+	attachments := h.router.Group("/attachments")
+	{
+		attachments.GET("/:id", h.DownloadAttachment)
+		attachments.DELETE("/:id", h.DeleteAttachment)
+	}
+}
+
+// @Summary Uploading an attachment
+// @Description Handles multipart request with a "file" part and stores it as an attachment of the task.
+// @Tags attachment
+// @Accept mpfd
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param file formData file true "File"
+// @Param X-Checksum-SHA256 header string false "Hex encoded SHA-256 of the file"
+// @Success 201 {object} models.Attachment "Created attachment"
+// @Failure 400
+// @Failure 404
+// @Failure 413
+// @Failure 500
+// @Router /task/{id}/attachments [post]
+func (h *TaskHandler) UploadAttachment(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	maxSize := h.attachments.MaxSize()
+	if c.Request.ContentLength > maxSize+multipartOverhead {
+		h.Response(c, nil, http.StatusRequestEntityTooLarge, models.ErrAttachmentTooLarge)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to read multipart form: %w", err))
+		return
+	}
+
+	// the file is streamed into the blob store instead of buffering the whole form
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			h.Response(c, nil, http.StatusBadRequest, errors.New("no file part in the form"))
+			return
+		}
+		if err != nil {
+			h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to read multipart form: %w", err))
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment := models.Attachment{
+			TaskID:      taskID.String(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Checksum:    c.GetHeader(ChecksumHeader),
+		}
+		if err := h.validate.Struct(attachment); err != nil {
+			part.Close()
+			h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid attachment: %w", err))
+			return
+		}
+
+		attachment, err = h.attachments.Upload(c.Request.Context(), attachment, part)
+		part.Close()
+		if err != nil {
+			status := errorStatus(err)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			h.Response(c, nil, status, fmt.Errorf("failed to upload attachment: %w", err))
+			return
+		}
+
+		h.Response(c, gin.H{"attachment": attachment}, http.StatusCreated, nil)
+		return
+	}
+}
+
+// @Summary Listing attachments
+// @Description Handles request to get metadata of the task attachments.
+// @Tags attachment
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.Attachment "attachments"
+// @Failure 400
+// @Failure 500
+// @Router /task/{id}/attachments [get]
+func (h *TaskHandler) ListAttachments(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	attachments, err := h.attachments.List(c.Request.Context(), taskID.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list attachments: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"attachments": attachments}, http.StatusOK, nil)
+}
+
+// @Summary Downloading an attachment
+// @Description Handles request to stream the attachment content.
+// @Tags attachment
+// @Produce octet-stream
+// @Param id path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /attachments/{id} [get]
+func (h *TaskHandler) DownloadAttachment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	attachment, content, err := h.attachments.Open(c.Request.Context(), id.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to open attachment: %w", err))
+		return
+	}
+	defer content.Close()
+
+	h.logRequest(c, http.StatusOK, nil)
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+		ChecksumHeader:           attachment.Checksum,
+	})
+}
+
+// @Summary Deleting an attachment
+// @Description Handles request to delete an attachment and its content.
+// @Tags attachment
+// @Param id path string true "Attachment ID"
+// @Success 204
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /attachments/{id} [delete]
+func (h *TaskHandler) DeleteAttachment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.attachments.Delete(c.Request.Context(), id.String()); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete attachment: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
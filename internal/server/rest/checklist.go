@@ -0,0 +1,249 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChecklistServise interface {
+	List(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error)
+	Add(ctx context.Context, taskID uuid.UUID, text string) (models.ChecklistItem, error)
+	Edit(ctx context.Context, id uuid.UUID, text string) (models.ChecklistItem, error)
+	SetChecked(ctx context.Context, id uuid.UUID, checked bool) (models.ChecklistItem, error)
+	Reorder(ctx context.Context, taskID uuid.UUID, ids []uuid.UUID) ([]models.ChecklistItem, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// WithChecklists enables the checklist endpoints.
+func (h *TaskHandler) WithChecklists(svc ChecklistServise) *TaskHandler {
+	h.checklists = svc
+	return h
+}
+
+func (h *TaskHandler) registerChecklistRoutes() {
+	h.router.GET("/task/:id/checklist", h.ListChecklist)
+	h.router.POST("/task/:id/checklist", h.AddChecklistItem)
+	h.router.PUT("/task/:id/checklist/order", h.ReorderChecklist)
+
+	checklist := h.router.Group("/checklist")
+	{
+		checklist.PUT("/:id", h.EditChecklistItem)
+		checklist.PUT("/:id/checked", h.ToggleChecklistItem)
+		checklist.DELETE("/:id", h.DeleteChecklistItem)
+	}
+}
+
+type ChecklistItemRequest struct {
+	Text string `validate:"required,max=1000"`
+}
+
+type CheckRequest struct {
+	Checked bool
+}
+
+type ReorderRequest struct {
+	ItemIDs []uuid.UUID `validate:"required" swaggertype:"array,string"`
+}
+
+// @Summary Listing a checklist
+// @Description Handles request to get the checklist items of a task in their order.
+// @Tags checklist
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.ChecklistItem "items"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/checklist [get]
+func (h *TaskHandler) ListChecklist(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	items, err := h.checklists.List(c.Request.Context(), taskID)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list checklist: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"items": items}, http.StatusOK, nil)
+}
+
+// @Summary Adding a checklist item
+// @Description Handles request to append an unchecked item to the checklist of a task.
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body ChecklistItemRequest true "Item"
+// @Success 201 {object} models.ChecklistItem "Created item"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/checklist [post]
+func (h *TaskHandler) AddChecklistItem(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	item, err := h.checklists.Add(c.Request.Context(), taskID, req.Text)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to add checklist item: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"item": item}, http.StatusCreated, nil)
+}
+
+// @Summary Reordering a checklist
+// @Description Handles request to reorder the checklist of a task. The request must list every item of the checklist once.
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body ReorderRequest true "Item IDs in the new order"
+// @Success 200 {array} models.ChecklistItem "items"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/checklist/order [put]
+func (h *TaskHandler) ReorderChecklist(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	items, err := h.checklists.Reorder(c.Request.Context(), taskID, req.ItemIDs)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to reorder checklist: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"items": items}, http.StatusOK, nil)
+}
+
+// @Summary Editing a checklist item
+// @Description Handles request to change the text of a checklist item.
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Item ID"
+// @Param request body ChecklistItemRequest true "Item"
+// @Success 200 {object} models.ChecklistItem "Updated item"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /checklist/{id} [put]
+func (h *TaskHandler) EditChecklistItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	item, err := h.checklists.Edit(c.Request.Context(), id, req.Text)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to edit checklist item: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"item": item}, http.StatusOK, nil)
+}
+
+// @Summary Toggling a checklist item
+// @Description Handles request to check or uncheck a checklist item. The checklist progress of the task is updated.
+// @Tags checklist
+// @Accept json
+// @Produce json
+// @Param id path string true "Item ID"
+// @Param request body CheckRequest true "Checked"
+// @Success 200 {object} models.ChecklistItem "Updated item"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /checklist/{id}/checked [put]
+func (h *TaskHandler) ToggleChecklistItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req CheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	item, err := h.checklists.SetChecked(c.Request.Context(), id, req.Checked)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to toggle checklist item: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"item": item}, http.StatusOK, nil)
+}
+
+// @Summary Deleting a checklist item
+// @Description Handles request to remove an item from a checklist.
+// @Tags checklist
+// @Param id path string true "Item ID"
+// @Success 204
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /checklist/{id} [delete]
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.checklists.Delete(c.Request.Context(), id); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete checklist item: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
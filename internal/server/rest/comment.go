@@ -0,0 +1,224 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommentServise interface {
+	Create(ctx context.Context, comment models.Comment) (models.Comment, error)
+	Update(ctx context.Context, comment models.Comment) (models.Comment, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, taskID string, page models.Page) (models.CommentList, error)
+	History(ctx context.Context, id string) ([]models.CommentEdit, error)
+}
+
+// WithComments enables the comment endpoints.
+func (h *TaskHandler) WithComments(svc CommentServise) *TaskHandler {
+	h.comments = svc
+	return h
+}
+
+func (h *TaskHandler) registerCommentRoutes() {
+	h.router.GET("/task/:id/comments", h.ListComments)
+	h.router.POST("/task/:id/comments", h.CreateComment)
+
+	comments := h.router.Group("/comments")
+	{
+		comments.PUT("/:id", h.UpdateComment)
+		comments.DELETE("/:id", h.DeleteComment)
+		comments.GET("/:id/history", h.GetCommentHistory)
+	}
+}
+
+type CommentRequest struct {
+	Body string `validate:"required,max=10000"`
+}
+
+// @Summary Listing comments
+// @Description Handles request to get a page of comments of a task ordered by creation time.
+// @Tags comment
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} models.CommentList "comments"
+// @Failure 400
+// @Failure 500
+// @Router /task/{id}/comments [get]
+func (h *TaskHandler) ListComments(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var page models.Page
+	if err := c.ShouldBindQuery(&page); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	if err := h.validate.Struct(page); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid page: %w", err))
+		return
+	}
+
+	comments, err := h.comments.List(c.Request.Context(), taskID.String(), page)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list comments: %w", err))
+		return
+	}
+
+	page = page.Normalize()
+	h.Response(c, gin.H{
+		"comments": comments.Comments,
+		"total":    comments.Total,
+		"limit":    page.Limit,
+		"offset":   page.Offset,
+	}, http.StatusOK, nil)
+}
+
+// @Summary Creating a comment
+// @Description Handles request to comment a task on behalf of the authenticated user.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-User-ID header string true "Author ID"
+// @Param request body CommentRequest true "Comment"
+// @Success 201 {object} models.Comment "Created comment"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/comments [post]
+func (h *TaskHandler) CreateComment(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	comment, err := h.comments.Create(c.Request.Context(), models.Comment{
+		TaskID: taskID.String(),
+		Body:   req.Body,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create comment: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"comment": comment}, http.StatusCreated, nil)
+}
+
+// @Summary Updating a comment
+// @Description Handles request to edit a comment. The previous text is kept in the comment history.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param X-User-ID header string true "Author ID"
+// @Param request body CommentRequest true "Comment"
+// @Success 200 {object} models.Comment "Updated comment"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /comments/{id} [put]
+func (h *TaskHandler) UpdateComment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	comment, err := h.comments.Update(c.Request.Context(), models.Comment{
+		ID:   id.String(),
+		Body: req.Body,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to update comment: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"comment": comment}, http.StatusOK, nil)
+}
+
+// @Summary Deleting a comment
+// @Description Handles request to delete a comment.
+// @Tags comment
+// @Param id path string true "Comment ID"
+// @Param X-User-ID header string true "Author ID"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /comments/{id} [delete]
+func (h *TaskHandler) DeleteComment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.comments.Delete(c.Request.Context(), id.String()); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete comment: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Receiving comment history
+// @Description Handles request to get previous versions of a comment.
+// @Tags comment
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {array} models.CommentEdit "edits"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /comments/{id}/history [get]
+func (h *TaskHandler) GetCommentHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	edits, err := h.comments.History(c.Request.Context(), id.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to receive comment history: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"edits": edits}, http.StatusOK, nil)
+}
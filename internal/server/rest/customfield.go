@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomFieldServise interface {
+	List(ctx context.Context, projectID string) ([]models.FieldDefinition, error)
+	Create(ctx context.Context, field models.FieldDefinition) (models.FieldDefinition, error)
+	Delete(ctx context.Context, projectID string, name string) error
+}
+
+// WithCustomFields enables the custom field definition endpoints.
+func (h *TaskHandler) WithCustomFields(svc CustomFieldServise) *TaskHandler {
+	h.fields = svc
+	return h
+}
+
+func (h *TaskHandler) registerCustomFieldRoutes() {
+	h.router.GET("/projects/:id/fields", h.ListCustomFields)
+	h.router.POST("/projects/:id/fields", h.CreateCustomField)
+	h.router.DELETE("/projects/:id/fields/:name", h.DeleteCustomField)
+}
+
+type FieldRequest struct {
+	Name     string           `validate:"required,max=100" example:"severity"`
+	Type     models.FieldType `validate:"required,oneof=string number date enum user"`
+	Options  []string         `validate:"omitempty,dive,required,max=200"`
+	Required bool
+}
+
+// @Summary Listing custom fields
+// @Description Handles request to get the custom fields defined in a project.
+// @Tags project
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {array} models.FieldDefinition "fields"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id}/fields [get]
+func (h *TaskHandler) ListCustomFields(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	fields, err := h.fields.List(c.Request.Context(), id.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list custom fields: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"fields": fields}, http.StatusOK, nil)
+}
+
+// @Summary Defining a custom field
+// @Description Handles request to define a custom field of the project tasks. Only the owner may define fields.
+// @Description Values of date fields are formatted as 2006-01-02, user fields hold a user ID.
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body FieldRequest true "Field"
+// @Success 201 {object} models.FieldDefinition "Created field"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /projects/{id}/fields [post]
+func (h *TaskHandler) CreateCustomField(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req FieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	field, err := h.fields.Create(c.Request.Context(), models.FieldDefinition{
+		ProjectID: id.String(),
+		Name:      req.Name,
+		Type:      req.Type,
+		Options:   req.Options,
+		Required:  req.Required,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create custom field: %w", err))
+		return
+	}
+
+	h.Response(c, field, http.StatusCreated, nil)
+}
+
+// @Summary Deleting a custom field
+// @Description Handles request to delete a custom field together with its values. Only the owner may delete fields.
+// @Tags project
+// @Param id path string true "Project ID"
+// @Param name path string true "Field name"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id}/fields/{name} [delete]
+func (h *TaskHandler) DeleteCustomField(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.fields.Delete(c.Request.Context(), id.String(), c.Param("name")); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete custom field: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
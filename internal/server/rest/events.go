@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval keeps idle event streams from being closed by proxies.
+const heartbeatInterval = 15 * time.Second
+
+// @Summary Streaming task changes
+// @Description Streams created, updated and deleted events of the tasks matching the filter as Server-Sent Events.
+// @Description Every event has an ID, a stream is resumed after the event passed in the Last-Event-ID header.
+// @Description Idle streams receive a comment as a heartbeat.
+// @Tags task
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Param title query string false "Title"
+// @Param description query string false "Description"
+// @Param status query string false "Status"
+// @Param owner_id query string false "Owner ID"
+// @Param assigned_to query string false "Assignee ID, or me for the authenticated user"
+// @Param project_id query string false "Project ID"
+// @Param cf[name] query string false "Custom field value, e.g. cf[severity]=high"
+// @Param include_archived query bool false "Stream archived tasks too"
+// @Param label query string false "Label"
+// @Param parent_id query string false "Parent task ID"
+// @Success 200 {object} models.TaskEvent "event"
+// @Failure 400
+// @Failure 410 "The events after Last-Event-ID are no longer available"
+// @Failure 500
+// @Router /task/events [get]
+func (h *TaskHandler) TaskEvents(c *gin.Context) {
+	var filter models.TaskFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	filter.CustomFields = c.QueryMap("cf")
+
+	if err := h.validate.Struct(filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	var lastID uint64
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		var err error
+		lastID, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid Last-Event-ID: %w", err))
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	events, err := h.service.Watch(ctx, filter, lastID)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to watch tasks: %w", err))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.log.Debug().Msg("event stream client disconnected")
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// the client fell behind, it reconnects with the Last-Event-ID
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.log.Error().Err(err).Msgf("failed to encode event: %d", event.ID)
+				return
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Receiving task history
+// @Description Handles request to get the revisions of a task, the oldest first.
+// @Description Every revision lists the changed fields with their old and new values.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.Revision "history"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/history [get]
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	history, err := h.service.History(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to receive task history: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"history": history}, http.StatusOK, nil)
+}
+
+// @Summary Reverting a revision
+// @Description Handles request to set the fields changed by a revision back to their old values.
+// @Description Fails with 409 if any of the fields has been changed since.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param revision path string true "Revision ID"
+// @Success 200 {object} models.Task "Reverted task"
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /task/{id}/revert/{revision} [post]
+func (h *TaskHandler) RevertTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+	revisionID, err := uuid.Parse(c.Param("revision"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid revision UUID: %w", err))
+		return
+	}
+
+	task, err := h.service.Revert(c.Request.Context(), id, revisionID)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to revert task: %w", err))
+		return
+	}
+
+	h.Response(c, task, http.StatusOK, nil)
+}
@@ -0,0 +1,407 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProjectServise interface {
+	Create(ctx context.Context, project models.Project) (models.Project, error)
+	Get(ctx context.Context, id string) (models.Project, error)
+	List(ctx context.Context) ([]models.Project, error)
+	Update(ctx context.Context, project models.Project) (models.Project, error)
+	Delete(ctx context.Context, id string) error
+	Tasks(ctx context.Context, id string, filter models.TaskFilter) ([]models.Task, error)
+	Members(ctx context.Context, id string) ([]models.ProjectMember, error)
+	AddMember(ctx context.Context, id string, userID string) (models.ProjectMember, error)
+	RemoveMember(ctx context.Context, id string, userID string) error
+}
+
+// WithProjects enables the project endpoints.
+func (h *TaskHandler) WithProjects(svc ProjectServise) *TaskHandler {
+	h.projects = svc
+	return h
+}
+
+func (h *TaskHandler) registerProjectRoutes() {
+	projects := h.router.Group("/projects")
+	{
+		projects.POST("/", h.CreateProject)
+		projects.GET("/", h.ListProjects)
+		projects.GET("/:id", h.GetProject)
+		projects.PUT("/:id", h.UpdateProject)
+		projects.DELETE("/:id", h.DeleteProject)
+		projects.GET("/:id/tasks", h.ListProjectTasks)
+		projects.GET("/:id/members", h.ListProjectMembers)
+		projects.PUT("/:id/members/:user_id", h.AddProjectMember)
+		projects.DELETE("/:id/members/:user_id", h.RemoveProjectMember)
+	}
+}
+
+type ProjectRequest struct {
+	Name        string `validate:"required,max=200"`
+	Description string
+}
+
+type MoveRequest struct {
+	ProjectID string `validate:"required,uuid4"`
+}
+
+// @Summary Creating a project
+// @Description Handles request to create a project owned by the authenticated user.
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param request body ProjectRequest true "New project"
+// @Success 201 {object} models.Project "Created project"
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /projects/ [post]
+func (h *TaskHandler) CreateProject(c *gin.Context) {
+	var req ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	project, err := h.projects.Create(c.Request.Context(), models.Project{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create project: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"project": project}, http.StatusCreated, nil)
+}
+
+// @Summary Listing projects
+// @Description Handles request to get the projects the authenticated user is a member of.
+// @Tags project
+// @Produce json
+// @Success 200 {array} models.Project "projects"
+// @Failure 401
+// @Failure 500
+// @Router /projects/ [get]
+func (h *TaskHandler) ListProjects(c *gin.Context) {
+	projects, err := h.projects.List(c.Request.Context())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list projects: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"projects": projects}, http.StatusOK, nil)
+}
+
+// @Summary Receiving a project
+// @Description Handles request to get a project of the authenticated user.
+// @Tags project
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} models.Project "project"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id} [get]
+func (h *TaskHandler) GetProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	project, err := h.projects.Get(c.Request.Context(), id.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to receive project: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"project": project}, http.StatusOK, nil)
+}
+
+// @Summary Updating a project
+// @Description Handles request to rename a project. Only the owner may edit it.
+// @Tags project
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body ProjectRequest true "fields"
+// @Success 200 {object} models.Project "Updated project"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id} [put]
+func (h *TaskHandler) UpdateProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	project, err := h.projects.Update(c.Request.Context(), models.Project{
+		ID:          id.String(),
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to update project: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"project": project}, http.StatusOK, nil)
+}
+
+// @Summary Deleting a project
+// @Description Handles request to delete a project. Its tasks are kept without a project. Only the owner may delete it.
+// @Tags project
+// @Param id path string true "Project ID"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id} [delete]
+func (h *TaskHandler) DeleteProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.projects.Delete(c.Request.Context(), id.String()); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete project: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Listing project tasks
+// @Description Handles request to get the tasks of a project matching the filter.
+// @Tags project
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param title query string false "Title"
+// @Param description query string false "Description"
+// @Param status query string false "Status"
+// @Param owner_id query string false "Owner ID"
+// @Param assigned_to query string false "Assignee ID, or me for the authenticated user"
+// @Param cf[name] query string false "Custom field value, e.g. cf[severity]=high"
+// @Param include_archived query bool false "List archived tasks too"
+// @Success 200 {array} models.Task "tasks"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id}/tasks [get]
+func (h *TaskHandler) ListProjectTasks(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var filter models.TaskFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	filter.CustomFields = c.QueryMap("cf")
+	if err := h.validate.Struct(filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	tasks, err := h.projects.Tasks(c.Request.Context(), id.String(), filter)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list project tasks: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"tasks": tasks}, http.StatusOK, nil)
+}
+
+// @Summary Listing project members
+// @Description Handles request to get the members of a project.
+// @Tags project
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {array} models.ProjectMember "members"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /projects/{id}/members [get]
+func (h *TaskHandler) ListProjectMembers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	members, err := h.projects.Members(c.Request.Context(), id.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list project members: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"members": members}, http.StatusOK, nil)
+}
+
+// @Summary Adding a project member
+// @Description Handles request to add a user to a project. Only the owner may add members.
+// @Tags project
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Success 201 {object} models.ProjectMember "Added member"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /projects/{id}/members/{user_id} [put]
+func (h *TaskHandler) AddProjectMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid user UUID: %w", err))
+		return
+	}
+
+	member, err := h.projects.AddMember(c.Request.Context(), id.String(), userID.String())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to add project member: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"member": member}, http.StatusCreated, nil)
+}
+
+// @Summary Removing a project member
+// @Description Handles request to remove a user from a project. The owner may remove any member, other members may only leave.
+// @Tags project
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /projects/{id}/members/{user_id} [delete]
+func (h *TaskHandler) RemoveProjectMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid user UUID: %w", err))
+		return
+	}
+
+	if err := h.projects.RemoveMember(c.Request.Context(), id.String(), userID.String()); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to remove project member: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Moving a task to a project
+// @Description Handles request to move a task to a project the authenticated user is a member of. The change is recorded in the task history.
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body MoveRequest true "Target project"
+// @Success 200 {object} models.Task "Updated task"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/project [put]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	task, err := h.service.Move(c.Request.Context(), id, req.ProjectID)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to move task: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"task": task}, http.StatusOK, nil)
+}
+
+// @Summary Removing a task from its project
+// @Description Handles request to take a task out of its project. The change is recorded in the task history.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.Task "Updated task"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/project [delete]
+func (h *TaskHandler) RemoveTaskFromProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	task, err := h.service.Move(c.Request.Context(), id, "")
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to remove task from project: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"task": task}, http.StatusOK, nil)
+}
@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OccurrencesQuery struct {
+	Count int `form:"count" validate:"omitempty,min=1,max=100"`
+}
+
+const defaultOccurrences = 5
+
+// @Summary Previewing occurrences
+// @Description Handles request to get the next due dates of a recurring task.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param count query int false "Number of occurrences, 5 by default"
+// @Success 200 {array} string "due dates"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/occurrences [get]
+func (h *TaskHandler) PreviewOccurrences(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var query OccurrencesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid count: %w", err))
+		return
+	}
+	if query.Count == 0 {
+		query.Count = defaultOccurrences
+	}
+
+	occurrences, err := h.service.Occurrences(c.Request.Context(), id, query.Count)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to preview occurrences: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"occurrences": occurrences}, http.StatusOK, nil)
+}
@@ -2,11 +2,15 @@ package rest
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	_ "github.com/VikaPaz/task_tracker/docs"
+	"github.com/VikaPaz/task_tracker/internal/auth"
 	"github.com/VikaPaz/task_tracker/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,10 +21,21 @@ import (
 )
 
 type TaskHandler struct {
-	router   *gin.Engine
-	service  TaskServise
-	validate *validator.Validate
-	log      *zerolog.Logger
+	router      *gin.Engine
+	service     TaskServise
+	workflow    WorkflowServise
+	comments    CommentServise
+	attachments AttachmentServise
+	projects    ProjectServise
+	worklogs    WorkLogServise
+	checklists  ChecklistServise
+	fields      CustomFieldServise
+	templates   TemplateServise
+	watchers    WatcherServise
+	users       UserServise
+	webhooks    WebhookServise
+	validate    *validator.Validate
+	log         *zerolog.Logger
 }
 
 type TaskServise interface {
@@ -29,6 +44,20 @@ type TaskServise interface {
 	Update(ctx context.Context, req models.Task) (models.Task, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error)
+	Assign(ctx context.Context, id uuid.UUID, assigneeID string) (models.Task, error)
+	Unassign(ctx context.Context, id uuid.UUID) (models.Task, error)
+	Move(ctx context.Context, id uuid.UUID, projectID string) (models.Task, error)
+	History(ctx context.Context, id uuid.UUID) ([]models.Revision, error)
+	Revert(ctx context.Context, id uuid.UUID, revisionID uuid.UUID) (models.Task, error)
+	Occurrences(ctx context.Context, id uuid.UUID, n int) ([]time.Time, error)
+	Trash(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	Restore(ctx context.Context, id uuid.UUID) (models.Task, error)
+	Purge(ctx context.Context, id uuid.UUID) error
+	Archive(ctx context.Context, id uuid.UUID) (models.Task, error)
+	Unarchive(ctx context.Context, id uuid.UUID) (models.Task, error)
+	Watch(ctx context.Context, filter models.TaskFilter, afterID uint64) (<-chan models.TaskEvent, error)
+	Sync(ctx context.Context, token string) (models.TaskChanges, error)
 }
 
 func NewTaskHandler(svc TaskServise, log *zerolog.Logger) *TaskHandler {
@@ -43,6 +72,8 @@ func NewTaskHandler(svc TaskServise, log *zerolog.Logger) *TaskHandler {
 }
 
 func (h *TaskHandler) registerRoutes() {
+	h.router.Use(h.identify)
+
 	tasks := h.router.Group("/task")
 	{
 		tasks.POST("/", h.CreateTask)
@@ -50,8 +81,98 @@ func (h *TaskHandler) registerRoutes() {
 		tasks.PUT("/", h.UpdateTask)
 		tasks.DELETE("/:id", h.DeleteTask)
 		tasks.GET("/", h.ListTasks)
+		tasks.GET("/stats", h.GetTaskStats)
+		tasks.GET("/events", h.TaskEvents)
+		tasks.PUT("/:id/assignee", h.AssignTask)
+		tasks.DELETE("/:id/assignee", h.UnassignTask)
+		tasks.PUT("/:id/project", h.MoveTask)
+		tasks.DELETE("/:id/project", h.RemoveTaskFromProject)
+		tasks.GET("/:id/history", h.GetTaskHistory)
+		tasks.POST("/:id/revert/:revision", h.RevertTask)
+		tasks.GET("/:id/occurrences", h.PreviewOccurrences)
+		tasks.POST("/:id/restore", h.RestoreTask)
+		tasks.POST("/:id/archive", h.ArchiveTask)
+		tasks.POST("/:id/unarchive", h.UnarchiveTask)
+	}
+	trash := h.router.Group("/trash")
+	{
+		trash.GET("/", h.ListTrash)
+		trash.DELETE("/:id", h.PurgeTask)
+	}
+	h.router.GET("/sync", h.SyncTasks)
+	if h.workflow != nil {
+		h.registerWorkflowRoutes()
+	}
+	if h.comments != nil {
+		h.registerCommentRoutes()
+	}
+	if h.attachments != nil {
+		h.registerAttachmentRoutes()
+	}
+	if h.projects != nil {
+		h.registerProjectRoutes()
+	}
+	if h.worklogs != nil {
+		h.registerWorkLogRoutes()
+	}
+	if h.checklists != nil {
+		h.registerChecklistRoutes()
+	}
+	if h.fields != nil {
+		h.registerCustomFieldRoutes()
+	}
+	if h.templates != nil {
+		h.registerTemplateRoutes()
+	}
+	if h.watchers != nil {
+		h.registerWatcherRoutes()
+	}
+	if h.users != nil {
+		h.registerUserRoutes()
+	}
+	if h.webhooks != nil {
+		h.registerWebhookRoutes()
 	}
 	h.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	h.router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	h.router.NoRoute(h.unavailable)
+}
+
+// unavailable answers the routes of the features left out of the handler,
+// as the storage doesn't keep them, with 501 instead of a plain 404.
+func (h *TaskHandler) unavailable(c *gin.Context) {
+	segments := strings.Split(strings.Trim(c.Request.URL.Path, "/"), "/")
+	enabled := map[string]bool{
+		"workflow":    h.workflow != nil,
+		"comments":    h.comments != nil,
+		"attachments": h.attachments != nil,
+		"projects":    h.projects != nil,
+		"worklogs":    h.worklogs != nil,
+		"timer":       h.worklogs != nil,
+		"checklist":   h.checklists != nil,
+		"templates":   h.templates != nil,
+		"users":       h.users != nil,
+		"webhooks":    h.webhooks != nil,
+	}
+	feature := segments[0]
+	if feature == "task" && len(segments) > 2 {
+		// the routes of a task, /task/:id/<feature>
+		feature = segments[2]
+		enabled = map[string]bool{
+			"comments":    h.comments != nil,
+			"attachments": h.attachments != nil,
+			"checklist":   h.checklists != nil,
+			"worklogs":    h.worklogs != nil,
+			"timer":       h.worklogs != nil,
+			"watchers":    h.watchers != nil,
+			"watch":       h.watchers != nil,
+			"unwatch":     h.watchers != nil,
+		}
+	}
+	if on, ok := enabled[feature]; ok && !on {
+		h.Response(c, nil, http.StatusNotImplemented, fmt.Errorf("the %s routes aren't available with this storage", feature))
+	}
+	// gin answers 404 to the rest
 }
 
 func Run(server *TaskHandler, serverPort string) {
@@ -72,6 +193,62 @@ func (h *TaskHandler) Response(
 	c.JSON(status, responseBody)
 }
 
+// errorStatus maps domain errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound),
+		errors.Is(err, models.ErrTransitionNotFound),
+		errors.Is(err, models.ErrCommentNotFound),
+		errors.Is(err, models.ErrAttachmentNotFound),
+		errors.Is(err, models.ErrProjectNotFound),
+		errors.Is(err, models.ErrMemberNotFound),
+		errors.Is(err, models.ErrTimerNotFound),
+		errors.Is(err, models.ErrChecklistItemNotFound),
+		errors.Is(err, models.ErrFieldNotFound),
+		errors.Is(err, models.ErrRevisionNotFound),
+		errors.Is(err, models.ErrTemplateNotFound),
+		errors.Is(err, models.ErrWebhookNotFound),
+		errors.Is(err, models.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrStatusNotFound),
+		errors.Is(err, models.ErrChecksumMismatch),
+		errors.Is(err, models.ErrInvalidRRule),
+		errors.Is(err, models.ErrDueRequired),
+		errors.Is(err, models.ErrNotRecurring),
+		errors.Is(err, models.ErrInvalidRange),
+		errors.Is(err, models.ErrInvalidOrder),
+		errors.Is(err, models.ErrInvalidField),
+		errors.Is(err, models.ErrProjectRequired),
+		errors.Is(err, models.ErrParentNotFound),
+		errors.Is(err, models.ErrMissingVariable),
+		errors.Is(err, models.ErrInvalidUsername),
+		errors.Is(err, models.ErrInvalidSyncToken):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, models.ErrEventsExpired),
+		errors.Is(err, models.ErrSyncTokenExpired):
+		return http.StatusGone
+	case errors.Is(err, models.ErrTransitionNotAllowed),
+		errors.Is(err, models.ErrStatusExists),
+		errors.Is(err, models.ErrStatusInUse),
+		errors.Is(err, models.ErrMemberExists),
+		errors.Is(err, models.ErrProjectOwner),
+		errors.Is(err, models.ErrTimerRunning),
+		errors.Is(err, models.ErrFieldExists),
+		errors.Is(err, models.ErrNotRevertible),
+		errors.Is(err, models.ErrRevertConflict),
+		errors.Is(err, models.ErrUsernameTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *TaskHandler) logRequest(c *gin.Context, status int, err error) {
 	logger := h.log.Info()
 
@@ -86,19 +263,61 @@ func (h *TaskHandler) logRequest(c *gin.Context, status int, err error) {
 		Int("status", status)
 }
 
+// identify stores the user ID passed in the auth.Header into the request context.
+func (h *TaskHandler) identify(c *gin.Context) {
+	raw := c.GetHeader(auth.Header)
+	if raw == "" {
+		c.Next()
+		return
+	}
+
+	userID, err := auth.Parse(raw)
+	if err != nil {
+		h.Response(c, nil, http.StatusUnauthorized, fmt.Errorf("invalid %s header: %w", auth.Header, err))
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), userID))
+	c.Next()
+}
+
 // TODO: add auth
 func genOwner() string {
 	return uuid.New().String()
 }
 
+// currentOwner returns the authenticated user, or a generated owner for anonymous requests.
+func currentOwner(c *gin.Context) string {
+	if userID, ok := auth.UserID(c.Request.Context()); ok {
+		return userID
+	}
+	return genOwner()
+}
+
 type CreateRequest struct {
 	ID          string `swaggerignore:"true" validate:"omitempty,uuid4"`
 	Title       string
 	Description string
 	Created     time.Time         `swaggerignore:"true"`
 	Updated     time.Time         `swaggerignore:"true"`
-	Status      models.TaskStatus `validate:"omitempty,oneof=in_progress done"`
+	Status      models.TaskStatus `validate:"omitempty,max=100"`
 	OwnerID     string            `swaggerignore:"true" validate:"uuid4"`
+	AssigneeID  string            `validate:"omitempty,uuid4"`
+	Due         time.Time
+	RRule       string `example:"FREQ=WEEKLY;BYDAY=MO"`
+	ProjectID   string `validate:"omitempty,uuid4"`
+
+	EstimateMinutes int `validate:"min=0,max=525600"`
+	StoryPoints     int `validate:"min=0,max=100"`
+
+	ChecklistProgress models.ChecklistProgress `swaggerignore:"true"`
+	CustomFields      map[string]any
+	Deleted           time.Time `swaggerignore:"true"`
+	Archived          time.Time `swaggerignore:"true"`
+	ParentID          string    `validate:"omitempty,uuid4"`
+	Labels            []string  `validate:"omitempty,max=20,dive,required,max=50"`
+	Watchers          []string  `swaggerignore:"true"`
 }
 
 // @Summary Creating a new task
@@ -121,11 +340,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	task := models.Task(req)
 
-	task.OwnerID = genOwner()
-
-	if task.Status == "" {
-		task.Status = models.InProgress
-	}
+	task.OwnerID = currentOwner(c)
 
 	var err error
 	err = h.validate.Struct(task)
@@ -137,7 +352,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	task, err = h.service.Create(c.Request.Context(), task)
 	if err != nil {
-		h.Response(c, nil, http.StatusInternalServerError, fmt.Errorf("failed to create task: %w", err))
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create task: %w", err))
 		return
 	}
 	h.Response(c, gin.H{"task": task}, http.StatusCreated, nil)
@@ -163,10 +378,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 
 	task, err := h.service.Get(c.Request.Context(), id)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == models.ErrTaskNotFound {
-			status = http.StatusNotFound
-		}
+		status := errorStatus(err)
 		h.Response(c, nil, status, fmt.Errorf("failed to receive task: %w", err))
 		return
 	}
@@ -180,8 +392,23 @@ type UpdateRequest struct {
 	Description string
 	Created     time.Time         `swaggerignore:"true"`
 	Updated     time.Time         `swaggerignore:"true"`
-	Status      models.TaskStatus `validate:"omitempty,oneof=in_progress done"`
-	OwnerID     string            `validate:"uuid4"`
+	Status      models.TaskStatus `validate:"omitempty,max=100"`
+	OwnerID     string            `swaggerignore:"true"`
+	AssigneeID  string            `swaggerignore:"true"`
+	Due         time.Time
+	RRule       string `example:"FREQ=WEEKLY;BYDAY=MO"`
+	ProjectID   string `swaggerignore:"true"`
+
+	EstimateMinutes int `validate:"min=0,max=525600"`
+	StoryPoints     int `validate:"min=0,max=100"`
+
+	ChecklistProgress models.ChecklistProgress `swaggerignore:"true"`
+	CustomFields      map[string]any
+	Deleted           time.Time `swaggerignore:"true"`
+	Archived          time.Time `swaggerignore:"true"`
+	ParentID          string    `swaggerignore:"true"`
+	Labels            []string  `validate:"omitempty,max=20,dive,required,max=50"`
+	Watchers          []string  `swaggerignore:"true"`
 }
 
 // @Summary Updating a task
@@ -193,6 +420,7 @@ type UpdateRequest struct {
 // @Success 200 {object} models.Task "Updated task"
 // @Failure 400
 // @Failure 404
+// @Failure 409
 // @Failure 500
 // @Router /task/ [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
@@ -205,7 +433,11 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 
 	task := models.Task(req)
 
-	task.OwnerID = genOwner()
+	// the owner is kept from creation, assignment and project are changed
+	// only with their own endpoints
+	task.OwnerID = ""
+	task.AssigneeID = ""
+	task.ProjectID = ""
 	_, err := uuid.Parse(task.ID)
 	if err != nil {
 		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
@@ -221,10 +453,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 
 	updatedTask, err := h.service.Update(c.Request.Context(), task)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == models.ErrTaskNotFound {
-			status = http.StatusNotFound
-		}
+		status := errorStatus(err)
 		h.Response(c, nil, status, fmt.Errorf("failed to update task: %w", err))
 		return
 	}
@@ -233,7 +462,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 }
 
 // @Summary Deleting a task
-// @Description Handles request to delete a task.
+// @Description Handles request to move a task to the trash, it can be restored until it is purged.
 // @Tags task
 // @Param id path string false "Task ID"
 // @Success 204
@@ -250,10 +479,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	}
 
 	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		status := http.StatusInternalServerError
-		if err == models.ErrTaskNotFound {
-			status = http.StatusNotFound
-		}
+		status := errorStatus(err)
 		h.Response(c, nil, status, fmt.Errorf("failed to delete task: %w", err))
 		return
 	}
@@ -270,6 +496,12 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 // @Param description query string false "Description"
 // @Param status query string false "Status"
 // @Param owner_id query string false "Owner ID"
+// @Param assigned_to query string false "Assignee ID, or me for the authenticated user"
+// @Param project_id query string false "Project ID"
+// @Param cf[name] query string false "Custom field value, e.g. cf[severity]=high"
+// @Param include_archived query bool false "List archived tasks too"
+// @Param label query string false "Label"
+// @Param parent_id query string false "Parent task ID"
 // @Success 200 {object} models.Task "task"
 // @Failure 400
 // @Failure 404
@@ -282,6 +514,7 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
 		return
 	}
+	filter.CustomFields = c.QueryMap("cf")
 
 	if err := h.validate.Struct(filter); err != nil {
 		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
@@ -291,13 +524,51 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 
 	tasks, err := h.service.List(c.Request.Context(), filter)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == models.ErrTaskNotFound {
-			status = http.StatusNotFound
-		}
+		status := errorStatus(err)
 		h.Response(c, nil, status, fmt.Errorf("failed to list tasks: %w", err))
 		return
 	}
 
 	h.Response(c, gin.H{"tasks": tasks}, http.StatusOK, nil)
 }
+
+// @Summary Receiving task statistics
+// @Description Handles request to roll up story points, estimates and remaining work of the tasks matching the filter.
+// @Description Remaining minutes are the estimates of open tasks minus the logged time.
+// @Tags task
+// @Produce json
+// @Param group_by query string false "status (default), owner or project"
+// @Param title query string false "Title"
+// @Param description query string false "Description"
+// @Param status query string false "Status"
+// @Param owner_id query string false "Owner ID"
+// @Param assigned_to query string false "Assignee ID, or me for the authenticated user"
+// @Param project_id query string false "Project ID"
+// @Param cf[name] query string false "Custom field value, e.g. cf[severity]=high"
+// @Param include_archived query bool false "List archived tasks too"
+// @Success 200 {array} models.TaskStats "stats"
+// @Failure 400
+// @Failure 500
+// @Router /task/stats [get]
+func (h *TaskHandler) GetTaskStats(c *gin.Context) {
+	var filter models.TaskStatsFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	filter.CustomFields = c.QueryMap("cf")
+
+	if err := h.validate.Struct(filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid filter: %w", err))
+		return
+	}
+
+	stats, err := h.service.Stats(c.Request.Context(), filter)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to receive task stats: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"stats": stats}, http.StatusOK, nil)
+}
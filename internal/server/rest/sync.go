@@ -0,0 +1,30 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Syncing tasks
+// @Description Handles request to get the tasks created or updated after the sync token and the IDs of the deleted ones.
+// @Description Pass the returned Token to the next sync, More tells that further changes follow right away.
+// @Description Without a token every task is returned.
+// @Tags sync
+// @Produce json
+// @Param since query string false "Sync token of the previous sync"
+// @Success 200 {object} models.TaskChanges "changes"
+// @Failure 400
+// @Failure 410 "The changes after the token are no longer available, sync again without a token"
+// @Failure 500
+// @Router /sync [get]
+func (h *TaskHandler) SyncTasks(c *gin.Context) {
+	changes, err := h.service.Sync(c.Request.Context(), c.Query("since"))
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to sync tasks: %w", err))
+		return
+	}
+
+	h.Response(c, changes, http.StatusOK, nil)
+}
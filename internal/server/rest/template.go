@@ -0,0 +1,237 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TemplateServise interface {
+	Create(ctx context.Context, template models.Template) (models.Template, error)
+	Get(ctx context.Context, id uuid.UUID) (models.Template, error)
+	List(ctx context.Context) ([]models.Template, error)
+	Update(ctx context.Context, template models.Template) (models.Template, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Instantiate(ctx context.Context, id uuid.UUID, projectID string, vars map[string]string) ([]models.Task, error)
+}
+
+// WithTemplates enables the task template endpoints.
+func (h *TaskHandler) WithTemplates(svc TemplateServise) *TaskHandler {
+	h.templates = svc
+	return h
+}
+
+func (h *TaskHandler) registerTemplateRoutes() {
+	templates := h.router.Group("/templates")
+	{
+		templates.POST("/", h.CreateTemplate)
+		templates.GET("/", h.ListTemplates)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+		templates.POST("/:id/instantiate", h.InstantiateTemplate)
+	}
+}
+
+type TemplateRequest struct {
+	Name string `validate:"required,max=200" example:"Onboarding"`
+	Root models.TemplateTask
+}
+
+type InstantiateRequest struct {
+	ProjectID string            `validate:"omitempty,uuid4"`
+	Variables map[string]string `example:"customer:ACME"`
+}
+
+// @Summary Creating a template
+// @Description Handles request to create a task template owned by the user.
+// @Description Title, description, labels and checklist items may contain {{variable}} placeholders.
+// @Tags template
+// @Accept json
+// @Produce json
+// @Param request body TemplateRequest true "Template"
+// @Success 201 {object} models.Template "Created template"
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /templates/ [post]
+func (h *TaskHandler) CreateTemplate(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	template, err := h.templates.Create(c.Request.Context(), models.Template{
+		Name: req.Name,
+		Root: req.Root,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create template: %w", err))
+		return
+	}
+
+	h.Response(c, template, http.StatusCreated, nil)
+}
+
+// @Summary Listing templates
+// @Description Handles request to get all task templates.
+// @Tags template
+// @Produce json
+// @Success 200 {array} models.Template "templates"
+// @Failure 500
+// @Router /templates/ [get]
+func (h *TaskHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templates.List(c.Request.Context())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list templates: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"templates": templates}, http.StatusOK, nil)
+}
+
+// @Summary Getting a template
+// @Description Handles request to get a task template by ID.
+// @Tags template
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} models.Template "Template"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /templates/{id} [get]
+func (h *TaskHandler) GetTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	template, err := h.templates.Get(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to get template: %w", err))
+		return
+	}
+
+	h.Response(c, template, http.StatusOK, nil)
+}
+
+// @Summary Updating a template
+// @Description Handles request to replace the name and the tasks of a template. Only the owner may update it.
+// @Tags template
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param request body TemplateRequest true "Template"
+// @Success 200 {object} models.Template "Updated template"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /templates/{id} [put]
+func (h *TaskHandler) UpdateTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	template, err := h.templates.Update(c.Request.Context(), models.Template{
+		ID:   id.String(),
+		Name: req.Name,
+		Root: req.Root,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to update template: %w", err))
+		return
+	}
+
+	h.Response(c, template, http.StatusOK, nil)
+}
+
+// @Summary Deleting a template
+// @Description Handles request to delete a task template. Only the owner may delete it.
+// @Tags template
+// @Param id path string true "Template ID"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /templates/{id} [delete]
+func (h *TaskHandler) DeleteTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.templates.Delete(c.Request.Context(), id); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete template: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Instantiating a template
+// @Description Handles request to create the tasks of a template in one transaction, with the placeholders replaced by the variables.
+// @Description The user becomes the owner of the tasks; every variable used in the template has to be set.
+// @Tags template
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param request body InstantiateRequest true "Variables"
+// @Success 201 {array} models.Task "Created tasks, parents first"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /templates/{id}/instantiate [post]
+func (h *TaskHandler) InstantiateTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req InstantiateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	tasks, err := h.templates.Instantiate(c.Request.Context(), id, req.ProjectID, req.Variables)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to instantiate template: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"tasks": tasks}, http.StatusCreated, nil)
+}
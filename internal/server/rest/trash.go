@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Listing the trash
+// @Description Handles request to get the deleted tasks matching the filter, the most recently deleted first.
+// @Tags trash
+// @Produce json
+// @Param title query string false "Title"
+// @Param description query string false "Description"
+// @Param status query string false "Status"
+// @Param owner_id query string false "Owner ID"
+// @Param assigned_to query string false "Assignee ID, or me for the authenticated user"
+// @Param project_id query string false "Project ID"
+// @Success 200 {array} models.Task "tasks"
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /trash/ [get]
+func (h *TaskHandler) ListTrash(c *gin.Context) {
+	var filter models.TaskFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	if err := h.validate.Struct(filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid filter: %w", err))
+		return
+	}
+
+	tasks, err := h.service.Trash(c.Request.Context(), filter)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list trash: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"tasks": tasks}, http.StatusOK, nil)
+}
+
+// @Summary Restoring a task
+// @Description Handles request to take a deleted task out of the trash.
+// @Tags trash
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} models.Task "Restored task"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/restore [post]
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	task, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to restore task: %w", err))
+		return
+	}
+
+	h.Response(c, task, http.StatusOK, nil)
+}
+
+// @Summary Purging a task
+// @Description Handles request to permanently delete a task from the trash together with its comments, attachments and logs.
+// @Tags trash
+// @Param id path string true "Task ID"
+// @Success 204
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /trash/{id} [delete]
+func (h *TaskHandler) PurgeTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.service.Purge(c.Request.Context(), id); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to purge task: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type UserServise interface {
+	SetUsername(ctx context.Context, username string) (models.User, error)
+}
+
+// WithUsers enables the endpoint setting the usernames used in @mentions.
+func (h *TaskHandler) WithUsers(svc UserServise) *TaskHandler {
+	h.users = svc
+	return h
+}
+
+func (h *TaskHandler) registerUserRoutes() {
+	h.router.PUT("/users/me/username", h.SetUsername)
+}
+
+type UsernameRequest struct {
+	Username string `validate:"required" example:"jane_doe"`
+}
+
+// @Summary Setting the username
+// @Description Handles request to set the username of the user, other users @mention it in descriptions and comments.
+// @Description Usernames are 3-32 lowercase letters, digits or underscores.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body UsernameRequest true "Username"
+// @Success 200 {object} models.User "User"
+// @Failure 400
+// @Failure 401
+// @Failure 409
+// @Failure 500
+// @Router /users/me/username [put]
+func (h *TaskHandler) SetUsername(c *gin.Context) {
+	var req UsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	user, err := h.users.SetUsername(c.Request.Context(), req.Username)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to set username: %w", err))
+		return
+	}
+
+	h.Response(c, user, http.StatusOK, nil)
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WatcherServise interface {
+	List(ctx context.Context, taskID uuid.UUID) ([]string, error)
+	Watch(ctx context.Context, taskID uuid.UUID) ([]string, error)
+	Unwatch(ctx context.Context, taskID uuid.UUID) ([]string, error)
+}
+
+// WithWatchers enables the task watcher endpoints.
+func (h *TaskHandler) WithWatchers(svc WatcherServise) *TaskHandler {
+	h.watchers = svc
+	return h
+}
+
+func (h *TaskHandler) registerWatcherRoutes() {
+	h.router.GET("/task/:id/watchers", h.ListWatchers)
+	h.router.POST("/task/:id/watch", h.WatchTask)
+	h.router.POST("/task/:id/unwatch", h.UnwatchTask)
+}
+
+// @Summary Listing watchers
+// @Description Handles request to get the IDs of the users watching a task.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} string "watchers"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/watchers [get]
+func (h *TaskHandler) ListWatchers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	watchers, err := h.watchers.List(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list watchers: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"watchers": watchers}, http.StatusOK, nil)
+}
+
+// @Summary Watching a task
+// @Description Handles request to make the user watch a task.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} string "watchers"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/watch [post]
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	watchers, err := h.watchers.Watch(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to watch task: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"watchers": watchers}, http.StatusOK, nil)
+}
+
+// @Summary Unwatching a task
+// @Description Handles request to stop the user watching a task.
+// @Tags task
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} string "watchers"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/unwatch [post]
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	watchers, err := h.watchers.Unwatch(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to unwatch task: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"watchers": watchers}, http.StatusOK, nil)
+}
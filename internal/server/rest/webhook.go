@@ -0,0 +1,216 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookServise interface {
+	Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	List(ctx context.Context) ([]models.Webhook, error)
+	Get(ctx context.Context, id uuid.UUID) (models.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Deliveries(ctx context.Context, id uuid.UUID) ([]models.Delivery, error)
+	Redeliver(ctx context.Context, id uuid.UUID, deliveryID uuid.UUID) (models.Delivery, error)
+}
+
+// WithWebhooks enables the webhook endpoints.
+func (h *TaskHandler) WithWebhooks(svc WebhookServise) *TaskHandler {
+	h.webhooks = svc
+	return h
+}
+
+func (h *TaskHandler) registerWebhookRoutes() {
+	webhooks := h.router.Group("/webhooks")
+	{
+		webhooks.POST("/", h.CreateWebhook)
+		webhooks.GET("/", h.ListWebhooks)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.ListDeliveries)
+		webhooks.POST("/:id/deliveries/:delivery/redeliver", h.Redeliver)
+	}
+}
+
+type WebhookRequest struct {
+	URL        string             `validate:"required,http_url,max=2000" example:"https://example.com/hooks/tasks"`
+	Secret     string             `validate:"required,min=16,max=200"`
+	EventTypes []models.EventType `validate:"omitempty,dive,oneof=created updated deleted"`
+	Filter     models.TaskFilter
+}
+
+// @Summary Creating a webhook
+// @Description Handles request to subscribe a URL to the events of the tasks matching the filter, all event types when EventTypes is empty.
+// @Description Every event is POSTed as JSON with the X-Webhook-Signature header holding sha256= and the hex HMAC-SHA256 of the body keyed with the secret.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequest true "Webhook"
+// @Success 201 {object} models.Webhook "Created webhook"
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /webhooks/ [post]
+func (h *TaskHandler) CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	webhook, err := h.webhooks.Create(c.Request.Context(), models.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Filter:     req.Filter,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create webhook: %w", err))
+		return
+	}
+
+	h.Response(c, webhook, http.StatusCreated, nil)
+}
+
+// @Summary Listing webhooks
+// @Description Handles request to get the webhooks of the user.
+// @Tags webhook
+// @Produce json
+// @Success 200 {array} models.Webhook "webhooks"
+// @Failure 401
+// @Failure 500
+// @Router /webhooks/ [get]
+func (h *TaskHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhooks.List(c.Request.Context())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list webhooks: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"webhooks": webhooks}, http.StatusOK, nil)
+}
+
+// @Summary Getting a webhook
+// @Description Handles request to get a webhook of the user.
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.Webhook "Webhook"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id} [get]
+func (h *TaskHandler) GetWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	webhook, err := h.webhooks.Get(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to get webhook: %w", err))
+		return
+	}
+
+	h.Response(c, webhook, http.StatusOK, nil)
+}
+
+// @Summary Deleting a webhook
+// @Description Handles request to delete a webhook of the user together with its deliveries.
+// @Tags webhook
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id} [delete]
+func (h *TaskHandler) DeleteWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	if err := h.webhooks.Delete(c.Request.Context(), id); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete webhook: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Listing webhook deliveries
+// @Description Handles request to get the last 100 deliveries of a webhook with their status, attempts and the last response.
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {array} models.Delivery "deliveries"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id}/deliveries [get]
+func (h *TaskHandler) ListDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(c.Request.Context(), id)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list deliveries: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"deliveries": deliveries}, http.StatusOK, nil)
+}
+
+// @Summary Redelivering a webhook delivery
+// @Description Handles request to send the payload of a delivery again, as a new delivery.
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery path string true "Delivery ID"
+// @Success 202 {object} models.Delivery "New delivery"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 500
+// @Router /webhooks/{id}/deliveries/{delivery}/redeliver [post]
+func (h *TaskHandler) Redeliver(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	delivery, err := h.webhooks.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to redeliver: %w", err))
+		return
+	}
+
+	h.Response(c, delivery, http.StatusAccepted, nil)
+}
@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkflowServise interface {
+	Get(ctx context.Context) (models.Workflow, error)
+	CreateStatus(ctx context.Context, status models.WorkflowStatus) (models.WorkflowStatus, error)
+	DeleteStatus(ctx context.Context, name models.TaskStatus) error
+	CreateTransition(ctx context.Context, transition models.Transition) error
+	DeleteTransition(ctx context.Context, transition models.Transition) error
+}
+
+// WithWorkflow enables the workflow management endpoints.
+func (h *TaskHandler) WithWorkflow(svc WorkflowServise) *TaskHandler {
+	h.workflow = svc
+	return h
+}
+
+func (h *TaskHandler) registerWorkflowRoutes() {
+	workflow := h.router.Group("/workflow")
+	{
+		workflow.GET("/", h.GetWorkflow)
+		workflow.POST("/statuses", h.CreateStatus)
+		workflow.DELETE("/statuses/:name", h.DeleteStatus)
+		workflow.POST("/transitions", h.CreateTransition)
+		workflow.DELETE("/transitions/:from/:to", h.DeleteTransition)
+	}
+}
+
+// @Summary Receiving the workflow
+// @Description Handles request to get registered statuses and allowed transitions between them.
+// @Tags workflow
+// @Produce json
+// @Success 200 {object} models.Workflow "workflow"
+// @Failure 500
+// @Router /workflow/ [get]
+func (h *TaskHandler) GetWorkflow(c *gin.Context) {
+	workflow, err := h.workflow.Get(c.Request.Context())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to receive workflow: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"workflow": workflow}, http.StatusOK, nil)
+}
+
+// @Summary Creating a status
+// @Description Handles request to register a new task status.
+// @Tags workflow
+// @Accept json
+// @Produce json
+// @Param request body models.WorkflowStatus true "New status"
+// @Success 201 {object} models.WorkflowStatus "Created status"
+// @Failure 400
+// @Failure 409
+// @Failure 500
+// @Router /workflow/statuses [post]
+func (h *TaskHandler) CreateStatus(c *gin.Context) {
+	var status models.WorkflowStatus
+
+	if err := c.ShouldBindJSON(&status); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	if err := h.validate.Struct(status); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	status, err := h.workflow.CreateStatus(c.Request.Context(), status)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create status: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"status": status}, http.StatusCreated, nil)
+}
+
+// @Summary Deleting a status
+// @Description Handles request to delete a status which isn't used by any task.
+// @Tags workflow
+// @Param name path string true "Status name"
+// @Success 204
+// @Failure 400
+// @Failure 409
+// @Failure 500
+// @Router /workflow/statuses/{name} [delete]
+func (h *TaskHandler) DeleteStatus(c *gin.Context) {
+	name := models.TaskStatus(c.Param("name"))
+
+	if err := h.workflow.DeleteStatus(c.Request.Context(), name); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete status: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Allowing a transition
+// @Description Handles request to allow moving tasks from one status to another.
+// @Tags workflow
+// @Accept json
+// @Param request body models.Transition true "Transition"
+// @Success 204
+// @Failure 400
+// @Failure 500
+// @Router /workflow/transitions [post]
+func (h *TaskHandler) CreateTransition(c *gin.Context) {
+	var transition models.Transition
+
+	if err := c.ShouldBindJSON(&transition); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	if err := h.validate.Struct(transition); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	if err := h.workflow.CreateTransition(c.Request.Context(), transition); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to create transition: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
+
+// @Summary Forbidding a transition
+// @Description Handles request to remove an allowed transition.
+// @Tags workflow
+// @Param from path string true "Current status"
+// @Param to path string true "Target status"
+// @Success 204
+// @Failure 404
+// @Failure 500
+// @Router /workflow/transitions/{from}/{to} [delete]
+func (h *TaskHandler) DeleteTransition(c *gin.Context) {
+	transition := models.Transition{
+		From: models.TaskStatus(c.Param("from")),
+		To:   models.TaskStatus(c.Param("to")),
+	}
+
+	if err := h.workflow.DeleteTransition(c.Request.Context(), transition); err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to delete transition: %w", err))
+		return
+	}
+
+	h.Response(c, nil, http.StatusNoContent, nil)
+}
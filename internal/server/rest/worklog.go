@@ -0,0 +1,314 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WorkLogServise interface {
+	Start(ctx context.Context, taskID uuid.UUID, note string) (models.WorkLog, error)
+	Stop(ctx context.Context) (models.WorkLog, error)
+	Running(ctx context.Context) (models.WorkLog, error)
+	Log(ctx context.Context, log models.WorkLog) (models.WorkLog, error)
+	List(ctx context.Context, taskID string, page models.Page) (models.WorkLogList, error)
+	Total(ctx context.Context, filter models.WorkLogFilter) (int64, error)
+	Report(ctx context.Context, filter models.WorkReportFilter) ([]models.WorkReportRow, error)
+}
+
+// WithWorkLogs enables the time tracking endpoints.
+func (h *TaskHandler) WithWorkLogs(svc WorkLogServise) *TaskHandler {
+	h.worklogs = svc
+	return h
+}
+
+func (h *TaskHandler) registerWorkLogRoutes() {
+	h.router.POST("/task/:id/timer/start", h.StartTimer)
+	h.router.GET("/task/:id/worklogs", h.ListWorkLogs)
+	h.router.POST("/task/:id/worklogs", h.LogWork)
+	h.router.GET("/task/:id/worklogs/total", h.GetTaskWorkTotal)
+
+	h.router.GET("/timer", h.GetTimer)
+	h.router.POST("/timer/stop", h.StopTimer)
+
+	worklogs := h.router.Group("/worklogs")
+	{
+		worklogs.GET("/total", h.GetWorkTotal)
+		worklogs.GET("/report", h.GetWorkReport)
+	}
+}
+
+type TimerRequest struct {
+	Note string `validate:"max=1000"`
+}
+
+type WorkLogRequest struct {
+	Started  time.Time `validate:"required"`
+	Duration int64     `validate:"required,min=1" example:"3600"`
+	Note     string    `validate:"max=1000"`
+}
+
+// @Summary Starting a timer
+// @Description Handles request to start tracking time of the authenticated user on a task. A user can have only one running timer.
+// @Tags worklog
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body TimerRequest false "Note"
+// @Success 201 {object} models.WorkLog "Running timer"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /task/{id}/timer/start [post]
+func (h *TaskHandler) StartTimer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req TimerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+			return
+		}
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	log, err := h.worklogs.Start(c.Request.Context(), id, req.Note)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to start timer: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"worklog": log}, http.StatusCreated, nil)
+}
+
+// @Summary Receiving the running timer
+// @Description Handles request to get the running timer of the authenticated user.
+// @Tags worklog
+// @Produce json
+// @Success 200 {object} models.WorkLog "Running timer"
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /timer [get]
+func (h *TaskHandler) GetTimer(c *gin.Context) {
+	log, err := h.worklogs.Running(c.Request.Context())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to receive timer: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"worklog": log}, http.StatusOK, nil)
+}
+
+// @Summary Stopping the timer
+// @Description Handles request to stop the running timer of the authenticated user and returns the finished work log.
+// @Tags worklog
+// @Produce json
+// @Success 200 {object} models.WorkLog "Finished work log"
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /timer/stop [post]
+func (h *TaskHandler) StopTimer(c *gin.Context) {
+	log, err := h.worklogs.Stop(c.Request.Context())
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to stop timer: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"worklog": log}, http.StatusOK, nil)
+}
+
+// @Summary Logging work
+// @Description Handles request to log time spent by the authenticated user on a task. Duration is in seconds.
+// @Tags worklog
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param request body WorkLogRequest true "Work log"
+// @Success 201 {object} models.WorkLog "Created work log"
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 500
+// @Router /task/{id}/worklogs [post]
+func (h *TaskHandler) LogWork(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var req WorkLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("failed to bind request JSON: %w", err))
+		return
+	}
+
+	log, err := h.worklogs.Log(c.Request.Context(), models.WorkLog{
+		TaskID:   id.String(),
+		Started:  req.Started,
+		Duration: req.Duration,
+		Note:     req.Note,
+	})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to log work: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"worklog": log}, http.StatusCreated, nil)
+}
+
+// @Summary Listing work logs
+// @Description Handles request to get a page of work logs of a task ordered by start time.
+// @Tags worklog
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param limit query int false "Page size"
+// @Param offset query int false "Page offset"
+// @Success 200 {object} models.WorkLogList "work logs"
+// @Failure 400
+// @Failure 500
+// @Router /task/{id}/worklogs [get]
+func (h *TaskHandler) ListWorkLogs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	var page models.Page
+	if err := c.ShouldBindQuery(&page); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	if err := h.validate.Struct(page); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid page: %w", err))
+		return
+	}
+
+	logs, err := h.worklogs.List(c.Request.Context(), id.String(), page)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to list work logs: %w", err))
+		return
+	}
+
+	page = page.Normalize()
+	h.Response(c, gin.H{
+		"worklogs": logs.WorkLogs,
+		"total":    logs.Total,
+		"limit":    page.Limit,
+		"offset":   page.Offset,
+	}, http.StatusOK, nil)
+}
+
+// @Summary Receiving time spent on a task
+// @Description Handles request to get the seconds logged on a task, running timers aren't counted.
+// @Tags worklog
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]int64 "duration"
+// @Failure 400
+// @Failure 500
+// @Router /task/{id}/worklogs/total [get]
+func (h *TaskHandler) GetTaskWorkTotal(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid UUID: %w", err))
+		return
+	}
+
+	total, err := h.worklogs.Total(c.Request.Context(), models.WorkLogFilter{TaskID: id.String()})
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to sum work logs: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"duration": total}, http.StatusOK, nil)
+}
+
+// @Summary Receiving time spent
+// @Description Handles request to get the seconds logged matching the filter, e.g. on the tasks of an owner.
+// @Tags worklog
+// @Produce json
+// @Param task_id query string false "Task ID"
+// @Param user_id query string false "ID of the user who logged the time"
+// @Param owner_id query string false "Task owner ID"
+// @Param project_id query string false "Project ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Success 200 {object} map[string]int64 "duration"
+// @Failure 400
+// @Failure 500
+// @Router /worklogs/total [get]
+func (h *TaskHandler) GetWorkTotal(c *gin.Context) {
+	var filter models.WorkLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	if err := h.validate.Struct(filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid filter: %w", err))
+		return
+	}
+
+	total, err := h.worklogs.Total(c.Request.Context(), filter)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to sum work logs: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"duration": total}, http.StatusOK, nil)
+}
+
+// @Summary Receiving a time report
+// @Description Handles request to aggregate the logged seconds by day and/or project over a date range.
+// @Tags worklog
+// @Produce json
+// @Param from query string true "First day, YYYY-MM-DD"
+// @Param to query string true "Last day, YYYY-MM-DD"
+// @Param group_by query []string true "day, project" collectionFormat(multi)
+// @Param user_id query string false "ID of the user who logged the time"
+// @Param owner_id query string false "Task owner ID"
+// @Param project_id query string false "Project ID"
+// @Success 200 {array} models.WorkReportRow "report"
+// @Failure 400
+// @Failure 500
+// @Router /worklogs/report [get]
+func (h *TaskHandler) GetWorkReport(c *gin.Context) {
+	var filter models.WorkReportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("error: %w", err))
+		return
+	}
+	if err := h.validate.Struct(filter); err != nil {
+		h.Response(c, nil, http.StatusBadRequest, fmt.Errorf("invalid filter: %w", err))
+		return
+	}
+
+	rows, err := h.worklogs.Report(c.Request.Context(), filter)
+	if err != nil {
+		h.Response(c, nil, errorStatus(err), fmt.Errorf("failed to build work report: %w", err))
+		return
+	}
+
+	h.Response(c, gin.H{"report": rows}, http.StatusOK, nil)
+}
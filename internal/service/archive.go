@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+)
+
+// Archive hides a task from task lists unless they include archived tasks.
+// The task keeps its status and can still be fetched and updated.
+func (s *TaskService) Archive(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	s.log.Info().Msgf("Archiving task with ID: %s", id.String())
+
+	return s.setArchived(ctx, id, time.Now().UTC())
+}
+
+func (s *TaskService) Unarchive(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	s.log.Info().Msgf("Unarchiving task with ID: %s", id.String())
+
+	return s.setArchived(ctx, id, time.Time{})
+}
+
+// ArchiveDone archives the tasks which have been done for longer than age,
+// however recently they were edited since.
+func (s *TaskService) ArchiveDone(ctx context.Context, age time.Duration) (int, error) {
+	now := time.Now().UTC()
+	tasks, err := s.repo.ArchiveStale(ctx, models.Done, now.Add(-age), now)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error archiving done tasks")
+		return 0, err
+	}
+
+	return len(tasks), nil
+}
+
+// RunArchiver calls ArchiveDone every interval until the context is done.
+func (s *TaskService) RunArchiver(ctx context.Context, age, interval time.Duration) {
+	runEvery(ctx, interval, func(ctx context.Context) {
+		archived, err := s.ArchiveDone(ctx, age)
+		if err == nil && archived > 0 {
+			s.log.Info().Msgf("archived %d done tasks", archived)
+		}
+	})
+}
+
+func (s *TaskService) setArchived(ctx context.Context, id uuid.UUID, archived time.Time) (models.Task, error) {
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	if current.Archived.IsZero() == archived.IsZero() {
+		return current, nil
+	}
+
+	task, err := s.repo.SetArchived(ctx, id.String(), archived, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error archiving task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
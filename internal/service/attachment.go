@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type AttachmentRepo interface {
+	Create(ctx context.Context, attachment models.Attachment) (models.Attachment, error)
+	Get(ctx context.Context, id string) (models.Attachment, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, taskID string) ([]models.Attachment, error)
+}
+
+// BlobStore keeps attachment contents. Keys are slash separated paths,
+// all blobs of a task share the task ID as a prefix.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type AttachmentService struct {
+	repo    AttachmentRepo
+	store   BlobStore
+	tasks   TaskGetter
+	maxSize int64
+	log     *zerolog.Logger
+}
+
+func NewAttachmentService(repo AttachmentRepo, store BlobStore, tasks TaskGetter, maxSize int64, log *zerolog.Logger) *AttachmentService {
+	return &AttachmentService{
+		repo:    repo,
+		store:   store,
+		tasks:   tasks,
+		maxSize: maxSize,
+		log:     log,
+	}
+}
+
+// MaxSize returns the largest accepted attachment in bytes.
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload stores the content and its metadata. If attachment.Checksum is set
+// the SHA-256 of the received content must match it.
+func (s *AttachmentService) Upload(ctx context.Context, attachment models.Attachment, content io.Reader) (models.Attachment, error) {
+	s.log.Debug().Msgf("Uploading attachment %s for task: %s", attachment.Filename, attachment.TaskID)
+
+	taskID, err := uuid.Parse(attachment.TaskID)
+	if err != nil {
+		return models.Attachment{}, models.ErrTaskNotFound
+	}
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", attachment.TaskID)
+		return models.Attachment{}, err
+	}
+
+	buffered := bufio.NewReader(content)
+	if attachment.ContentType == "" || attachment.ContentType == "application/octet-stream" {
+		head, _ := buffered.Peek(512)
+		attachment.ContentType = http.DetectContentType(head)
+	}
+
+	attachment.ID = uuid.New().String()
+	attachment.StorageKey = taskID.String() + "/" + attachment.ID
+	attachment.UploaderID, _ = auth.UserID(ctx)
+	attachment.Created = time.Now().UTC()
+
+	hash := sha256.New()
+	limited := io.LimitReader(buffered, s.maxSize+1)
+	size, err := s.store.Put(ctx, attachment.StorageKey, io.TeeReader(limited, hash))
+	if err != nil {
+		s.log.Error().Err(err).Msgf("failed to store attachment: %s", attachment.StorageKey)
+		s.discard(ctx, attachment.StorageKey)
+		return models.Attachment{}, err
+	}
+
+	if size > s.maxSize {
+		s.discard(ctx, attachment.StorageKey)
+		return models.Attachment{}, models.ErrAttachmentTooLarge
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if attachment.Checksum != "" && !strings.EqualFold(attachment.Checksum, checksum) {
+		s.discard(ctx, attachment.StorageKey)
+		return models.Attachment{}, models.ErrChecksumMismatch
+	}
+	attachment.Checksum = checksum
+	attachment.Size = size
+
+	created, err := s.repo.Create(ctx, attachment)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create attachment")
+		s.discard(ctx, attachment.StorageKey)
+		return models.Attachment{}, err
+	}
+
+	return created, nil
+}
+
+func (s *AttachmentService) Get(ctx context.Context, id string) (models.Attachment, error) {
+	attachment, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching attachment with ID: %s", id)
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// Open returns the attachment metadata and a reader of its content.
+// The caller must close the reader.
+func (s *AttachmentService) Open(ctx context.Context, id string) (models.Attachment, io.ReadCloser, error) {
+	s.log.Debug().Msgf("Opening attachment with ID: %s", id)
+
+	attachment, err := s.Get(ctx, id)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+
+	content, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error opening blob: %s", attachment.StorageKey)
+		return models.Attachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+func (s *AttachmentService) List(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	s.log.Info().Msgf("Listing attachments of task: %s", taskID)
+
+	attachments, err := s.repo.List(ctx, taskID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing attachments of task: %s", taskID)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (s *AttachmentService) Delete(ctx context.Context, id string) error {
+	s.log.Info().Msgf("Deleting attachment with ID: %s", id)
+
+	attachment, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting attachment with ID: %s", id)
+		return err
+	}
+
+	s.discard(ctx, attachment.StorageKey)
+	return nil
+}
+
+// CleanupTask removes the blobs of a purged task. Metadata rows are
+// removed by the database together with the task.
+func (s *AttachmentService) CleanupTask(ctx context.Context, taskID string) error {
+	s.log.Debug().Msgf("Removing attachments of task: %s", taskID)
+
+	if err := s.store.DeletePrefix(ctx, taskID+"/"); err != nil {
+		s.log.Error().Err(err).Msgf("Error removing attachments of task: %s", taskID)
+		return err
+	}
+	return nil
+}
+
+func (s *AttachmentService) discard(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		s.log.Error().Err(err).Msgf("failed to remove blob: %s", key)
+	}
+}
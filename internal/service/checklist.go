@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type ChecklistRepo interface {
+	List(ctx context.Context, taskID string) ([]models.ChecklistItem, error)
+	Add(ctx context.Context, item models.ChecklistItem) (models.ChecklistItem, error)
+	SetText(ctx context.Context, id string, text string) (models.ChecklistItem, error)
+	SetChecked(ctx context.Context, id string, checked bool) (models.ChecklistItem, error)
+	Reorder(ctx context.Context, taskID string, ids []string) ([]models.ChecklistItem, error)
+	Delete(ctx context.Context, id string) (models.ChecklistItem, error)
+}
+
+// TaskInvalidator drops the cached tasks changed by other repositories.
+type TaskInvalidator interface {
+	InvalidateTasks(ctx context.Context, ids ...string)
+}
+
+type ChecklistService struct {
+	repo        ChecklistRepo
+	tasks       TaskGetter
+	invalidator TaskInvalidator
+	log         *zerolog.Logger
+}
+
+func NewChecklistService(repo ChecklistRepo, tasks TaskGetter, log *zerolog.Logger) *ChecklistService {
+	return &ChecklistService{
+		repo:  repo,
+		tasks: tasks,
+		log:   log,
+	}
+}
+
+// WithInvalidation drops the cached task whenever its checklist progress changes.
+func (s *ChecklistService) WithInvalidation(invalidator TaskInvalidator) *ChecklistService {
+	s.invalidator = invalidator
+	return s
+}
+
+// invalidate drops the cached task, the checklist progress is stored in it.
+func (s *ChecklistService) invalidate(ctx context.Context, taskID string) {
+	if s.invalidator != nil {
+		s.invalidator.InvalidateTasks(ctx, taskID)
+	}
+}
+
+func (s *ChecklistService) List(ctx context.Context, taskID uuid.UUID) ([]models.ChecklistItem, error) {
+	s.log.Info().Msgf("Listing checklist of task: %s", taskID.String())
+
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", taskID.String())
+		return nil, err
+	}
+
+	items, err := s.repo.List(ctx, taskID.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing checklist of task: %s", taskID.String())
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Add appends an unchecked item to the task checklist.
+func (s *ChecklistService) Add(ctx context.Context, taskID uuid.UUID, text string) (models.ChecklistItem, error) {
+	s.log.Info().Msgf("Adding checklist item to task: %s", taskID.String())
+
+	item, err := s.repo.Add(ctx, models.ChecklistItem{
+		TaskID: taskID.String(),
+		Text:   text,
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error adding checklist item to task: %s", taskID.String())
+		return models.ChecklistItem{}, err
+	}
+	s.invalidate(ctx, item.TaskID)
+
+	return item, nil
+}
+
+// Edit replaces the text of the item.
+func (s *ChecklistService) Edit(ctx context.Context, id uuid.UUID, text string) (models.ChecklistItem, error) {
+	s.log.Info().Msgf("Editing checklist item: %s", id.String())
+
+	item, err := s.repo.SetText(ctx, id.String(), text)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error editing checklist item: %s", id.String())
+		return models.ChecklistItem{}, err
+	}
+	s.invalidate(ctx, item.TaskID)
+
+	return item, nil
+}
+
+// SetChecked checks or unchecks the item.
+func (s *ChecklistService) SetChecked(ctx context.Context, id uuid.UUID, checked bool) (models.ChecklistItem, error) {
+	s.log.Info().Msgf("Setting checklist item %s checked: %t", id.String(), checked)
+
+	item, err := s.repo.SetChecked(ctx, id.String(), checked)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error checking checklist item: %s", id.String())
+		return models.ChecklistItem{}, err
+	}
+	s.invalidate(ctx, item.TaskID)
+
+	return item, nil
+}
+
+// Reorder moves the items of the task checklist into the given order.
+func (s *ChecklistService) Reorder(ctx context.Context, taskID uuid.UUID, ids []uuid.UUID) ([]models.ChecklistItem, error) {
+	s.log.Info().Msgf("Reordering checklist of task: %s", taskID.String())
+
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", taskID.String())
+		return nil, err
+	}
+
+	order := make([]string, len(ids))
+	for i, id := range ids {
+		order[i] = id.String()
+	}
+
+	items, err := s.repo.Reorder(ctx, taskID.String(), order)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error reordering checklist of task: %s", taskID.String())
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (s *ChecklistService) Delete(ctx context.Context, id uuid.UUID) error {
+	s.log.Info().Msgf("Deleting checklist item: %s", id.String())
+
+	item, err := s.repo.Delete(ctx, id.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting checklist item: %s", id.String())
+		return err
+	}
+	s.invalidate(ctx, item.TaskID)
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type CommentRepo interface {
+	Create(ctx context.Context, comment models.Comment) (models.Comment, error)
+	Get(ctx context.Context, id string) (models.Comment, error)
+	Update(ctx context.Context, comment models.Comment, editorID string) (models.Comment, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, taskID string, page models.Page) (models.CommentList, error)
+	History(ctx context.Context, commentID string) ([]models.CommentEdit, error)
+}
+
+// TaskGetter is used by services which need to check that a task exists.
+type TaskGetter interface {
+	Get(ctx context.Context, id uuid.UUID) (models.Task, error)
+}
+
+type CommentService struct {
+	repo     CommentRepo
+	tasks    TaskGetter
+	mentions Mentioner
+	log      *zerolog.Logger
+}
+
+func NewCommentService(repo CommentRepo, tasks TaskGetter, log *zerolog.Logger) *CommentService {
+	return &CommentService{
+		repo:  repo,
+		tasks: tasks,
+		log:   log,
+	}
+}
+
+// WithMentions makes users @mentioned in comments watch the task.
+func (s *CommentService) WithMentions(mentions Mentioner) *CommentService {
+	s.mentions = mentions
+	return s
+}
+
+func (s *CommentService) Create(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	s.log.Debug().Msgf("Creating comment for task: %s", comment.TaskID)
+
+	authorID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.Comment{}, models.ErrUnauthenticated
+	}
+
+	taskID, err := uuid.Parse(comment.TaskID)
+	if err != nil {
+		return models.Comment{}, models.ErrTaskNotFound
+	}
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", comment.TaskID)
+		return models.Comment{}, err
+	}
+
+	comment.AuthorID = authorID
+	comment.Edited = false
+	comment.Created, comment.Updated = time.Now().UTC(), time.Now().UTC()
+
+	comment, err = s.repo.Create(ctx, comment)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create comment")
+		return models.Comment{}, err
+	}
+	s.mention(ctx, comment)
+
+	return comment, nil
+}
+
+// Update changes the comment body. Only the author may edit a comment.
+func (s *CommentService) Update(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	s.log.Info().Msgf("Updating comment with ID: %s", comment.ID)
+
+	current, err := s.authorized(ctx, comment.ID)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	current.Body = comment.Body
+	current.Updated = time.Now().UTC()
+
+	updated, err := s.repo.Update(ctx, current, current.AuthorID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error updating comment with ID: %s", comment.ID)
+		return models.Comment{}, err
+	}
+	s.mention(ctx, updated)
+
+	return updated, nil
+}
+
+// Delete removes the comment. Only the author may delete a comment.
+func (s *CommentService) Delete(ctx context.Context, id string) error {
+	s.log.Info().Msgf("Deleting comment with ID: %s", id)
+
+	if _, err := s.authorized(ctx, id); err != nil {
+		return err
+	}
+
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting comment with ID: %s", id)
+		return err
+	}
+
+	return nil
+}
+
+func (s *CommentService) List(ctx context.Context, taskID string, page models.Page) (models.CommentList, error) {
+	s.log.Info().Msgf("Listing comments of task: %s", taskID)
+
+	comments, err := s.repo.List(ctx, taskID, page.Normalize())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing comments of task: %s", taskID)
+		return models.CommentList{}, err
+	}
+
+	return comments, nil
+}
+
+func (s *CommentService) History(ctx context.Context, id string) ([]models.CommentEdit, error) {
+	s.log.Info().Msgf("Fetching history of comment: %s", id)
+
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	edits, err := s.repo.History(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching history of comment: %s", id)
+		return nil, err
+	}
+
+	return edits, nil
+}
+
+func (s *CommentService) authorized(ctx context.Context, id string) (models.Comment, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.Comment{}, models.ErrUnauthenticated
+	}
+
+	comment, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching comment with ID: %s", id)
+		return models.Comment{}, err
+	}
+
+	if comment.AuthorID != userID {
+		return models.Comment{}, models.ErrForbidden
+	}
+	return comment, nil
+}
+
+// mention adds the users @mentioned in the comment to the task watchers.
+// The comment is already stored, so a failure is only logged.
+func (s *CommentService) mention(ctx context.Context, comment models.Comment) {
+	if s.mentions == nil {
+		return
+	}
+
+	if _, err := s.mentions.Mention(ctx, comment.TaskID, comment.Body); err != nil {
+		s.log.Error().Err(err).Msgf("Error adding mentioned watchers of task: %s", comment.TaskID)
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type CustomFieldRepo interface {
+	List(ctx context.Context, projectID string) ([]models.FieldDefinition, error)
+	Create(ctx context.Context, field models.FieldDefinition) (models.FieldDefinition, error)
+	Delete(ctx context.Context, projectID string, name string) ([]string, error)
+}
+
+// ProjectGuard checks the role of the current user in a project.
+type ProjectGuard interface {
+	CheckMember(ctx context.Context, projectID string) error
+	CheckOwner(ctx context.Context, projectID string) error
+}
+
+// fieldName keeps field names usable as JSON keys and query parameters.
+var fieldName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type CustomFieldService struct {
+	repo        CustomFieldRepo
+	projects    ProjectGuard
+	invalidator TaskInvalidator
+	log         *zerolog.Logger
+}
+
+func NewCustomFieldService(repo CustomFieldRepo, projects ProjectGuard, log *zerolog.Logger) *CustomFieldService {
+	return &CustomFieldService{
+		repo:     repo,
+		projects: projects,
+		log:      log,
+	}
+}
+
+// WithInvalidation drops the cached tasks whose values are removed with a field.
+func (s *CustomFieldService) WithInvalidation(invalidator TaskInvalidator) *CustomFieldService {
+	s.invalidator = invalidator
+	return s
+}
+
+// List returns the field definitions of a project visible to the current user.
+func (s *CustomFieldService) List(ctx context.Context, projectID string) ([]models.FieldDefinition, error) {
+	s.log.Info().Msgf("Listing custom fields of project: %s", projectID)
+
+	if err := s.projects.CheckMember(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.Definitions(ctx, projectID)
+}
+
+// Definitions returns the field definitions of a project without checking access.
+func (s *CustomFieldService) Definitions(ctx context.Context, projectID string) ([]models.FieldDefinition, error) {
+	fields, err := s.repo.List(ctx, projectID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing custom fields of project: %s", projectID)
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Create defines a new field in a project, only the project owner may do it.
+func (s *CustomFieldService) Create(ctx context.Context, field models.FieldDefinition) (models.FieldDefinition, error) {
+	s.log.Info().Msgf("Creating custom field %s in project: %s", field.Name, field.ProjectID)
+
+	if err := s.projects.CheckOwner(ctx, field.ProjectID); err != nil {
+		return models.FieldDefinition{}, err
+	}
+
+	if !fieldName.MatchString(field.Name) {
+		return models.FieldDefinition{}, fmt.Errorf("%w: name %q must be lowercase letters, digits and underscores", models.ErrInvalidField, field.Name)
+	}
+	if field.Type == models.FieldEnum && len(field.Options) == 0 {
+		return models.FieldDefinition{}, fmt.Errorf("%w: enum %s has no options", models.ErrInvalidField, field.Name)
+	}
+	if field.Type != models.FieldEnum {
+		field.Options = nil
+	}
+
+	field.Created = time.Now().UTC()
+
+	field, err := s.repo.Create(ctx, field)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error creating custom field %s in project: %s", field.Name, field.ProjectID)
+		return models.FieldDefinition{}, err
+	}
+
+	return field, nil
+}
+
+// Delete removes a field definition and its values, only the project owner may do it.
+func (s *CustomFieldService) Delete(ctx context.Context, projectID string, name string) error {
+	s.log.Info().Msgf("Deleting custom field %s of project: %s", name, projectID)
+
+	if err := s.projects.CheckOwner(ctx, projectID); err != nil {
+		return err
+	}
+
+	taskIDs, err := s.repo.Delete(ctx, projectID, name)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting custom field %s of project: %s", name, projectID)
+		return err
+	}
+	if s.invalidator != nil && len(taskIDs) > 0 {
+		s.invalidator.InvalidateTasks(ctx, taskIDs...)
+	}
+
+	return nil
+}
+
+// validateCustomFields checks the values against the field definitions.
+// Numbers are expected as float64, which is what JSON and protobuf Struct decode into.
+func validateCustomFields(defs []models.FieldDefinition, values map[string]any) error {
+	known := make(map[string]models.FieldDefinition, len(defs))
+	for _, def := range defs {
+		known[def.Name] = def
+	}
+
+	for name, value := range values {
+		def, ok := known[name]
+		if !ok {
+			return fmt.Errorf("%w: %s isn't defined in the project", models.ErrInvalidField, name)
+		}
+		if err := validateFieldValue(def, value); err != nil {
+			return err
+		}
+	}
+
+	for _, def := range defs {
+		if _, ok := values[def.Name]; def.Required && !ok {
+			return fmt.Errorf("%w: %s is required", models.ErrInvalidField, def.Name)
+		}
+	}
+	return nil
+}
+
+func validateFieldValue(def models.FieldDefinition, value any) error {
+	invalid := fmt.Errorf("%w: %s must be a %s", models.ErrInvalidField, def.Name, def.Type)
+
+	switch def.Type {
+	case models.FieldNumber:
+		if _, ok := value.(float64); !ok {
+			return invalid
+		}
+		return nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return invalid
+	}
+
+	switch def.Type {
+	case models.FieldDate:
+		if _, err := time.Parse(models.DateLayout, str); err != nil {
+			return invalid
+		}
+	case models.FieldEnum:
+		if !slices.Contains(def.Options, str) {
+			return fmt.Errorf("%w: %s must be one of %v", models.ErrInvalidField, def.Name, def.Options)
+		}
+	case models.FieldUser:
+		if _, err := uuid.Parse(str); err != nil {
+			return invalid
+		}
+	}
+	return nil
+}
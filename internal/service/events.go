@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+)
+
+// EventBus delivers task events to the watchers of task changes.
+type EventBus interface {
+	Subscribe(afterID uint64) (<-chan models.TaskEvent, func(), error)
+}
+
+// WithEvents enables watching the task events relayed to the bus from the outbox.
+func (s *TaskService) WithEvents(events EventBus) *TaskService {
+	s.events = events
+	return s
+}
+
+// Watch streams the events of the tasks matching the filter after the change,
+// starting after the event with afterID (0 for new events only). The channel is
+// closed when the context is done or the watcher falls too far behind.
+func (s *TaskService) Watch(ctx context.Context, filter models.TaskFilter, afterID uint64) (<-chan models.TaskEvent, error) {
+	s.log.Info().Msgf("Watching tasks: %v", filter)
+
+	if s.events == nil {
+		return nil, errors.New("task events aren't enabled")
+	}
+
+	filter, err := s.resolveFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	events, cancel, err := s.events.Subscribe(afterID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan models.TaskEvent)
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					s.log.Warn().Msg("task watcher fell behind")
+					return
+				}
+				if !filter.Match(event.Task) {
+					continue
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+)
+
+// History returns the revisions of a task, the oldest first.
+func (s *TaskService) History(ctx context.Context, id uuid.UUID) ([]models.Revision, error) {
+	s.log.Info().Msgf("Fetching history of task with ID: %s", id.String())
+
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", id.String())
+		return nil, err
+	}
+
+	revisions, err := s.history.List(ctx, id.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching history of task with ID: %s", id.String())
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Revert undoes a revision by setting its fields back to the old values.
+// It fails with ErrRevertConflict when any of the fields has changed since,
+// the status has to follow the workflow like any other status change.
+func (s *TaskService) Revert(ctx context.Context, id uuid.UUID, revisionID uuid.UUID) (models.Task, error) {
+	s.log.Info().Msgf("Reverting revision %s of task with ID: %s", revisionID.String(), id.String())
+
+	revision, err := s.history.Get(ctx, revisionID.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching revision with ID: %s", revisionID.String())
+		return models.Task{}, err
+	}
+	if revision.TaskID != id.String() {
+		return models.Task{}, models.ErrRevisionNotFound
+	}
+	if revision.Action == models.ActionCreated || len(revision.Changes) == 0 {
+		return models.Task{}, models.ErrNotRevertible
+	}
+
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	reverted := current
+	columns := make([]string, 0, len(revision.Changes))
+	for _, change := range revision.Changes {
+		field, ok := models.LookupField(change.Field)
+		if !ok {
+			return models.Task{}, fmt.Errorf("%w: %s isn't tracked", models.ErrNotRevertible, change.Field)
+		}
+		if !reflect.DeepEqual(field.Get(current), change.New) {
+			return models.Task{}, fmt.Errorf("%w: %s", models.ErrRevertConflict, change.Field)
+		}
+		if err := field.Set(&reverted, change.Old); err != nil {
+			return models.Task{}, fmt.Errorf("%w: %s: %v", models.ErrNotRevertible, change.Field, err)
+		}
+		columns = append(columns, change.Field)
+	}
+
+	if reverted.Status != current.Status {
+		if err := s.workflow.ValidateTransition(ctx, current.Status, reverted.Status); err != nil {
+			s.log.Error().Err(err).Msgf("invalid transition %s -> %s", current.Status, reverted.Status)
+			return models.Task{}, err
+		}
+	}
+	if reverted.ProjectID != current.ProjectID {
+		if err := s.checkProjects(ctx, current.ProjectID, reverted.ProjectID); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	reverted.Updated = time.Now().UTC()
+
+	task, err := s.repo.Patch(ctx, reverted, columns, models.ActionReverted)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error reverting task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
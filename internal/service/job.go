@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// runEvery calls job every interval until the context is done.
+func runEvery(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job(ctx)
+		}
+	}
+}
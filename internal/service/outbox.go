@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type OutboxRepo interface {
+	Relay(ctx context.Context, limit int, handle func(ctx context.Context, event models.TaskEvent) error) (int, error)
+	PurgeRelayed(ctx context.Context, before time.Time) (int, error)
+}
+
+// EventSink receives the task events relayed from the outbox. An event is
+// relayed again if any sink fails on it, so sinks see it at least once.
+type EventSink interface {
+	Handle(ctx context.Context, event models.TaskEvent) error
+}
+
+// SinkFunc adapts a function to EventSink.
+type SinkFunc func(ctx context.Context, event models.TaskEvent) error
+
+func (f SinkFunc) Handle(ctx context.Context, event models.TaskEvent) error {
+	return f(ctx, event)
+}
+
+// LogSink writes the relayed events to the log.
+func LogSink(log *zerolog.Logger) EventSink {
+	return SinkFunc(func(ctx context.Context, event models.TaskEvent) error {
+		log.Info().Msgf("Task event %d: %s %s", event.ID, event.Type, event.Task.ID)
+		return nil
+	})
+}
+
+// outboxBatch is the number of events relayed in one transaction
+const outboxBatch = 100
+
+type OutboxRelay struct {
+	repo  OutboxRepo
+	sinks []EventSink
+	log   *zerolog.Logger
+}
+
+func NewOutboxRelay(repo OutboxRepo, sinks []EventSink, log *zerolog.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		repo:  repo,
+		sinks: sinks,
+		log:   log,
+	}
+}
+
+// RunRelay relays the pending events every interval and deletes the ones
+// relayed longer than retention ago until the context is done.
+func (r *OutboxRelay) RunRelay(ctx context.Context, interval time.Duration, retention time.Duration) {
+	runEvery(ctx, interval, func(ctx context.Context) {
+		if _, err := r.RelayPending(ctx); err != nil {
+			r.log.Error().Err(err).Msg("Error relaying outbox events")
+		}
+
+		purged, err := r.repo.PurgeRelayed(ctx, time.Now().UTC().Add(-retention))
+		if err != nil {
+			r.log.Error().Err(err).Msg("Error purging relayed outbox events")
+			return
+		}
+		if purged > 0 {
+			r.log.Debug().Msgf("Purged %d relayed outbox events", purged)
+		}
+	})
+}
+
+// RelayPending passes the pending events to every sink in batches until none
+// are left and returns how many were relayed.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		relayed, err := r.repo.Relay(ctx, outboxBatch, r.handle)
+		total += relayed
+		if err != nil || relayed < outboxBatch {
+			return total, err
+		}
+	}
+}
+
+func (r *OutboxRelay) handle(ctx context.Context, event models.TaskEvent) error {
+	for _, sink := range r.sinks {
+		if err := sink.Handle(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
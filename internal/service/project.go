@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type ProjectRepo interface {
+	Create(ctx context.Context, project models.Project) (models.Project, error)
+	Get(ctx context.Context, id string) (models.Project, error)
+	List(ctx context.Context, userID string) ([]models.Project, error)
+	Update(ctx context.Context, project models.Project) (models.Project, error)
+	Delete(ctx context.Context, id string) ([]string, error)
+	Member(ctx context.Context, projectID, userID string) (models.ProjectMember, error)
+	Members(ctx context.Context, projectID string) ([]models.ProjectMember, error)
+	AddMember(ctx context.Context, member models.ProjectMember) (models.ProjectMember, error)
+	RemoveMember(ctx context.Context, projectID, userID string) error
+}
+
+// TaskLister is used by services which list tasks within their own scope.
+type TaskLister interface {
+	List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+}
+
+type ProjectService struct {
+	repo        ProjectRepo
+	tasks       TaskLister
+	invalidator TaskInvalidator
+	log         *zerolog.Logger
+}
+
+func NewProjectService(repo ProjectRepo, tasks TaskLister, log *zerolog.Logger) *ProjectService {
+	return &ProjectService{
+		repo:  repo,
+		tasks: tasks,
+		log:   log,
+	}
+}
+
+// WithInvalidation drops the cached tasks detached from a deleted project.
+func (s *ProjectService) WithInvalidation(invalidator TaskInvalidator) *ProjectService {
+	s.invalidator = invalidator
+	return s
+}
+
+// Create stores a project owned by the current user.
+func (s *ProjectService) Create(ctx context.Context, project models.Project) (models.Project, error) {
+	s.log.Debug().Msgf("Creating project: %s", project.Name)
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.Project{}, models.ErrUnauthenticated
+	}
+
+	project.OwnerID = userID
+	project.Created, project.Updated = time.Now().UTC(), time.Now().UTC()
+
+	project, err := s.repo.Create(ctx, project)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create project")
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+func (s *ProjectService) Get(ctx context.Context, id string) (models.Project, error) {
+	s.log.Debug().Msgf("Fetching project with ID: %s", id)
+
+	if _, err := s.member(ctx, id); err != nil {
+		return models.Project{}, err
+	}
+
+	project, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching project with ID: %s", id)
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+// List returns the projects of the current user.
+func (s *ProjectService) List(ctx context.Context) ([]models.Project, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+	s.log.Info().Msgf("Listing projects of user: %s", userID)
+
+	projects, err := s.repo.List(ctx, userID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing projects of user: %s", userID)
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// Update changes the name and description. Only the owner may edit a project.
+func (s *ProjectService) Update(ctx context.Context, project models.Project) (models.Project, error) {
+	s.log.Info().Msgf("Updating project with ID: %s", project.ID)
+
+	if err := s.owner(ctx, project.ID); err != nil {
+		return models.Project{}, err
+	}
+
+	project.Updated = time.Now().UTC()
+
+	project, err := s.repo.Update(ctx, project)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error updating project with ID: %s", project.ID)
+		return models.Project{}, err
+	}
+
+	return project, nil
+}
+
+// Delete removes the project. Its tasks are kept without a project and
+// without the values of its custom fields.
+func (s *ProjectService) Delete(ctx context.Context, id string) error {
+	s.log.Info().Msgf("Deleting project with ID: %s", id)
+
+	if err := s.owner(ctx, id); err != nil {
+		return err
+	}
+
+	taskIDs, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting project with ID: %s", id)
+		return err
+	}
+	if s.invalidator != nil && len(taskIDs) > 0 {
+		s.invalidator.InvalidateTasks(ctx, taskIDs...)
+	}
+
+	return nil
+}
+
+// Tasks lists the tasks of the project matching the filter.
+func (s *ProjectService) Tasks(ctx context.Context, id string, filter models.TaskFilter) ([]models.Task, error) {
+	s.log.Info().Msgf("Listing tasks of project: %s", id)
+
+	if _, err := s.member(ctx, id); err != nil {
+		return nil, err
+	}
+
+	filter.ProjectID = id
+	return s.tasks.List(ctx, filter)
+}
+
+func (s *ProjectService) Members(ctx context.Context, id string) ([]models.ProjectMember, error) {
+	s.log.Info().Msgf("Listing members of project: %s", id)
+
+	if _, err := s.member(ctx, id); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.Members(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing members of project: %s", id)
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// AddMember adds a user to the project. Only the owner may add members.
+func (s *ProjectService) AddMember(ctx context.Context, id string, userID string) (models.ProjectMember, error) {
+	s.log.Info().Msgf("Adding member %s to project: %s", userID, id)
+
+	if err := s.owner(ctx, id); err != nil {
+		return models.ProjectMember{}, err
+	}
+
+	member, err := s.repo.AddMember(ctx, models.ProjectMember{
+		ProjectID: id,
+		UserID:    userID,
+		Role:      models.RoleMember,
+		Added:     time.Now().UTC(),
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error adding member %s to project: %s", userID, id)
+		return models.ProjectMember{}, err
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes a user from the project. The owner may remove anyone
+// but themselves, other members may only leave the project.
+func (s *ProjectService) RemoveMember(ctx context.Context, id string, userID string) error {
+	s.log.Info().Msgf("Removing member %s from project: %s", userID, id)
+
+	current, err := s.member(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.Role != models.RoleOwner && current.UserID != userID {
+		return models.ErrForbidden
+	}
+
+	member, err := s.repo.Member(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.RoleOwner {
+		return models.ErrProjectOwner
+	}
+
+	err = s.repo.RemoveMember(ctx, id, userID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error removing member %s from project: %s", userID, id)
+		return err
+	}
+
+	return nil
+}
+
+// CheckMember checks that the current user is a member of the project.
+func (s *ProjectService) CheckMember(ctx context.Context, id string) error {
+	_, err := s.member(ctx, id)
+	return err
+}
+
+// CheckOwner checks that the current user owns the project.
+func (s *ProjectService) CheckOwner(ctx context.Context, id string) error {
+	return s.owner(ctx, id)
+}
+
+// member returns the membership of the current user, a missing project and
+// a missing membership are both reported as ErrProjectNotFound.
+func (s *ProjectService) member(ctx context.Context, id string) (models.ProjectMember, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.ProjectMember{}, models.ErrUnauthenticated
+	}
+
+	member, err := s.repo.Member(ctx, id, userID)
+	if errors.Is(err, models.ErrMemberNotFound) {
+		return models.ProjectMember{}, models.ErrProjectNotFound
+	}
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching member %s of project: %s", userID, id)
+		return models.ProjectMember{}, err
+	}
+	return member, nil
+}
+
+func (s *ProjectService) owner(ctx context.Context, id string) error {
+	member, err := s.member(ctx, id)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.RoleOwner {
+		return models.ErrForbidden
+	}
+	return nil
+}
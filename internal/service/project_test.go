@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// ownedProjectRepo has one project owned by ownerID, whose deletion detaches
+// the tasks. The other methods aren't used.
+type ownedProjectRepo struct {
+	ProjectRepo
+	projectID string
+	ownerID   string
+	taskIDs   []string
+	deleted   bool
+}
+
+func (r *ownedProjectRepo) Member(ctx context.Context, projectID, userID string) (models.ProjectMember, error) {
+	if projectID != r.projectID || userID != r.ownerID || r.deleted {
+		return models.ProjectMember{}, models.ErrMemberNotFound
+	}
+	return models.ProjectMember{ProjectID: projectID, UserID: userID, Role: models.RoleOwner}, nil
+}
+
+func (r *ownedProjectRepo) Delete(ctx context.Context, id string) ([]string, error) {
+	if id != r.projectID || r.deleted {
+		return nil, models.ErrProjectNotFound
+	}
+	r.deleted = true
+	return r.taskIDs, nil
+}
+
+type invalidations []string
+
+func (i *invalidations) InvalidateTasks(ctx context.Context, ids ...string) {
+	*i = append(*i, ids...)
+}
+
+func TestDeleteProjectInvalidatesTasks(t *testing.T) {
+	logger := zerolog.Nop()
+	repo := &ownedProjectRepo{
+		projectID: uuid.NewString(),
+		ownerID:   uuid.NewString(),
+		taskIDs:   []string{uuid.NewString(), uuid.NewString()},
+	}
+	var invalidated invalidations
+	svc := NewProjectService(repo, nil, &logger).WithInvalidation(&invalidated)
+
+	ctx := auth.WithUser(context.Background(), repo.ownerID)
+	if err := svc.Delete(ctx, repo.projectID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if !slices.Equal(invalidated, repo.taskIDs) {
+		t.Errorf("invalidated %v, want the detached tasks %v", invalidated, repo.taskIDs)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/rrule"
+	"github.com/google/uuid"
+)
+
+const MaxOccurrences = 100
+
+func validateRecurrence(rule string, due time.Time) error {
+	if due.IsZero() {
+		return models.ErrDueRequired
+	}
+	if _, err := rrule.Parse(rule); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidRRule, err)
+	}
+	return nil
+}
+
+// nextOccurrence returns the due date following the given one and the rule
+// for the rest of the series, false when the series is over. The due date is
+// the DTSTART of the rule, so COUNT is decreased for every new occurrence.
+func nextOccurrence(rule string, due time.Time) (time.Time, string, bool, error) {
+	parsed, err := rrule.Parse(rule)
+	if err != nil {
+		return time.Time{}, "", false, fmt.Errorf("%w: %v", models.ErrInvalidRRule, err)
+	}
+
+	next, ok := parsed.After(due, due)
+	if !ok {
+		return time.Time{}, "", false, nil
+	}
+
+	if parsed.Count > 0 {
+		parsed.Count--
+	}
+	return next, parsed.String(), true, nil
+}
+
+// scheduleNext creates the next occurrence of a recurring task and moves the
+// rule to it in one write, so completing the task again doesn't repeat the
+// occurrence.
+// It returns the task without the rule.
+func (s *TaskService) scheduleNext(ctx context.Context, task models.Task) (models.Task, error) {
+	next, rule, ok, err := nextOccurrence(task.RRule, task.Due)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if !ok {
+		s.log.Debug().Msgf("recurrence of task %s is over", task.ID)
+		return task, nil
+	}
+
+	status, err := s.workflow.InitialStatus(ctx)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	now := time.Now().UTC()
+	occurrence := models.Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Created:     now,
+		Updated:     now,
+		Status:      status,
+		OwnerID:     task.OwnerID,
+		AssigneeID:  task.AssigneeID,
+		Due:         next,
+		RRule:       rule,
+		ProjectID:   task.ProjectID,
+
+		EstimateMinutes: task.EstimateMinutes,
+		StoryPoints:     task.StoryPoints,
+		CustomFields:    task.CustomFields,
+		Labels:          task.Labels,
+	}
+
+	done, occurrence, err := s.repo.CompleteRecurring(ctx, task.ID, occurrence, now)
+	if err != nil {
+		return models.Task{}, err
+	}
+	s.log.Info().Msgf("scheduled next occurrence %s of task %s due %s", occurrence.ID, task.ID, next)
+
+	return done, nil
+}
+
+// Occurrences previews up to n due dates following the current one.
+func (s *TaskService) Occurrences(ctx context.Context, id uuid.UUID, n int) ([]time.Time, error) {
+	s.log.Debug().Msgf("Previewing occurrences of task with ID: %s", id.String())
+
+	task, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", id.String())
+		return nil, err
+	}
+	if task.RRule == "" {
+		return nil, models.ErrNotRecurring
+	}
+
+	rule, err := rrule.Parse(task.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidRRule, err)
+	}
+
+	if n > MaxOccurrences {
+		n = MaxOccurrences
+	}
+
+	res := make([]time.Time, 0, n)
+	it := rule.Iterator(task.Due)
+	for len(res) < n {
+		next, ok := it.Next()
+		if !ok {
+			break
+		}
+		if next.After(task.Due) {
+			res = append(res, next)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/repository/memory"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+func newMemTaskService() *TaskService {
+	logger := zerolog.Nop()
+	db := memory.NewDB()
+	workflow := NewWorkflowService(memory.NewWorkflowRepository(db, &logger), &logger)
+	return NewTaskService(memory.NewTaskRepository(db, &logger), workflow, memory.NewHistoryRepository(db, &logger), &logger)
+}
+
+func setStatus(t *testing.T, svc *TaskService, id string, status models.TaskStatus) models.Task {
+	t.Helper()
+	task, err := svc.Update(context.Background(), models.Task{ID: id, Status: status})
+	if err != nil {
+		t.Fatalf("Update(%s): %v", status, err)
+	}
+	return task
+}
+
+func TestCompleteRecurringTask(t *testing.T) {
+	ctx := context.Background()
+	svc := newMemTaskService()
+
+	due := time.Date(2024, 10, 7, 9, 0, 0, 0, time.UTC)
+	task, err := svc.Create(ctx, models.Task{
+		Title:       "Weekly report",
+		Description: "Send the numbers",
+		Status:      models.InProgress,
+		OwnerID:     uuid.NewString(),
+		Due:         due,
+		RRule:       "FREQ=WEEKLY;BYDAY=MO",
+		Labels:      []string{"reports", "weekly"},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	done := setStatus(t, svc, task.ID, models.Done)
+	if done.RRule != "" {
+		t.Errorf("completed task keeps the rule %q", done.RRule)
+	}
+
+	tasks, err := svc.List(ctx, models.TaskFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want the task and its next occurrence", len(tasks))
+	}
+	i := slices.IndexFunc(tasks, func(val models.Task) bool { return val.ID != task.ID })
+	next := tasks[i]
+
+	if next.Title != task.Title || next.Description != task.Description || next.OwnerID != task.OwnerID {
+		t.Errorf("next occurrence = %+v, want the fields of %+v", next, task)
+	}
+	if !slices.Equal(next.Labels, task.Labels) {
+		t.Errorf("next occurrence labels = %v, want %v", next.Labels, task.Labels)
+	}
+	if want := due.AddDate(0, 0, 7); !next.Due.Equal(want) {
+		t.Errorf("next occurrence due = %s, want %s", next.Due, want)
+	}
+	if next.RRule != task.RRule {
+		t.Errorf("next occurrence rule = %q, want %q", next.RRule, task.RRule)
+	}
+	if next.Status != "todo" {
+		t.Errorf("next occurrence status = %q, want the initial status", next.Status)
+	}
+
+	// reopening and completing the task again doesn't repeat the occurrence
+	setStatus(t, svc, task.ID, models.InProgress)
+	setStatus(t, svc, task.ID, models.Done)
+
+	tasks, err = svc.List(ctx, models.TaskFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("got %d tasks after completing the task again, want 2", len(tasks))
+	}
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/VikaPaz/task_tracker/internal/auth"
 	"github.com/VikaPaz/task_tracker/internal/models"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -13,25 +16,144 @@ type Repo interface {
 	Create(ctx context.Context, task models.Task) (models.Task, error)
 	Get(ctx context.Context, id uuid.UUID) (models.Task, error)
 	Update(ctx context.Context, req models.Task) (models.Task, error)
+	SetAssignee(ctx context.Context, id string, assigneeID string, updated time.Time) (models.Task, error)
+	SetProject(ctx context.Context, id string, projectID string, updated time.Time) (models.Task, error)
 	Delete(ctx context.Context, id string) error
+	Trash(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	Restore(ctx context.Context, id string, updated time.Time) (models.Task, error)
+	Purge(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) ([]string, error)
+	SetArchived(ctx context.Context, id string, archived time.Time, updated time.Time) (models.Task, error)
+	ArchiveStale(ctx context.Context, status models.TaskStatus, before time.Time, archived time.Time) ([]models.Task, error)
+	Patch(ctx context.Context, task models.Task, columns []string, action models.HistoryAction) (models.Task, error)
+	CompleteRecurring(ctx context.Context, id string, occurrence models.Task, updated time.Time) (done models.Task, next models.Task, err error)
+	CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error)
 	List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error)
+	Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error)
+	Changes(ctx context.Context, since uint64, limit int) (models.TaskChanges, error)
+}
+
+// HistoryRepo reads the task revisions, which the task repository writes
+// together with the changes.
+type HistoryRepo interface {
+	Get(ctx context.Context, id string) (models.Revision, error)
+	List(ctx context.Context, taskID string) ([]models.Revision, error)
+}
+
+// Workflow validates task statuses against the configured workflow.
+type Workflow interface {
+	ValidateStatus(ctx context.Context, status models.TaskStatus) error
+	ValidateTransition(ctx context.Context, from, to models.TaskStatus) error
+	InitialStatus(ctx context.Context) (models.TaskStatus, error)
+}
+
+// TaskCleaner releases resources bound to a task after it has been purged.
+type TaskCleaner interface {
+	CleanupTask(ctx context.Context, taskID string) error
+}
+
+// ProjectAccess checks that the current user may put tasks into a project.
+type ProjectAccess interface {
+	CheckMember(ctx context.Context, projectID string) error
+}
+
+// FieldDefinitions provides the custom fields defined in a project.
+type FieldDefinitions interface {
+	Definitions(ctx context.Context, projectID string) ([]models.FieldDefinition, error)
+}
+
+// Mentioner makes the users @mentioned in a text watch the task.
+type Mentioner interface {
+	Mention(ctx context.Context, taskID string, text string) ([]string, error)
 }
 
 type TaskService struct {
-	repo Repo
-	log  *zerolog.Logger
+	repo     Repo
+	workflow Workflow
+	history  HistoryRepo
+	projects ProjectAccess
+	fields   FieldDefinitions
+	mentions Mentioner
+	events   EventBus
+	cleaners []TaskCleaner
+	log      *zerolog.Logger
 }
 
-func NewTaskService(repo Repo, log *zerolog.Logger) *TaskService {
+func NewTaskService(repo Repo, workflow Workflow, history HistoryRepo, log *zerolog.Logger) *TaskService {
 	return &TaskService{
-		repo: repo,
-		log:  log,
+		repo:     repo,
+		workflow: workflow,
+		history:  history,
+		log:      log,
 	}
 }
 
+// WithCleaners registers cleaners which are run after a task is purged.
+func (s *TaskService) WithCleaners(cleaners ...TaskCleaner) *TaskService {
+	s.cleaners = append(s.cleaners, cleaners...)
+	return s
+}
+
+// WithProjects enables the membership check when a task is put into a project.
+func (s *TaskService) WithProjects(projects ProjectAccess) *TaskService {
+	s.projects = projects
+	return s
+}
+
+// WithCustomFields enables validation of custom field values against the project definitions.
+func (s *TaskService) WithCustomFields(fields FieldDefinitions) *TaskService {
+	s.fields = fields
+	return s
+}
+
+// WithMentions makes users @mentioned in task descriptions watch the task.
+func (s *TaskService) WithMentions(mentions Mentioner) *TaskService {
+	s.mentions = mentions
+	return s
+}
+
 func (s *TaskService) Create(ctx context.Context, task models.Task) (models.Task, error) {
 	s.log.Debug().Msgf("Creating task: %v", task)
 
+	if task.Status == "" {
+		status, err := s.workflow.InitialStatus(ctx)
+		if err != nil {
+			return models.Task{}, err
+		}
+		task.Status = status
+	}
+
+	if err := s.workflow.ValidateStatus(ctx, task.Status); err != nil {
+		s.log.Error().Err(err).Msgf("invalid status: %s", task.Status)
+		return models.Task{}, err
+	}
+
+	if task.RRule != "" {
+		if err := validateRecurrence(task.RRule, task.Due); err != nil {
+			s.log.Error().Err(err).Msgf("invalid recurrence: %s", task.RRule)
+			return models.Task{}, err
+		}
+	}
+
+	if task.ProjectID != "" {
+		if err := s.checkProject(ctx, task.ProjectID); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	if err := s.checkCustomFields(ctx, task.ProjectID, task.CustomFields); err != nil {
+		return models.Task{}, err
+	}
+
+	if task.ParentID != "" {
+		if err := s.checkParent(ctx, task.ParentID); err != nil {
+			return models.Task{}, err
+		}
+	}
+
+	task.ChecklistProgress = models.ChecklistProgress{}
+	task.Watchers = nil
+	task.Deleted, task.Archived = time.Time{}, time.Time{}
 	task.Created, task.Updated = time.Now().UTC(), time.Now().UTC()
 
 	task, err := s.repo.Create(ctx, task)
@@ -41,7 +163,49 @@ func (s *TaskService) Create(ctx context.Context, task models.Task) (models.Task
 	}
 	s.log.Debug().Msg("created new task")
 
-	return task, nil
+	return s.mention(ctx, task), nil
+}
+
+// CreateTree creates a task with its checklist and subtasks. Subtasks are put
+// into the project of the root task and get its owner and the initial status.
+func (s *TaskService) CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error) {
+	s.log.Debug().Msgf("Creating task tree: %s", tree.Task.Title)
+
+	status, err := s.workflow.InitialStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tree.Task.ProjectID != "" {
+		if err := s.checkProject(ctx, tree.Task.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	if tree.Task.ParentID != "" {
+		if err := s.checkParent(ctx, tree.Task.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	tree = fillTree(tree, tree.Task.OwnerID, tree.Task.ProjectID, status, time.Now().UTC())
+
+	tasks, err := s.repo.CreateTree(ctx, tree)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create task tree")
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func fillTree(tree models.TaskTree, ownerID, projectID string, status models.TaskStatus, now time.Time) models.TaskTree {
+	tree.Task.OwnerID, tree.Task.ProjectID, tree.Task.Status = ownerID, projectID, status
+	tree.Task.Created, tree.Task.Updated = now, now
+	for i, subtask := range tree.Subtasks {
+		tree.Subtasks[i] = fillTree(subtask, ownerID, projectID, status, now)
+	}
+	return tree
 }
 
 func (s *TaskService) Get(ctx context.Context, id uuid.UUID) (models.Task, error) {
@@ -60,6 +224,42 @@ func (s *TaskService) Get(ctx context.Context, id uuid.UUID) (models.Task, error
 func (s *TaskService) Update(ctx context.Context, req models.Task) (models.Task, error) {
 	s.log.Info().Msgf("Updating task with ID: %s", req.ID)
 
+	id, err := uuid.Parse(req.ID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", req.ID)
+		return models.Task{}, err
+	}
+
+	if req.Status != "" {
+		if err := s.workflow.ValidateTransition(ctx, current.Status, req.Status); err != nil {
+			s.log.Error().Err(err).Msgf("invalid transition %s -> %s", current.Status, req.Status)
+			return models.Task{}, err
+		}
+	}
+
+	if req.RRule != "" {
+		due := req.Due
+		if due.IsZero() {
+			due = current.Due
+		}
+		if err := validateRecurrence(req.RRule, due); err != nil {
+			s.log.Error().Err(err).Msgf("invalid recurrence: %s", req.RRule)
+			return models.Task{}, err
+		}
+	}
+
+	if req.CustomFields != nil {
+		req.CustomFields = mergeCustomFields(current.CustomFields, req.CustomFields)
+		if err := s.checkCustomFields(ctx, current.ProjectID, req.CustomFields); err != nil {
+			return models.Task{}, err
+		}
+	}
+
 	req.Updated = time.Now()
 
 	task, err := s.repo.Update(ctx, req)
@@ -68,9 +268,42 @@ func (s *TaskService) Update(ctx context.Context, req models.Task) (models.Task,
 		return models.Task{}, err
 	}
 
+	if task.RRule != "" && task.Status == models.Done && current.Status != models.Done {
+		// the update is already stored, a failure only means the series stops here
+		done, err := s.scheduleNext(ctx, task)
+		if err != nil {
+			s.log.Error().Err(err).Msgf("Error scheduling next occurrence of task with ID: %s", task.ID)
+		} else {
+			task = done
+		}
+	}
+
+	if task.Description != current.Description {
+		task = s.mention(ctx, task)
+	}
+
 	return task, nil
 }
 
+// mention adds the users @mentioned in the task description to its watchers.
+// The task is already stored, so a failure only leaves the watchers as they are.
+func (s *TaskService) mention(ctx context.Context, task models.Task) models.Task {
+	if s.mentions == nil {
+		return task
+	}
+
+	watchers, err := s.mentions.Mention(ctx, task.ID, task.Description)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error adding mentioned watchers of task with ID: %s", task.ID)
+		return task
+	}
+	if watchers != nil {
+		task.Watchers = watchers
+	}
+	return task
+}
+
+// Delete moves a task to the trash, see Restore and Purge.
 func (s *TaskService) Delete(ctx context.Context, id uuid.UUID) error {
 	s.log.Info().Msgf("Deleting task with ID: %s", id.String())
 
@@ -86,6 +319,11 @@ func (s *TaskService) Delete(ctx context.Context, id uuid.UUID) error {
 func (s *TaskService) List(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
 	s.log.Info().Msg("Listing tasks with filter")
 
+	filter, err := s.resolveFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	tasks, err := s.repo.List(ctx, filter)
 	if err != nil {
 		s.log.Error().Err(err).Msg("Error listing tasks")
@@ -94,3 +332,181 @@ func (s *TaskService) List(ctx context.Context, filter models.TaskFilter) ([]mod
 
 	return tasks, nil
 }
+
+// Stats rolls up story points and estimates of the tasks matching the filter.
+func (s *TaskService) Stats(ctx context.Context, filter models.TaskStatsFilter) ([]models.TaskStats, error) {
+	s.log.Info().Msgf("Rolling up tasks by %s", filter.GroupBy)
+
+	taskFilter, err := s.resolveFilter(ctx, filter.TaskFilter)
+	if err != nil {
+		return nil, err
+	}
+	filter.TaskFilter = taskFilter
+	if filter.GroupBy == "" {
+		filter.GroupBy = models.StatsByStatus
+	}
+
+	stats, err := s.repo.Stats(ctx, filter)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error rolling up tasks")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// resolveFilter replaces AssigneeMe with the current user and checks the status.
+func (s *TaskService) resolveFilter(ctx context.Context, filter models.TaskFilter) (models.TaskFilter, error) {
+	if filter.AssigneeID == models.AssigneeMe {
+		userID, ok := auth.UserID(ctx)
+		if !ok {
+			return models.TaskFilter{}, models.ErrUnauthenticated
+		}
+		filter.AssigneeID = userID
+	}
+
+	if filter.Status != "" {
+		if err := s.workflow.ValidateStatus(ctx, models.TaskStatus(filter.Status)); err != nil {
+			s.log.Error().Err(err).Msgf("invalid status: %s", filter.Status)
+			return models.TaskFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
+
+// Assign sets the assignee of a task and records it in the task history.
+func (s *TaskService) Assign(ctx context.Context, id uuid.UUID, assigneeID string) (models.Task, error) {
+	s.log.Info().Msgf("Assigning task with ID: %s to %s", id.String(), assigneeID)
+
+	return s.setAssignee(ctx, id, assigneeID)
+}
+
+// Unassign removes the assignee of a task and records it in the task history.
+func (s *TaskService) Unassign(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	s.log.Info().Msgf("Unassigning task with ID: %s", id.String())
+
+	return s.setAssignee(ctx, id, "")
+}
+
+// Move puts a task into a project, an empty projectID removes it from its project.
+// The change is recorded in the task history.
+func (s *TaskService) Move(ctx context.Context, id uuid.UUID, projectID string) (models.Task, error) {
+	s.log.Info().Msgf("Moving task with ID: %s to project %s", id.String(), projectID)
+
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	if current.ProjectID == projectID {
+		return current, nil
+	}
+	if err := s.checkProjects(ctx, current.ProjectID, projectID); err != nil {
+		return models.Task{}, err
+	}
+
+	task, err := s.repo.SetProject(ctx, id.String(), projectID, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error moving task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+func (s *TaskService) checkParent(ctx context.Context, parentID string) error {
+	id, err := uuid.Parse(parentID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrParentNotFound, err)
+	}
+	_, err = s.repo.Get(ctx, id)
+	if errors.Is(err, models.ErrTaskNotFound) {
+		return models.ErrParentNotFound
+	}
+	return err
+}
+
+// checkProjects checks that the user is a member of both the source and
+// the target project of a task, an empty project is skipped.
+func (s *TaskService) checkProjects(ctx context.Context, from, to string) error {
+	for _, project := range []string{from, to} {
+		if project == "" {
+			continue
+		}
+		if err := s.checkProject(ctx, project); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskService) checkProject(ctx context.Context, projectID string) error {
+	if s.projects == nil {
+		return nil
+	}
+	if err := s.projects.CheckMember(ctx, projectID); err != nil {
+		s.log.Error().Err(err).Msgf("project %s isn't available", projectID)
+		return err
+	}
+	return nil
+}
+
+func (s *TaskService) checkCustomFields(ctx context.Context, projectID string, values map[string]any) error {
+	if projectID == "" {
+		if len(values) != 0 {
+			return models.ErrProjectRequired
+		}
+		return nil
+	}
+	if s.fields == nil {
+		return nil
+	}
+
+	defs, err := s.fields.Definitions(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if err := validateCustomFields(defs, values); err != nil {
+		s.log.Error().Err(err).Msgf("invalid custom fields of project %s", projectID)
+		return err
+	}
+	return nil
+}
+
+// mergeCustomFields applies the changed values to the current ones, a nil value removes the field.
+func mergeCustomFields(current, changes map[string]any) map[string]any {
+	res := make(map[string]any, len(current)+len(changes))
+	for name, value := range current {
+		res[name] = value
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(res, name)
+			continue
+		}
+		res[name] = value
+	}
+	return res
+}
+
+func (s *TaskService) setAssignee(ctx context.Context, id uuid.UUID, assigneeID string) (models.Task, error) {
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	if current.AssigneeID == assigneeID {
+		return current, nil
+	}
+
+	task, err := s.repo.SetAssignee(ctx, id.String(), assigneeID, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error assigning task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
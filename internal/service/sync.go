@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+)
+
+// syncBatch is the number of changes returned by one sync at most
+const syncBatch = 500
+
+// Sync returns the tasks created or updated after the sync token and the IDs
+// of the deleted ones together with the token of the next sync. An empty
+// token returns every task.
+func (s *TaskService) Sync(ctx context.Context, token string) (models.TaskChanges, error) {
+	s.log.Info().Msgf("Syncing tasks after token: %q", token)
+
+	since, err := models.ParseSyncToken(token)
+	if err != nil {
+		return models.TaskChanges{}, err
+	}
+
+	changes, err := s.repo.Changes(ctx, since, syncBatch)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error syncing tasks after token: %q", token)
+		return models.TaskChanges{}, err
+	}
+
+	return changes, nil
+}
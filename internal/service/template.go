@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type TemplateRepo interface {
+	Create(ctx context.Context, template models.Template) (models.Template, error)
+	Get(ctx context.Context, id string) (models.Template, error)
+	List(ctx context.Context) ([]models.Template, error)
+	Update(ctx context.Context, template models.Template) (models.Template, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// TreeCreator creates a task with its checklist and subtasks.
+type TreeCreator interface {
+	CreateTree(ctx context.Context, tree models.TaskTree) ([]models.Task, error)
+}
+
+// placeholder matches template variables like {{customer}}.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_]*)\s*\}\}`)
+
+type TemplateService struct {
+	repo  TemplateRepo
+	tasks TreeCreator
+	log   *zerolog.Logger
+}
+
+func NewTemplateService(repo TemplateRepo, tasks TreeCreator, log *zerolog.Logger) *TemplateService {
+	return &TemplateService{
+		repo:  repo,
+		tasks: tasks,
+		log:   log,
+	}
+}
+
+// Create stores a template owned by the current user.
+func (s *TemplateService) Create(ctx context.Context, template models.Template) (models.Template, error) {
+	s.log.Info().Msgf("Creating template: %s", template.Name)
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.Template{}, models.ErrUnauthenticated
+	}
+
+	template.OwnerID = userID
+	template.Created, template.Updated = time.Now().UTC(), time.Now().UTC()
+
+	template, err := s.repo.Create(ctx, template)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create template")
+		return models.Template{}, err
+	}
+
+	return withVariables(template), nil
+}
+
+func (s *TemplateService) Get(ctx context.Context, id uuid.UUID) (models.Template, error) {
+	s.log.Debug().Msgf("Fetching template with ID: %s", id.String())
+
+	template, err := s.repo.Get(ctx, id.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching template with ID: %s", id.String())
+		return models.Template{}, err
+	}
+
+	return withVariables(template), nil
+}
+
+func (s *TemplateService) List(ctx context.Context) ([]models.Template, error) {
+	s.log.Debug().Msg("Listing templates")
+
+	templates, err := s.repo.List(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error listing templates")
+		return nil, err
+	}
+
+	for i := range templates {
+		templates[i] = withVariables(templates[i])
+	}
+	return templates, nil
+}
+
+// Update replaces the name and the task tree of a template, only its owner may do it.
+func (s *TemplateService) Update(ctx context.Context, template models.Template) (models.Template, error) {
+	s.log.Info().Msgf("Updating template with ID: %s", template.ID)
+
+	if err := s.owner(ctx, template.ID); err != nil {
+		return models.Template{}, err
+	}
+
+	template.Updated = time.Now().UTC()
+
+	template, err := s.repo.Update(ctx, template)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error updating template with ID: %s", template.ID)
+		return models.Template{}, err
+	}
+
+	return withVariables(template), nil
+}
+
+func (s *TemplateService) Delete(ctx context.Context, id uuid.UUID) error {
+	s.log.Info().Msgf("Deleting template with ID: %s", id.String())
+
+	if err := s.owner(ctx, id.String()); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id.String()); err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting template with ID: %s", id.String())
+		return err
+	}
+
+	return nil
+}
+
+// Instantiate creates the task tree of a template owned by the current user,
+// every variable used in the template has to be set.
+func (s *TemplateService) Instantiate(ctx context.Context, id uuid.UUID, projectID string, vars map[string]string) ([]models.Task, error) {
+	s.log.Info().Msgf("Instantiating template with ID: %s", id.String())
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+
+	template, err := s.repo.Get(ctx, id.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching template with ID: %s", id.String())
+		return nil, err
+	}
+
+	for _, name := range withVariables(template).Variables {
+		if _, ok := vars[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", models.ErrMissingVariable, name)
+		}
+	}
+
+	tree := instantiate(template.Root, vars)
+	tree.Task.OwnerID = userID
+	tree.Task.ProjectID = projectID
+
+	tasks, err := s.tasks.CreateTree(ctx, tree)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error instantiating template with ID: %s", id.String())
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (s *TemplateService) owner(ctx context.Context, id string) error {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.ErrUnauthenticated
+	}
+
+	template, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching template with ID: %s", id)
+		return err
+	}
+	if template.OwnerID != userID {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+// instantiate builds the task tree of a template, the owner and the project
+// are set on the root task only.
+func instantiate(task models.TemplateTask, vars map[string]string) models.TaskTree {
+	substitute := func(text string) string {
+		return placeholder.ReplaceAllStringFunc(text, func(match string) string {
+			return vars[placeholder.FindStringSubmatch(match)[1]]
+		})
+	}
+
+	tree := models.TaskTree{
+		Task: models.Task{
+			Title:       substitute(task.Title),
+			Description: substitute(task.Description),
+		},
+		Checklist: make([]string, len(task.Checklist)),
+		Subtasks:  make([]models.TaskTree, len(task.Subtasks)),
+	}
+	for _, label := range task.Labels {
+		tree.Task.Labels = append(tree.Task.Labels, substitute(label))
+	}
+	for i, item := range task.Checklist {
+		tree.Checklist[i] = substitute(item)
+	}
+	for i, subtask := range task.Subtasks {
+		tree.Subtasks[i] = instantiate(subtask, vars)
+	}
+	return tree
+}
+
+// withVariables fills the sorted names of the placeholders used in the template.
+func withVariables(template models.Template) models.Template {
+	var collect func(task models.TemplateTask)
+	template.Variables = []string{}
+	collect = func(task models.TemplateTask) {
+		texts := append([]string{task.Title, task.Description}, task.Labels...)
+		for _, text := range append(texts, task.Checklist...) {
+			for _, match := range placeholder.FindAllStringSubmatch(text, -1) {
+				if !slices.Contains(template.Variables, match[1]) {
+					template.Variables = append(template.Variables, match[1])
+				}
+			}
+		}
+		for _, subtask := range task.Subtasks {
+			collect(subtask)
+		}
+	}
+	collect(template.Root)
+	slices.Sort(template.Variables)
+	return template
+}
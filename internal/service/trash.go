@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+)
+
+// Trash lists the deleted tasks matching the filter.
+func (s *TaskService) Trash(ctx context.Context, filter models.TaskFilter) ([]models.Task, error) {
+	s.log.Debug().Msgf("Listing trashed tasks: %v", filter)
+
+	filter, err := s.resolveFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.Trash(ctx, filter)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error listing trashed tasks")
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// Restore takes a deleted task out of the trash.
+func (s *TaskService) Restore(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	s.log.Info().Msgf("Restoring task with ID: %s", id.String())
+
+	task, err := s.repo.Restore(ctx, id.String(), time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error restoring task with ID: %s", id.String())
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// Purge permanently deletes a task from the trash and releases its resources.
+func (s *TaskService) Purge(ctx context.Context, id uuid.UUID) error {
+	s.log.Info().Msgf("Purging task with ID: %s", id.String())
+
+	if err := s.repo.Purge(ctx, id.String()); err != nil {
+		s.log.Error().Err(err).Msgf("Error purging task with ID: %s", id.String())
+		return err
+	}
+
+	s.cleanup(ctx, id.String())
+	return nil
+}
+
+// PurgeExpired permanently deletes the tasks kept in the trash longer than the retention.
+func (s *TaskService) PurgeExpired(ctx context.Context, retention time.Duration) (int, error) {
+	ids, err := s.repo.PurgeDeleted(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error purging expired tasks")
+		return 0, err
+	}
+
+	for _, id := range ids {
+		s.cleanup(ctx, id)
+	}
+	return len(ids), nil
+}
+
+// RunPurge calls PurgeExpired every interval until the context is done.
+func (s *TaskService) RunPurge(ctx context.Context, retention, interval time.Duration) {
+	runEvery(ctx, interval, func(ctx context.Context) {
+		purged, err := s.PurgeExpired(ctx, retention)
+		if err == nil && purged > 0 {
+			s.log.Info().Msgf("purged %d tasks from the trash", purged)
+		}
+	})
+}
+
+// cleanup runs the cleaners of a purged task. The task is already gone,
+// so a failed cleanup is only logged.
+func (s *TaskService) cleanup(ctx context.Context, id string) {
+	for _, cleaner := range s.cleaners {
+		if err := cleaner.CleanupTask(ctx, id); err != nil {
+			s.log.Error().Err(err).Msgf("Error cleaning up task with ID: %s", id)
+		}
+	}
+}
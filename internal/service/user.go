@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type UserRepo interface {
+	SetUsername(ctx context.Context, user models.User) (models.User, error)
+	FindByUsernames(ctx context.Context, usernames []string) ([]models.User, error)
+}
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_]{3,32}$`)
+
+type UserService struct {
+	repo UserRepo
+	log  *zerolog.Logger
+}
+
+func NewUserService(repo UserRepo, log *zerolog.Logger) *UserService {
+	return &UserService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// SetUsername sets the username of the current user, usernames are case insensitive.
+func (s *UserService) SetUsername(ctx context.Context, username string) (models.User, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.User{}, models.ErrUnauthenticated
+	}
+
+	username = strings.ToLower(username)
+	if !usernamePattern.MatchString(username) {
+		return models.User{}, models.ErrInvalidUsername
+	}
+
+	s.log.Info().Msgf("Setting username of user %s: %s", userID, username)
+
+	user, err := s.repo.SetUsername(ctx, models.User{ID: userID, Username: username})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error setting username of user: %s", userID)
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+// Resolve returns the IDs of the users having the usernames, unknown usernames are skipped.
+func (s *UserService) Resolve(ctx context.Context, usernames []string) ([]string, error) {
+	users, err := s.repo.FindByUsernames(ctx, usernames)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error resolving usernames: %v", usernames)
+		return nil, err
+	}
+
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids, nil
+}
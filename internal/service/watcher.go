@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type WatcherRepo interface {
+	List(ctx context.Context, taskID string) ([]string, error)
+	Add(ctx context.Context, taskID string, userIDs []string, created time.Time) ([]string, error)
+	Remove(ctx context.Context, taskID string, userID string) ([]string, error)
+}
+
+// UserResolver finds users by the usernames used in @mentions.
+type UserResolver interface {
+	Resolve(ctx context.Context, usernames []string) ([]string, error)
+}
+
+// mention matches @username, but not the domain of an email address.
+var mention = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9_]{3,32})\b`)
+
+type WatcherService struct {
+	repo        WatcherRepo
+	users       UserResolver
+	tasks       TaskGetter
+	invalidator TaskInvalidator
+	log         *zerolog.Logger
+}
+
+func NewWatcherService(repo WatcherRepo, users UserResolver, tasks TaskGetter, log *zerolog.Logger) *WatcherService {
+	return &WatcherService{
+		repo:  repo,
+		users: users,
+		tasks: tasks,
+		log:   log,
+	}
+}
+
+// WithInvalidation drops the cached task whenever its watchers change.
+func (s *WatcherService) WithInvalidation(invalidator TaskInvalidator) *WatcherService {
+	s.invalidator = invalidator
+	return s
+}
+
+// invalidate drops the cached task, the watchers are stored in it.
+func (s *WatcherService) invalidate(ctx context.Context, taskID string) {
+	if s.invalidator != nil {
+		s.invalidator.InvalidateTasks(ctx, taskID)
+	}
+}
+
+// List returns the IDs of the users watching the task, which are the
+// recipients of its notifications.
+func (s *WatcherService) List(ctx context.Context, taskID uuid.UUID) ([]string, error) {
+	s.log.Debug().Msgf("Listing watchers of task: %s", taskID.String())
+
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", taskID.String())
+		return nil, err
+	}
+
+	watchers, err := s.repo.List(ctx, taskID.String())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing watchers of task: %s", taskID.String())
+		return nil, err
+	}
+
+	return watchers, nil
+}
+
+// Watch makes the current user watch the task.
+func (s *WatcherService) Watch(ctx context.Context, taskID uuid.UUID) ([]string, error) {
+	s.log.Info().Msgf("Watching task: %s", taskID.String())
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+
+	watchers, err := s.repo.Add(ctx, taskID.String(), []string{userID}, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error watching task: %s", taskID.String())
+		return nil, err
+	}
+	s.invalidate(ctx, taskID.String())
+
+	return watchers, nil
+}
+
+// Unwatch stops the current user watching the task.
+func (s *WatcherService) Unwatch(ctx context.Context, taskID uuid.UUID) ([]string, error) {
+	s.log.Info().Msgf("Unwatching task: %s", taskID.String())
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+
+	watchers, err := s.repo.Remove(ctx, taskID.String(), userID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error unwatching task: %s", taskID.String())
+		return nil, err
+	}
+	s.invalidate(ctx, taskID.String())
+
+	return watchers, nil
+}
+
+// Mention makes the users @mentioned in the text watch the task.
+// It returns the watchers of the task, or nil when nobody known is mentioned.
+func (s *WatcherService) Mention(ctx context.Context, taskID string, text string) ([]string, error) {
+	usernames := mentions(text)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	userIDs, err := s.users.Resolve(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	s.log.Debug().Msgf("Users mentioned in task %s: %v", taskID, userIDs)
+
+	watchers, err := s.repo.Add(ctx, taskID, userIDs, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error adding mentioned watchers of task: %s", taskID)
+		return nil, err
+	}
+	s.invalidate(ctx, taskID)
+
+	return watchers, nil
+}
+
+// mentions returns the lowercased usernames @mentioned in the text.
+func mentions(text string) []string {
+	var usernames []string
+	for _, match := range mention.FindAllStringSubmatch(text, -1) {
+		username := strings.ToLower(match[1])
+		if !slices.Contains(usernames, username) {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
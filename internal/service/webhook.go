@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	Get(ctx context.Context, id string) (models.Webhook, error)
+	List(ctx context.Context, ownerID string) ([]models.Webhook, error)
+	Delete(ctx context.Context, id string) error
+	AddDeliveries(ctx context.Context, deliveries []models.Delivery) ([]models.Delivery, error)
+	ClaimDue(ctx context.Context, now time.Time, leased time.Time, limit int) ([]models.Delivery, error)
+	SaveAttempt(ctx context.Context, delivery models.Delivery) error
+	GetDelivery(ctx context.Context, id string) (models.Delivery, error)
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]models.Delivery, error)
+}
+
+// SignatureHeader carries the HMAC-SHA256 of the payload, see Signature.
+const SignatureHeader = "X-Webhook-Signature"
+
+const (
+	// deliveryBatch is the number of deliveries attempted at once
+	deliveryBatch = 50
+	// deliveryLogSize is the number of the last deliveries listed for a webhook
+	deliveryLogSize = 100
+	// deliveryLease postpones a claimed delivery in case the attempt never ends
+	deliveryLease = 5 * time.Minute
+)
+
+// retryBase is the delay after the first failed attempt, doubled after every
+// further one up to retryMax.
+var (
+	retryBase = 30 * time.Second
+	retryMax  = 6 * time.Hour
+)
+
+type WebhookService struct {
+	repo        WebhookRepo
+	client      *http.Client
+	maxAttempts int
+	log         *zerolog.Logger
+}
+
+func NewWebhookService(repo WebhookRepo, client *http.Client, maxAttempts int, log *zerolog.Logger) *WebhookService {
+	return &WebhookService{
+		repo:        repo,
+		client:      client,
+		maxAttempts: maxAttempts,
+		log:         log,
+	}
+}
+
+// Signature returns the value of SignatureHeader for the payload.
+func Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Create subscribes a URL of the current user to task events.
+func (s *WebhookService) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	s.log.Info().Msgf("Creating webhook: %s", webhook.URL)
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.Webhook{}, models.ErrUnauthenticated
+	}
+
+	webhook.OwnerID = userID
+	if webhook.Filter.AssigneeID == models.AssigneeMe {
+		webhook.Filter.AssigneeID = userID
+	}
+	webhook.Created = time.Now().UTC()
+
+	webhook, err := s.repo.Create(ctx, webhook)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create webhook")
+		return models.Webhook{}, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// List returns the webhooks of the current user.
+func (s *WebhookService) List(ctx context.Context) ([]models.Webhook, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return nil, models.ErrUnauthenticated
+	}
+
+	webhooks, err := s.repo.List(ctx, userID)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing webhooks of: %s", userID)
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id uuid.UUID) (models.Webhook, error) {
+	webhook, err := s.owned(ctx, id.String())
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	s.log.Info().Msgf("Deleting webhook with ID: %s", id.String())
+
+	if _, err := s.owned(ctx, id.String()); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id.String()); err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting webhook with ID: %s", id.String())
+		return err
+	}
+
+	return nil
+}
+
+// Deliveries returns the last deliveries of the webhook, the newest first.
+func (s *WebhookService) Deliveries(ctx context.Context, id uuid.UUID) ([]models.Delivery, error) {
+	if _, err := s.owned(ctx, id.String()); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, id.String(), deliveryLogSize)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing deliveries of webhook with ID: %s", id.String())
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver sends the payload of a delivery again as a new delivery.
+func (s *WebhookService) Redeliver(ctx context.Context, id uuid.UUID, deliveryID uuid.UUID) (models.Delivery, error) {
+	s.log.Info().Msgf("Redelivering webhook delivery with ID: %s", deliveryID.String())
+
+	if _, err := s.owned(ctx, id.String()); err != nil {
+		return models.Delivery{}, err
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, deliveryID.String())
+	if err != nil {
+		return models.Delivery{}, err
+	}
+	if delivery.WebhookID != id.String() {
+		return models.Delivery{}, models.ErrDeliveryNotFound
+	}
+
+	now := time.Now().UTC()
+	redelivery := models.Delivery{
+		WebhookID:   delivery.WebhookID,
+		EventID:     delivery.EventID,
+		EventType:   delivery.EventType,
+		Payload:     delivery.Payload,
+		Status:      models.DeliveryPending,
+		NextAttempt: now,
+		Created:     now,
+	}
+	added, err := s.repo.AddDeliveries(ctx, []models.Delivery{redelivery})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error redelivering webhook delivery with ID: %s", deliveryID.String())
+		return models.Delivery{}, err
+	}
+
+	return added[0], nil
+}
+
+func (s *WebhookService) owned(ctx context.Context, id string) (models.Webhook, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.Webhook{}, models.ErrUnauthenticated
+	}
+
+	webhook, err := s.repo.Get(ctx, id)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching webhook with ID: %s", id)
+		return models.Webhook{}, err
+	}
+	if webhook.OwnerID != userID {
+		return models.Webhook{}, models.ErrForbidden
+	}
+	return webhook, nil
+}
+
+// Enqueue adds a delivery of the event for every webhook subscribed to it.
+func (s *WebhookService) Enqueue(ctx context.Context, event models.TaskEvent) error {
+	webhooks, err := s.repo.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var deliveries []models.Delivery
+	for _, webhook := range webhooks {
+		if len(webhook.EventTypes) > 0 && !slices.Contains(webhook.EventTypes, event.Type) {
+			continue
+		}
+		if !webhook.Filter.Match(event.Task) {
+			continue
+		}
+		deliveries = append(deliveries, models.Delivery{
+			WebhookID:   webhook.ID,
+			EventID:     event.ID,
+			EventType:   event.Type,
+			Payload:     payload,
+			Status:      models.DeliveryPending,
+			NextAttempt: now,
+			Created:     now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	_, err = s.repo.AddDeliveries(ctx, deliveries)
+	return err
+}
+
+// RunDeliveries attempts the due deliveries every interval until the context is done.
+func (s *WebhookService) RunDeliveries(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func(ctx context.Context) {
+		if _, err := s.DeliverDue(ctx); err != nil {
+			s.log.Error().Err(err).Msg("Error delivering webhooks")
+		}
+	})
+}
+
+// DeliverDue attempts the pending deliveries which are due and returns how many succeeded.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	deliveries, err := s.repo.ClaimDue(ctx, now, now.Add(deliveryLease), deliveryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		delivery = s.attempt(ctx, delivery)
+		if err := s.repo.SaveAttempt(ctx, delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == models.DeliveryDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends the delivery and returns it with the outcome, a failed
+// attempt is retried with an exponential backoff until maxAttempts.
+func (s *WebhookService) attempt(ctx context.Context, delivery models.Delivery) models.Delivery {
+	delivery.Attempts++
+	delivery.ResponseCode, delivery.LastError = 0, ""
+
+	code, err := s.send(ctx, delivery)
+	delivery.ResponseCode = code
+	now := time.Now().UTC()
+
+	if err == nil {
+		delivery.Status = models.DeliveryDelivered
+		delivery.NextAttempt = time.Time{}
+		delivery.Delivered = now
+		return delivery
+	}
+
+	s.log.Warn().Err(err).Msgf("webhook delivery %s failed, attempt %d", delivery.ID, delivery.Attempts)
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= s.maxAttempts {
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttempt = time.Time{}
+		return delivery
+	}
+
+	delivery.NextAttempt = now.Add(backoff(delivery.Attempts))
+	return delivery
+}
+
+// send posts the payload to the webhook URL and returns the response status code.
+func (s *WebhookService) send(ctx context.Context, delivery models.Delivery) (int, error) {
+	webhook, err := s.repo.Get(ctx, delivery.WebhookID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "task-tracker-webhooks")
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	req.Header.Set(SignatureHeader, Signature(webhook.Secret, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func backoff(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMax)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// memWebhookRepo keeps webhooks and deliveries in memory.
+type memWebhookRepo struct {
+	mu         sync.Mutex
+	webhooks   map[string]models.Webhook
+	deliveries map[string]models.Delivery
+}
+
+func newMemWebhookRepo(webhooks ...models.Webhook) *memWebhookRepo {
+	repo := &memWebhookRepo{
+		webhooks:   make(map[string]models.Webhook),
+		deliveries: make(map[string]models.Delivery),
+	}
+	for _, webhook := range webhooks {
+		repo.webhooks[webhook.ID] = webhook
+	}
+	return repo
+}
+
+func (r *memWebhookRepo) Create(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook.ID = uuid.NewString()
+	r.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (r *memWebhookRepo) Get(ctx context.Context, id string) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return models.Webhook{}, models.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (r *memWebhookRepo) List(ctx context.Context, ownerID string) ([]models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []models.Webhook
+	for _, webhook := range r.webhooks {
+		if ownerID == "" || webhook.OwnerID == ownerID {
+			res = append(res, webhook)
+		}
+	}
+	return res, nil
+}
+
+func (r *memWebhookRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *memWebhookRepo) AddDeliveries(ctx context.Context, deliveries []models.Delivery) ([]models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range deliveries {
+		deliveries[i].ID = uuid.NewString()
+		r.deliveries[deliveries[i].ID] = deliveries[i]
+	}
+	return deliveries, nil
+}
+
+func (r *memWebhookRepo) ClaimDue(ctx context.Context, now time.Time, leased time.Time, limit int) ([]models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []models.Delivery
+	for id, delivery := range r.deliveries {
+		if delivery.Status != models.DeliveryPending || delivery.NextAttempt.After(now) || len(res) == limit {
+			continue
+		}
+		delivery.NextAttempt = leased
+		r.deliveries[id] = delivery
+		res = append(res, delivery)
+	}
+	return res, nil
+}
+
+func (r *memWebhookRepo) SaveAttempt(ctx context.Context, delivery models.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (r *memWebhookRepo) GetDelivery(ctx context.Context, id string) (models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return models.Delivery{}, models.ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+func (r *memWebhookRepo) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []models.Delivery
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			res = append(res, delivery)
+		}
+	}
+	return res, nil
+}
+
+// only returns the single delivery of the repo.
+func (r *memWebhookRepo) only(t *testing.T) models.Delivery {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(r.deliveries))
+	}
+	for _, delivery := range r.deliveries {
+		return delivery
+	}
+	return models.Delivery{}
+}
+
+// due makes the pending deliveries due now.
+func (r *memWebhookRepo) due() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, delivery := range r.deliveries {
+		delivery.NextAttempt = time.Time{}
+		r.deliveries[id] = delivery
+	}
+}
+
+type received struct {
+	body      []byte
+	signature string
+	event     string
+}
+
+// receiver starts an httptest server answering with the given status codes in turn.
+func receiver(t *testing.T, codes ...int) (*httptest.Server, <-chan received) {
+	t.Helper()
+	requests := make(chan received, len(codes))
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		requests <- received{body: body, signature: r.Header.Get(SignatureHeader), event: r.Header.Get("X-Webhook-Event")}
+
+		mu.Lock()
+		code := codes[0]
+		codes = codes[1:]
+		mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func newTestWebhookService(repo WebhookRepo, maxAttempts int) *WebhookService {
+	log := zerolog.Nop()
+	return NewWebhookService(repo, http.DefaultClient, maxAttempts, &log)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	server, requests := receiver(t, http.StatusNoContent)
+	webhook := models.Webhook{ID: uuid.NewString(), URL: server.URL, Secret: "0123456789abcdef"}
+	repo := newMemWebhookRepo(webhook)
+	svc := newTestWebhookService(repo, 3)
+	ctx := context.Background()
+
+	event := models.TaskEvent{ID: 7, Type: models.EventCreated, Task: models.Task{ID: uuid.NewString(), Title: "Deploy"}}
+	if err := svc.Enqueue(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+
+	delivered, err := svc.DeliverDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 1 {
+		t.Fatalf("delivered %d, want 1", delivered)
+	}
+
+	req := <-requests
+	if want := Signature(webhook.Secret, req.body); req.signature != want {
+		t.Errorf("signature = %q, want %q", req.signature, want)
+	}
+	if req.event != string(models.EventCreated) {
+		t.Errorf("event header = %q, want %q", req.event, models.EventCreated)
+	}
+
+	delivery := repo.only(t)
+	if delivery.Status != models.DeliveryDelivered || delivery.Attempts != 1 || delivery.ResponseCode != http.StatusNoContent {
+		t.Errorf("delivery = %+v, want delivered after 1 attempt with 204", delivery)
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	server, requests := receiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	webhook := models.Webhook{ID: uuid.NewString(), OwnerID: uuid.NewString(), URL: server.URL, Secret: "0123456789abcdef"}
+	repo := newMemWebhookRepo(webhook)
+	svc := newTestWebhookService(repo, 2)
+	ctx := auth.WithUser(context.Background(), webhook.OwnerID)
+
+	if err := svc.Enqueue(ctx, models.TaskEvent{ID: 1, Type: models.EventUpdated}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := svc.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-requests
+
+	delivery := repo.only(t)
+	if delivery.Status != models.DeliveryPending || delivery.Attempts != 1 || delivery.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("delivery = %+v, want pending after 1 attempt with 500", delivery)
+	}
+	if wait := delivery.NextAttempt.Sub(start); wait < retryBase || wait > retryBase+time.Minute {
+		t.Errorf("next attempt in %s, want about %s", wait, retryBase)
+	}
+
+	// not due yet
+	if _, err := svc.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if attempts := repo.only(t).Attempts; attempts != 1 {
+		t.Fatalf("attempts = %d before the retry is due, want 1", attempts)
+	}
+
+	repo.due()
+	if _, err := svc.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-requests
+
+	delivery = repo.only(t)
+	if delivery.Status != models.DeliveryFailed || delivery.Attempts != 2 || delivery.LastError == "" {
+		t.Errorf("delivery = %+v, want failed after 2 attempts", delivery)
+	}
+
+	redelivery, err := svc.Redeliver(ctx, uuid.MustParse(webhook.ID), uuid.MustParse(delivery.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered, err := svc.DeliverDue(ctx); err != nil || delivered != 1 {
+		t.Fatalf("redelivered %d, %v, want 1", delivered, err)
+	}
+	if got := <-requests; string(got.body) != string(delivery.Payload) {
+		t.Errorf("redelivered body = %s, want %s", got.body, delivery.Payload)
+	}
+	if redelivered, _ := repo.GetDelivery(ctx, redelivery.ID); redelivered.Status != models.DeliveryDelivered {
+		t.Errorf("redelivery status = %s, want delivered", redelivered.Status)
+	}
+}
+
+func TestWebhookEnqueueFilter(t *testing.T) {
+	projectID := uuid.NewString()
+	all := models.Webhook{ID: uuid.NewString(), URL: "http://all.test"}
+	deleted := models.Webhook{ID: uuid.NewString(), URL: "http://deleted.test", EventTypes: []models.EventType{models.EventDeleted}}
+	project := models.Webhook{ID: uuid.NewString(), URL: "http://project.test", Filter: models.TaskFilter{ProjectID: projectID}}
+	repo := newMemWebhookRepo(all, deleted, project)
+	svc := newTestWebhookService(repo, 1)
+
+	event := models.TaskEvent{ID: 1, Type: models.EventCreated, Task: models.Task{ID: uuid.NewString(), ProjectID: projectID}}
+	if err := svc.Enqueue(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]bool{}
+	for _, delivery := range repo.deliveries {
+		got[delivery.WebhookID] = true
+	}
+	if len(got) != 2 || !got[all.ID] || !got[project.ID] {
+		t.Errorf("deliveries for %v, want the all and project webhooks", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	want := []time.Duration{retryBase, 2 * retryBase, 4 * retryBase}
+	for i, delay := range want {
+		if got := backoff(i + 1); got != delay {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, delay)
+		}
+	}
+	if got := backoff(100); got != retryMax {
+		t.Errorf("backoff(100) = %s, want %s", got, retryMax)
+	}
+}
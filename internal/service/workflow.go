@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type WorkflowRepo interface {
+	ListStatuses(ctx context.Context) ([]models.WorkflowStatus, error)
+	CreateStatus(ctx context.Context, status models.WorkflowStatus) (models.WorkflowStatus, error)
+	DeleteStatus(ctx context.Context, name string) error
+	ListTransitions(ctx context.Context) ([]models.Transition, error)
+	CreateTransition(ctx context.Context, transition models.Transition) error
+	DeleteTransition(ctx context.Context, transition models.Transition) error
+}
+
+type WorkflowService struct {
+	repo  WorkflowRepo
+	cache *workflowCache
+	log   *zerolog.Logger
+}
+
+func NewWorkflowService(repo WorkflowRepo, log *zerolog.Logger) *WorkflowService {
+	return &WorkflowService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// WithCache keeps the workflow for ttl instead of loading it for every
+// validated task. It is dropped on the workflow writes of this service, the
+// writes of other instances show up once it expires.
+func (s *WorkflowService) WithCache(ttl time.Duration) *WorkflowService {
+	s.cache = &workflowCache{ttl: ttl}
+	return s
+}
+
+// workflowCache holds the last loaded workflow.
+type workflowCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	workflow models.Workflow
+	expires  time.Time
+	// gen is advanced by every write, a workflow loaded before it isn't kept
+	gen uint64
+}
+
+func (c *workflowCache) get() (models.Workflow, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().After(c.expires) {
+		return models.Workflow{}, c.gen, false
+	}
+	return cloneWorkflow(c.workflow), c.gen, true
+}
+
+func (c *workflowCache) put(workflow models.Workflow, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen == c.gen {
+		c.workflow = cloneWorkflow(workflow)
+		c.expires = time.Now().Add(c.ttl)
+	}
+}
+
+func (c *workflowCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.expires = time.Time{}
+}
+
+func cloneWorkflow(workflow models.Workflow) models.Workflow {
+	return models.Workflow{
+		Statuses:    slices.Clone(workflow.Statuses),
+		Transitions: slices.Clone(workflow.Transitions),
+	}
+}
+
+// invalidate drops the cached workflow after a write, failed ones included
+// as they may have changed it all the same.
+func (s *WorkflowService) invalidate() {
+	if s.cache != nil {
+		s.cache.invalidate()
+	}
+}
+
+func (s *WorkflowService) Get(ctx context.Context) (models.Workflow, error) {
+	var gen uint64
+	if s.cache != nil {
+		workflow, cachedGen, ok := s.cache.get()
+		if ok {
+			return workflow, nil
+		}
+		gen = cachedGen
+	}
+
+	statuses, err := s.repo.ListStatuses(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error listing statuses")
+		return models.Workflow{}, err
+	}
+
+	transitions, err := s.repo.ListTransitions(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error listing transitions")
+		return models.Workflow{}, err
+	}
+
+	workflow := models.Workflow{
+		Statuses:    statuses,
+		Transitions: transitions,
+	}
+	if s.cache != nil {
+		s.cache.put(workflow, gen)
+	}
+	return workflow, nil
+}
+
+// ValidateStatus checks that the status is registered in the workflow.
+func (s *WorkflowService) ValidateStatus(ctx context.Context, status models.TaskStatus) error {
+	workflow, err := s.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := workflow.Status(status); !ok {
+		return models.ErrStatusNotFound
+	}
+	return nil
+}
+
+// ValidateTransition checks that the target status is registered and
+// reachable from the current one.
+func (s *WorkflowService) ValidateTransition(ctx context.Context, from, to models.TaskStatus) error {
+	workflow, err := s.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := workflow.Status(to); !ok {
+		return models.ErrStatusNotFound
+	}
+	if !workflow.Allowed(from, to) {
+		s.log.Debug().Msgf("transition %s -> %s isn't allowed", from, to)
+		return models.ErrTransitionNotAllowed
+	}
+	return nil
+}
+
+// InitialStatus returns the first open status of the workflow, which is
+// used for tasks created by the tracker itself.
+func (s *WorkflowService) InitialStatus(ctx context.Context) (models.TaskStatus, error) {
+	workflow, err := s.Get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, status := range workflow.Statuses {
+		if status.Category == models.CategoryOpen {
+			return status.Name, nil
+		}
+	}
+	if len(workflow.Statuses) > 0 {
+		return workflow.Statuses[0].Name, nil
+	}
+	return "", models.ErrStatusNotFound
+}
+
+func (s *WorkflowService) CreateStatus(ctx context.Context, status models.WorkflowStatus) (models.WorkflowStatus, error) {
+	s.log.Info().Msgf("Creating status: %s", status.Name)
+
+	status, err := s.repo.CreateStatus(ctx, status)
+	s.invalidate()
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error creating status: %s", status.Name)
+		return models.WorkflowStatus{}, err
+	}
+
+	return status, nil
+}
+
+func (s *WorkflowService) DeleteStatus(ctx context.Context, name models.TaskStatus) error {
+	s.log.Info().Msgf("Deleting status: %s", name)
+
+	err := s.repo.DeleteStatus(ctx, name.String())
+	s.invalidate()
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting status: %s", name)
+		return err
+	}
+
+	return nil
+}
+
+func (s *WorkflowService) CreateTransition(ctx context.Context, transition models.Transition) error {
+	s.log.Info().Msgf("Creating transition: %s -> %s", transition.From, transition.To)
+
+	workflow, err := s.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := workflow.Status(transition.From); !ok {
+		return models.ErrStatusNotFound
+	}
+	if _, ok := workflow.Status(transition.To); !ok {
+		return models.ErrStatusNotFound
+	}
+
+	err = s.repo.CreateTransition(ctx, transition)
+	s.invalidate()
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error creating transition: %s -> %s", transition.From, transition.To)
+		return err
+	}
+
+	return nil
+}
+
+func (s *WorkflowService) DeleteTransition(ctx context.Context, transition models.Transition) error {
+	s.log.Info().Msgf("Deleting transition: %s -> %s", transition.From, transition.To)
+
+	err := s.repo.DeleteTransition(ctx, transition)
+	s.invalidate()
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error deleting transition: %s -> %s", transition.From, transition.To)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/VikaPaz/task_tracker/internal/repository/memory"
+	"github.com/rs/zerolog"
+)
+
+// countingWorkflowRepo counts the loads of the statuses.
+type countingWorkflowRepo struct {
+	WorkflowRepo
+	loads int
+}
+
+func (r *countingWorkflowRepo) ListStatuses(ctx context.Context) ([]models.WorkflowStatus, error) {
+	r.loads++
+	return r.WorkflowRepo.ListStatuses(ctx)
+}
+
+func TestWorkflowCache(t *testing.T) {
+	ctx := context.Background()
+	logger := zerolog.Nop()
+	repo := &countingWorkflowRepo{WorkflowRepo: memory.NewWorkflowRepository(memory.NewDB(), &logger)}
+	svc := NewWorkflowService(repo, &logger).WithCache(time.Hour)
+
+	for _, status := range []models.TaskStatus{models.InProgress, models.Done} {
+		if err := svc.ValidateStatus(ctx, status); err != nil {
+			t.Fatalf("ValidateStatus(%s): %v", status, err)
+		}
+	}
+	if repo.loads != 1 {
+		t.Errorf("workflow loaded %d times, want once", repo.loads)
+	}
+
+	// a write drops the cached workflow
+	_, err := svc.CreateStatus(ctx, models.WorkflowStatus{Name: "on_hold", Title: "On hold", Category: models.CategoryActive, Position: 45})
+	if err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+	if err := svc.ValidateStatus(ctx, "on_hold"); err != nil {
+		t.Errorf("ValidateStatus after CreateStatus: %v", err)
+	}
+	if repo.loads != 2 {
+		t.Errorf("workflow loaded %d times, want twice", repo.loads)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VikaPaz/task_tracker/internal/auth"
+	"github.com/VikaPaz/task_tracker/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type WorkLogRepo interface {
+	Create(ctx context.Context, log models.WorkLog) (models.WorkLog, error)
+	Running(ctx context.Context, userID string) (models.WorkLog, error)
+	Stop(ctx context.Context, userID string, stopped time.Time) (models.WorkLog, error)
+	List(ctx context.Context, taskID string, page models.Page) (models.WorkLogList, error)
+	Total(ctx context.Context, filter models.WorkLogFilter) (int64, error)
+	Report(ctx context.Context, filter models.WorkReportFilter) ([]models.WorkReportRow, error)
+}
+
+type WorkLogService struct {
+	repo  WorkLogRepo
+	tasks TaskGetter
+	log   *zerolog.Logger
+}
+
+func NewWorkLogService(repo WorkLogRepo, tasks TaskGetter, log *zerolog.Logger) *WorkLogService {
+	return &WorkLogService{
+		repo:  repo,
+		tasks: tasks,
+		log:   log,
+	}
+}
+
+// Start runs a timer of the current user on the task. A user can't have
+// more than one running timer.
+func (s *WorkLogService) Start(ctx context.Context, taskID uuid.UUID, note string) (models.WorkLog, error) {
+	s.log.Info().Msgf("Starting timer on task: %s", taskID.String())
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.WorkLog{}, models.ErrUnauthenticated
+	}
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", taskID.String())
+		return models.WorkLog{}, err
+	}
+
+	log, err := s.repo.Create(ctx, models.WorkLog{
+		TaskID:  taskID.String(),
+		UserID:  userID,
+		Started: time.Now().UTC(),
+		Note:    note,
+		Running: true,
+	})
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error starting timer of user: %s", userID)
+		return models.WorkLog{}, err
+	}
+
+	return log, nil
+}
+
+// Stop finishes the running timer of the current user.
+func (s *WorkLogService) Stop(ctx context.Context) (models.WorkLog, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.WorkLog{}, models.ErrUnauthenticated
+	}
+	s.log.Info().Msgf("Stopping timer of user: %s", userID)
+
+	log, err := s.repo.Stop(ctx, userID, time.Now().UTC())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error stopping timer of user: %s", userID)
+		return models.WorkLog{}, err
+	}
+
+	return log, nil
+}
+
+// Running returns the running timer of the current user.
+func (s *WorkLogService) Running(ctx context.Context) (models.WorkLog, error) {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.WorkLog{}, models.ErrUnauthenticated
+	}
+
+	return s.repo.Running(ctx, userID)
+}
+
+// Log stores time spent by the current user which wasn't tracked with a timer.
+func (s *WorkLogService) Log(ctx context.Context, log models.WorkLog) (models.WorkLog, error) {
+	s.log.Info().Msgf("Logging work on task: %s", log.TaskID)
+
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return models.WorkLog{}, models.ErrUnauthenticated
+	}
+
+	taskID, err := uuid.Parse(log.TaskID)
+	if err != nil {
+		return models.WorkLog{}, models.ErrTaskNotFound
+	}
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		s.log.Error().Err(err).Msgf("Error fetching task with ID: %s", log.TaskID)
+		return models.WorkLog{}, err
+	}
+
+	log.UserID = userID
+	log.Started = log.Started.UTC()
+	log.Running = false
+
+	log, err = s.repo.Create(ctx, log)
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error logging work on task: %s", log.TaskID)
+		return models.WorkLog{}, err
+	}
+
+	return log, nil
+}
+
+func (s *WorkLogService) List(ctx context.Context, taskID string, page models.Page) (models.WorkLogList, error) {
+	s.log.Info().Msgf("Listing work logs of task: %s", taskID)
+
+	logs, err := s.repo.List(ctx, taskID, page.Normalize())
+	if err != nil {
+		s.log.Error().Err(err).Msgf("Error listing work logs of task: %s", taskID)
+		return models.WorkLogList{}, err
+	}
+
+	return logs, nil
+}
+
+// Total returns the seconds logged matching the filter, running timers aren't counted.
+func (s *WorkLogService) Total(ctx context.Context, filter models.WorkLogFilter) (int64, error) {
+	s.log.Info().Msgf("Summing work logs: %v", filter)
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return 0, models.ErrInvalidRange
+	}
+
+	total, err := s.repo.Total(ctx, filter)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error summing work logs")
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Report aggregates the logged time within the date range.
+func (s *WorkLogService) Report(ctx context.Context, filter models.WorkReportFilter) ([]models.WorkReportRow, error) {
+	s.log.Info().Msgf("Building work report: %v", filter)
+
+	if filter.From.IsZero() || filter.To.IsZero() || filter.To.Before(filter.From) {
+		return nil, models.ErrInvalidRange
+	}
+
+	rows, err := s.repo.Report(ctx, filter)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Error building work report")
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists comments
+(
+    id         uuid default uuid_generate_v4() primary key,
+    task_id    uuid not null references tasks (id) on delete cascade,
+    author_id  uuid not null,
+    body       text not null,
+    created_at timestamp not null default current_timestamp,
+    updated_at timestamp default current_timestamp,
+    edited     boolean not null default false
+);
+
+create index if not exists comments_task_id_idx on comments (task_id, created_at);
+
+create table if not exists comment_edits
+(
+    id         uuid default uuid_generate_v4() primary key,
+    comment_id uuid not null references comments (id) on delete cascade,
+    editor_id  uuid not null,
+    body       text not null,
+    edited_at  timestamp not null default current_timestamp
+);
+
+create index if not exists comment_edits_comment_id_idx on comment_edits (comment_id, edited_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table comment_edits;
+drop table comments;
+-- +goose StatementEnd
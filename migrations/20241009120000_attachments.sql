@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists attachments
+(
+    id           uuid primary key,
+    task_id      uuid not null references tasks (id) on delete cascade,
+    filename     text not null,
+    content_type varchar(255) not null,
+    size         bigint not null,
+    checksum     char(64) not null,
+    storage_key  text not null,
+    uploader_id  uuid,
+    created_at   timestamp not null default current_timestamp
+);
+
+create index if not exists attachments_task_id_idx on attachments (task_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table attachments;
+-- +goose StatementEnd
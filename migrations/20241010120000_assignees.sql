@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column if not exists assignee_id uuid;
+
+create index if not exists tasks_assignee_id_idx on tasks (assignee_id);
+
+create table if not exists task_history
+(
+    id         uuid default uuid_generate_v4() primary key,
+    task_id    uuid not null references tasks (id) on delete cascade,
+    actor_id   uuid,
+    action     varchar(50) not null,
+    field      varchar(100),
+    old_value  text,
+    new_value  text,
+    created_at timestamp not null default current_timestamp
+);
+
+create index if not exists task_history_task_id_idx on task_history (task_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table task_history;
+drop index tasks_assignee_id_idx;
+alter table tasks drop column assignee_id;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column if not exists due_at timestamp,
+    add column if not exists rrule text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column rrule,
+    drop column due_at;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists projects
+(
+    id          uuid default uuid_generate_v4() primary key,
+    name        varchar(200) not null,
+    description text,
+    owner_id    uuid not null,
+    created_at  timestamp not null default current_timestamp,
+    updated_at  timestamp default current_timestamp
+);
+
+create table if not exists project_members
+(
+    project_id uuid not null references projects (id) on delete cascade,
+    user_id    uuid not null,
+    role       varchar(50) not null,
+    added_at   timestamp not null default current_timestamp,
+    primary key (project_id, user_id)
+);
+
+create index if not exists project_members_user_id_idx on project_members (user_id);
+
+alter table tasks add column if not exists project_id uuid references projects (id) on delete set null;
+
+create index if not exists tasks_project_id_idx on tasks (project_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_project_id_idx;
+alter table tasks drop column project_id;
+drop table project_members;
+drop table projects;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists work_logs
+(
+    id         uuid default uuid_generate_v4() primary key,
+    task_id    uuid not null references tasks (id) on delete cascade,
+    user_id    uuid not null,
+    started_at timestamp not null,
+    -- seconds, null while the timer is running
+    duration   bigint check (duration >= 0),
+    note       text
+);
+
+create index if not exists work_logs_task_id_idx on work_logs (task_id, started_at);
+
+create index if not exists work_logs_started_at_idx on work_logs (started_at);
+
+-- at most one running timer per user
+create unique index if not exists work_logs_running_idx on work_logs (user_id) where duration is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table work_logs;
+-- +goose StatementEnd
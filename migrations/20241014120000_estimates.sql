@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column if not exists estimate_minutes integer not null default 0 check (estimate_minutes >= 0),
+    add column if not exists story_points integer not null default 0 check (story_points >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column story_points,
+    drop column estimate_minutes;
+-- +goose StatementEnd
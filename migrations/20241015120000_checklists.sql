@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists checklist_items
+(
+    id       uuid default uuid_generate_v4() primary key,
+    task_id  uuid not null references tasks (id) on delete cascade,
+    text     text not null,
+    checked  boolean not null default false,
+    position integer not null
+);
+
+create index if not exists checklist_items_task_id_idx on checklist_items (task_id, position);
+
+-- the progress of the checklist, recounted whenever an item is added, checked or deleted
+alter table tasks add column if not exists checklist_checked integer not null default 0,
+    add column if not exists checklist_total integer not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column checklist_total,
+    drop column checklist_checked;
+drop table checklist_items;
+-- +goose StatementEnd
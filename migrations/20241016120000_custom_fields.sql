@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists custom_field_definitions
+(
+    id         uuid default uuid_generate_v4() primary key,
+    project_id uuid not null references projects (id) on delete cascade,
+    name       varchar(100) not null,
+    type       varchar(20) not null,
+    options    jsonb,
+    required   boolean not null default false,
+    created_at timestamp not null default current_timestamp,
+    unique (project_id, name)
+);
+
+alter table tasks add column if not exists custom_fields jsonb;
+
+create index if not exists tasks_custom_fields_idx on tasks using gin (custom_fields);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_custom_fields_idx;
+alter table tasks drop column custom_fields;
+drop table custom_field_definitions;
+-- +goose StatementEnd
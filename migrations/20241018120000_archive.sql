@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column if not exists archived_at timestamp;
+
+create index if not exists tasks_unarchived_idx on tasks (status, updated_at) where archived_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_unarchived_idx;
+alter table tasks drop column archived_at;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists task_revisions
+(
+    id         uuid default uuid_generate_v4() primary key,
+    task_id    uuid not null references tasks (id) on delete cascade,
+    actor_id   uuid,
+    action     varchar(50) not null,
+    changes    jsonb not null default '[]',
+    created_at timestamp not null default current_timestamp
+);
+
+create index if not exists task_revisions_task_id_idx on task_revisions (task_id, created_at);
+
+insert into task_revisions (id, task_id, actor_id, action, changes, created_at)
+select id,
+       task_id,
+       actor_id,
+       action,
+       jsonb_build_array(jsonb_build_object('field', field, 'old', nullif(old_value, ''), 'new', nullif(new_value, ''))),
+       created_at
+from task_history;
+
+drop table task_history;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+create table if not exists task_history
+(
+    id         uuid default uuid_generate_v4() primary key,
+    task_id    uuid not null references tasks (id) on delete cascade,
+    actor_id   uuid,
+    action     varchar(50) not null,
+    field      varchar(100),
+    old_value  text,
+    new_value  text,
+    created_at timestamp not null default current_timestamp
+);
+
+create index if not exists task_history_task_id_idx on task_history (task_id, created_at);
+
+insert into task_history (task_id, actor_id, action, field, old_value, new_value, created_at)
+select r.task_id, r.actor_id, r.action, c ->> 'field', c ->> 'old', c ->> 'new', r.created_at
+from task_revisions r,
+     jsonb_array_elements(r.changes) c;
+
+drop table task_revisions;
+-- +goose StatementEnd
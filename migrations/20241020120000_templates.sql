@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column if not exists labels text[],
+    add column if not exists parent_id uuid references tasks (id) on delete set null;
+
+create index if not exists tasks_labels_idx on tasks using gin (labels);
+create index if not exists tasks_parent_id_idx on tasks (parent_id);
+
+create table if not exists task_templates
+(
+    id         uuid default uuid_generate_v4() primary key,
+    owner_id   uuid not null,
+    name       varchar(200) not null,
+    root       jsonb not null,
+    created_at timestamp not null,
+    updated_at timestamp not null
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table task_templates;
+drop index tasks_parent_id_idx;
+drop index tasks_labels_idx;
+alter table tasks drop column parent_id,
+    drop column labels;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists users
+(
+    id       uuid primary key,
+    username varchar(32) not null unique
+);
+
+create table if not exists task_watchers
+(
+    task_id    uuid not null references tasks (id) on delete cascade,
+    user_id    uuid not null,
+    created_at timestamp not null default current_timestamp,
+    primary key (task_id, user_id)
+);
+
+create index if not exists task_watchers_user_id_idx on task_watchers (user_id);
+
+-- the user_id of task_watchers in the order they started watching
+alter table tasks add column if not exists watchers uuid[];
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column watchers;
+drop table task_watchers;
+drop table users;
+-- +goose StatementEnd
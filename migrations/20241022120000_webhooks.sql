@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists webhooks
+(
+    id          uuid default uuid_generate_v4() primary key,
+    owner_id    uuid not null,
+    url         text not null,
+    secret      text not null,
+    event_types text[],
+    filter      jsonb not null,
+    created_at  timestamp not null
+);
+
+create index if not exists webhooks_owner_id_idx on webhooks (owner_id);
+
+create table if not exists webhook_deliveries
+(
+    id              uuid default uuid_generate_v4() primary key,
+    webhook_id      uuid not null references webhooks (id) on delete cascade,
+    event_id        bigint not null,
+    event_type      varchar(20) not null,
+    payload         jsonb not null,
+    status          varchar(20) not null,
+    attempts        integer not null default 0,
+    next_attempt_at timestamp,
+    response_code   integer,
+    last_error      text,
+    created_at      timestamp not null,
+    delivered_at    timestamp
+);
+
+create index if not exists webhook_deliveries_webhook_id_idx on webhook_deliveries (webhook_id, created_at);
+create index if not exists webhook_deliveries_due_idx on webhook_deliveries (next_attempt_at) where status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table webhook_deliveries;
+drop table webhooks;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists outbox
+(
+    id         bigserial primary key,
+    task_id    uuid not null,
+    event_type varchar(20) not null,
+    payload    jsonb not null,
+    created_at timestamp not null default current_timestamp,
+    relayed_at timestamp
+);
+
+create index if not exists outbox_pending_idx on outbox (id) where relayed_at is null;
+create index if not exists outbox_relayed_at_idx on outbox (relayed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table outbox;
+-- +goose StatementEnd
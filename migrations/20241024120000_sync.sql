@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+create sequence if not exists tasks_change_seq;
+
+alter table tasks add column if not exists change_seq bigint not null default nextval('tasks_change_seq');
+
+create index if not exists tasks_change_seq_idx on tasks (change_seq);
+
+create or replace function tasks_next_change_seq() returns trigger as
+$$
+begin
+    new.change_seq = nextval('tasks_change_seq');
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger tasks_change_seq
+    before update on tasks
+    for each row
+execute function tasks_next_change_seq();
+
+-- the highest change_seq of the purged tasks, a sync token below it misses their tombstones
+create table if not exists task_sync_horizon
+(
+    id         integer primary key,
+    change_seq bigint  not null
+);
+
+insert into task_sync_horizon (id, change_seq) values (1, 0) on conflict do nothing;
+
+create or replace function tasks_advance_sync_horizon() returns trigger as
+$$
+begin
+    update task_sync_horizon set change_seq = greatest(change_seq, old.change_seq) where id = 1;
+    return old;
+end;
+$$ language plpgsql;
+
+create trigger tasks_sync_horizon
+    after delete on tasks
+    for each row
+execute function tasks_advance_sync_horizon();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger tasks_sync_horizon on tasks;
+drop function tasks_advance_sync_horizon();
+drop table task_sync_horizon;
+drop trigger tasks_change_seq on tasks;
+drop function tasks_next_change_seq();
+alter table tasks drop column change_seq;
+drop sequence tasks_change_seq;
+-- +goose StatementEnd
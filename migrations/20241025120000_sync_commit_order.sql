@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- change_seq is taken when the transaction commits and under a lock, so it
+-- grows in the order the changes become visible. Taken on update, a change
+-- committed after a later one could end up below a token already handed out.
+drop trigger tasks_change_seq on tasks;
+drop function tasks_next_change_seq();
+
+create or replace function tasks_commit_change_seq() returns trigger as
+$$
+begin
+    -- held until the commit, the next transaction takes its values after this one is visible
+    perform pg_advisory_xact_lock(hashtext('tasks_change_seq'));
+    update tasks set change_seq = nextval('tasks_change_seq') where id = new.id;
+    return null;
+end;
+$$ language plpgsql;
+
+-- the update of the trigger itself runs at depth 1 and isn't queued again
+create constraint trigger tasks_change_seq
+    after insert or update on tasks
+    deferrable initially deferred
+    for each row
+    when (pg_trigger_depth() = 0)
+execute function tasks_commit_change_seq();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger tasks_change_seq on tasks;
+drop function tasks_commit_change_seq();
+
+create or replace function tasks_next_change_seq() returns trigger as
+$$
+begin
+    new.change_seq = nextval('tasks_change_seq');
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger tasks_change_seq
+    before update on tasks
+    for each row
+execute function tasks_next_change_seq();
+-- +goose StatementEnd
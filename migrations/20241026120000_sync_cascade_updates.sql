@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Updates made by foreign key actions run at trigger depth 1 as well, so the
+-- depth guard left them without a new change_seq. The update of the trigger
+-- itself is told apart by the change_seq it sets instead.
+drop trigger tasks_change_seq on tasks;
+
+create constraint trigger tasks_change_seq_insert
+    after insert on tasks
+    deferrable initially deferred
+    for each row
+execute function tasks_commit_change_seq();
+
+create constraint trigger tasks_change_seq
+    after update on tasks
+    deferrable initially deferred
+    for each row
+    when (new.change_seq is not distinct from old.change_seq)
+execute function tasks_commit_change_seq();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger tasks_change_seq on tasks;
+drop trigger tasks_change_seq_insert on tasks;
+
+create constraint trigger tasks_change_seq
+    after insert or update on tasks
+    deferrable initially deferred
+    for each row
+    when (pg_trigger_depth() = 0)
+execute function tasks_commit_change_seq();
+-- +goose StatementEnd
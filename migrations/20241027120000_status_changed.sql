@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- done tasks are archived some time after they got done, updated_at moves on
+-- with every later edit. The tasks from before keep updated_at as the time.
+alter table tasks add column if not exists status_changed_at timestamp;
+
+create or replace function tasks_set_status_changed() returns trigger as
+$$
+begin
+    if tg_op = 'INSERT' then
+        new.status_changed_at = coalesce(new.updated_at, now());
+    elsif new.status is distinct from old.status then
+        new.status_changed_at = coalesce(new.updated_at, now());
+    end if;
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger tasks_status_changed
+    before insert or update of status on tasks
+    for each row
+execute function tasks_set_status_changed();
+
+drop index if exists tasks_unarchived_idx;
+create index if not exists tasks_unarchived_idx on tasks (status, coalesce(status_changed_at, updated_at)) where archived_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists tasks_unarchived_idx;
+create index if not exists tasks_unarchived_idx on tasks (status, updated_at) where archived_at is null;
+
+drop trigger tasks_status_changed on tasks;
+drop function tasks_set_status_changed();
+alter table tasks drop column status_changed_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists tasks
+(
+    id          text primary key,
+    title       text,
+    description text,
+    created_at  timestamp,
+    updated_at  timestamp,
+    status      varchar(100),
+    owner_id    text
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table tasks;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists workflow_statuses
+(
+    name     varchar(100) primary key,
+    title    text,
+    category varchar(20) not null,
+    position int not null default 0
+);
+
+create table if not exists workflow_transitions
+(
+    from_status varchar(100) references workflow_statuses (name) on delete cascade,
+    to_status   varchar(100) references workflow_statuses (name) on delete cascade,
+    primary key (from_status, to_status)
+);
+
+insert or ignore into workflow_statuses (name, title, category, position)
+values ('todo', 'To do', 'open', 10),
+       ('in_progress', 'In progress', 'active', 20),
+       ('in_review', 'In review', 'active', 30),
+       ('blocked', 'Blocked', 'active', 40),
+       ('done', 'Done', 'closed', 50),
+       ('cancelled', 'Cancelled', 'closed', 60);
+
+insert or ignore into workflow_transitions (from_status, to_status)
+values ('todo', 'in_progress'),
+       ('todo', 'cancelled'),
+       ('in_progress', 'todo'),
+       ('in_progress', 'in_review'),
+       ('in_progress', 'blocked'),
+       ('in_progress', 'done'),
+       ('in_progress', 'cancelled'),
+       ('in_review', 'in_progress'),
+       ('in_review', 'done'),
+       ('blocked', 'in_progress'),
+       ('blocked', 'cancelled'),
+       ('done', 'in_progress'),
+       ('cancelled', 'todo');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table workflow_transitions;
+drop table workflow_statuses;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column assignee_id text;
+
+create index if not exists tasks_assignee_id_idx on tasks (assignee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_assignee_id_idx;
+alter table tasks drop column assignee_id;
+-- +goose StatementEnd
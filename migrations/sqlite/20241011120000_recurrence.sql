@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column due_at timestamp;
+alter table tasks add column rrule text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column rrule;
+alter table tasks drop column due_at;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- projects aren't served from sqlite, the column keeps the task payloads the same
+alter table tasks add column project_id text;
+
+create index if not exists tasks_project_id_idx on tasks (project_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_project_id_idx;
+alter table tasks drop column project_id;
+-- +goose StatementEnd
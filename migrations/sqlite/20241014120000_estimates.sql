@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column estimate_minutes integer not null default 0 check (estimate_minutes >= 0);
+alter table tasks add column story_points integer not null default 0 check (story_points >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column story_points;
+alter table tasks drop column estimate_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists checklist_items
+(
+    id       text primary key,
+    task_id  text not null references tasks (id) on delete cascade,
+    text     text not null,
+    checked  boolean not null default false,
+    position integer not null
+);
+
+create index if not exists checklist_items_task_id_idx on checklist_items (task_id, position);
+
+alter table tasks add column checklist_checked integer not null default 0;
+alter table tasks add column checklist_total integer not null default 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column checklist_total;
+alter table tasks drop column checklist_checked;
+drop table checklist_items;
+-- +goose StatementEnd
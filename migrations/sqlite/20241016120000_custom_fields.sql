@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column custom_fields text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column custom_fields;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table tasks add column deleted_at timestamp;
+
+create index if not exists tasks_deleted_at_idx on tasks (deleted_at) where deleted_at is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_deleted_at_idx;
+delete from tasks where deleted_at is not null;
+alter table tasks drop column deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists task_revisions
+(
+    id         text primary key,
+    task_id    text not null references tasks (id) on delete cascade,
+    actor_id   text,
+    action     varchar(50) not null,
+    changes    text not null default '[]',
+    created_at timestamp not null default current_timestamp
+);
+
+create index if not exists task_revisions_task_id_idx on task_revisions (task_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table task_revisions;
+-- +goose StatementEnd
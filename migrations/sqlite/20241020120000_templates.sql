@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- labels are kept as a json array
+alter table tasks add column labels text;
+alter table tasks add column parent_id text references tasks (id) on delete set null;
+
+create index if not exists tasks_parent_id_idx on tasks (parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index tasks_parent_id_idx;
+alter table tasks drop column parent_id;
+alter table tasks drop column labels;
+-- +goose StatementEnd
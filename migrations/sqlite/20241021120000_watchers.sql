@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- watchers are kept as a json array
+alter table tasks add column watchers text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table tasks drop column watchers;
+-- +goose StatementEnd
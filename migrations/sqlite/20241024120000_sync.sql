@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- sqlite has no sequences, the last value is kept in a single row
+create table if not exists task_change_seq
+(
+    id    integer primary key,
+    value integer not null
+);
+
+insert or ignore into task_change_seq (id, value) values (1, 0);
+
+alter table tasks add column change_seq integer not null default 0;
+
+create index if not exists tasks_change_seq_idx on tasks (change_seq);
+
+update task_change_seq set value = (select coalesce(max(rowid), 0) from tasks) where id = 1;
+update tasks set change_seq = rowid;
+
+create trigger if not exists tasks_change_seq_insert
+    after insert on tasks
+    for each row
+begin
+    update task_change_seq set value = value + 1 where id = 1;
+    update tasks set change_seq = (select value from task_change_seq where id = 1) where id = new.id;
+end;
+
+-- skips the updates of change_seq made by the triggers themselves
+create trigger if not exists tasks_change_seq_update
+    after update on tasks
+    for each row
+    when new.change_seq = old.change_seq
+begin
+    update task_change_seq set value = value + 1 where id = 1;
+    update tasks set change_seq = (select value from task_change_seq where id = 1) where id = new.id;
+end;
+
+-- the highest change_seq of the purged tasks, a sync token below it misses their tombstones
+create table if not exists task_sync_horizon
+(
+    id         integer primary key,
+    change_seq integer not null
+);
+
+insert or ignore into task_sync_horizon (id, change_seq) values (1, 0);
+
+create trigger if not exists tasks_sync_horizon
+    after delete on tasks
+    for each row
+begin
+    update task_sync_horizon set change_seq = max(change_seq, old.change_seq) where id = 1;
+end;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop trigger tasks_sync_horizon;
+drop table task_sync_horizon;
+drop trigger tasks_change_seq_update;
+drop trigger tasks_change_seq_insert;
+drop index tasks_change_seq_idx;
+alter table tasks drop column change_seq;
+drop table task_change_seq;
+-- +goose StatementEnd
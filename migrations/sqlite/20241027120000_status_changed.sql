@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- done tasks are archived some time after they got done, updated_at moves on
+-- with every later edit. The tasks from before keep updated_at as the time.
+alter table tasks add column status_changed_at timestamp;
+
+create trigger if not exists tasks_status_changed_insert
+    after insert on tasks
+    for each row
+begin
+    update tasks set status_changed_at = new.updated_at where id = new.id;
+end;
+
+create trigger if not exists tasks_status_changed
+    after update of status on tasks
+    for each row
+    when new.status is not old.status
+begin
+    update tasks set status_changed_at = new.updated_at where id = new.id;
+end;
+
+drop index if exists tasks_unarchived_idx;
+create index if not exists tasks_unarchived_idx on tasks (status, coalesce(status_changed_at, updated_at)) where archived_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists tasks_unarchived_idx;
+create index if not exists tasks_unarchived_idx on tasks (status, updated_at) where archived_at is null;
+
+drop trigger tasks_status_changed;
+drop trigger tasks_status_changed_insert;
+alter table tasks drop column status_changed_at;
+-- +goose StatementEnd
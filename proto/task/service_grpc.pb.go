@@ -19,8 +19,36 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TaskService_GetTasks_FullMethodName         = "/task.TaskService/GetTasks"
-	TaskService_UpdateTaskStatus_FullMethodName = "/task.TaskService/UpdateTaskStatus"
+	TaskService_GetTasks_FullMethodName                = "/task.TaskService/GetTasks"
+	TaskService_GetTaskStats_FullMethodName            = "/task.TaskService/GetTaskStats"
+	TaskService_UpdateTaskStatus_FullMethodName        = "/task.TaskService/UpdateTaskStatus"
+	TaskService_GetWorkflow_FullMethodName             = "/task.TaskService/GetWorkflow"
+	TaskService_AssignTask_FullMethodName              = "/task.TaskService/AssignTask"
+	TaskService_UnassignTask_FullMethodName            = "/task.TaskService/UnassignTask"
+	TaskService_MoveTask_FullMethodName                = "/task.TaskService/MoveTask"
+	TaskService_ArchiveTask_FullMethodName             = "/task.TaskService/ArchiveTask"
+	TaskService_UnarchiveTask_FullMethodName           = "/task.TaskService/UnarchiveTask"
+	TaskService_GetTaskHistory_FullMethodName          = "/task.TaskService/GetTaskHistory"
+	TaskService_RevertTask_FullMethodName              = "/task.TaskService/RevertTask"
+	TaskService_WatchTasks_FullMethodName              = "/task.TaskService/WatchTasks"
+	TaskService_SyncTasks_FullMethodName               = "/task.TaskService/SyncTasks"
+	TaskService_ListComments_FullMethodName            = "/task.TaskService/ListComments"
+	TaskService_CreateComment_FullMethodName           = "/task.TaskService/CreateComment"
+	TaskService_UpdateComment_FullMethodName           = "/task.TaskService/UpdateComment"
+	TaskService_DeleteComment_FullMethodName           = "/task.TaskService/DeleteComment"
+	TaskService_GetCommentHistory_FullMethodName       = "/task.TaskService/GetCommentHistory"
+	TaskService_StartTimer_FullMethodName              = "/task.TaskService/StartTimer"
+	TaskService_StopTimer_FullMethodName               = "/task.TaskService/StopTimer"
+	TaskService_LogWork_FullMethodName                 = "/task.TaskService/LogWork"
+	TaskService_ListWorkLogs_FullMethodName            = "/task.TaskService/ListWorkLogs"
+	TaskService_GetWorkTotal_FullMethodName            = "/task.TaskService/GetWorkTotal"
+	TaskService_GetWorkReport_FullMethodName           = "/task.TaskService/GetWorkReport"
+	TaskService_ListChecklist_FullMethodName           = "/task.TaskService/ListChecklist"
+	TaskService_AddChecklistItem_FullMethodName        = "/task.TaskService/AddChecklistItem"
+	TaskService_EditChecklistItem_FullMethodName       = "/task.TaskService/EditChecklistItem"
+	TaskService_SetChecklistItemChecked_FullMethodName = "/task.TaskService/SetChecklistItemChecked"
+	TaskService_ReorderChecklist_FullMethodName        = "/task.TaskService/ReorderChecklist"
+	TaskService_DeleteChecklistItem_FullMethodName     = "/task.TaskService/DeleteChecklistItem"
 )
 
 // TaskServiceClient is the client API for TaskService service.
@@ -28,7 +56,35 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TaskServiceClient interface {
 	GetTasks(ctx context.Context, in *GetTasksRequest, opts ...grpc.CallOption) (*GetTasksResponse, error)
+	GetTaskStats(ctx context.Context, in *GetTaskStatsRequest, opts ...grpc.CallOption) (*GetTaskStatsResponse, error)
 	UpdateTaskStatus(ctx context.Context, in *UpdateTaskStatusRequest, opts ...grpc.CallOption) (*UpdateTaskStatusResponse, error)
+	GetWorkflow(ctx context.Context, in *GetWorkflowRequest, opts ...grpc.CallOption) (*GetWorkflowResponse, error)
+	AssignTask(ctx context.Context, in *AssignTaskRequest, opts ...grpc.CallOption) (*AssignTaskResponse, error)
+	UnassignTask(ctx context.Context, in *UnassignTaskRequest, opts ...grpc.CallOption) (*UnassignTaskResponse, error)
+	MoveTask(ctx context.Context, in *MoveTaskRequest, opts ...grpc.CallOption) (*MoveTaskResponse, error)
+	ArchiveTask(ctx context.Context, in *ArchiveTaskRequest, opts ...grpc.CallOption) (*ArchiveTaskResponse, error)
+	UnarchiveTask(ctx context.Context, in *UnarchiveTaskRequest, opts ...grpc.CallOption) (*UnarchiveTaskResponse, error)
+	GetTaskHistory(ctx context.Context, in *GetTaskHistoryRequest, opts ...grpc.CallOption) (*GetTaskHistoryResponse, error)
+	RevertTask(ctx context.Context, in *RevertTaskRequest, opts ...grpc.CallOption) (*RevertTaskResponse, error)
+	WatchTasks(ctx context.Context, in *WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error)
+	SyncTasks(ctx context.Context, in *SyncTasksRequest, opts ...grpc.CallOption) (*SyncTasksResponse, error)
+	ListComments(ctx context.Context, in *ListCommentsRequest, opts ...grpc.CallOption) (*ListCommentsResponse, error)
+	CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CreateCommentResponse, error)
+	UpdateComment(ctx context.Context, in *UpdateCommentRequest, opts ...grpc.CallOption) (*UpdateCommentResponse, error)
+	DeleteComment(ctx context.Context, in *DeleteCommentRequest, opts ...grpc.CallOption) (*DeleteCommentResponse, error)
+	GetCommentHistory(ctx context.Context, in *GetCommentHistoryRequest, opts ...grpc.CallOption) (*GetCommentHistoryResponse, error)
+	StartTimer(ctx context.Context, in *StartTimerRequest, opts ...grpc.CallOption) (*StartTimerResponse, error)
+	StopTimer(ctx context.Context, in *StopTimerRequest, opts ...grpc.CallOption) (*StopTimerResponse, error)
+	LogWork(ctx context.Context, in *LogWorkRequest, opts ...grpc.CallOption) (*LogWorkResponse, error)
+	ListWorkLogs(ctx context.Context, in *ListWorkLogsRequest, opts ...grpc.CallOption) (*ListWorkLogsResponse, error)
+	GetWorkTotal(ctx context.Context, in *GetWorkTotalRequest, opts ...grpc.CallOption) (*GetWorkTotalResponse, error)
+	GetWorkReport(ctx context.Context, in *GetWorkReportRequest, opts ...grpc.CallOption) (*GetWorkReportResponse, error)
+	ListChecklist(ctx context.Context, in *ListChecklistRequest, opts ...grpc.CallOption) (*ListChecklistResponse, error)
+	AddChecklistItem(ctx context.Context, in *AddChecklistItemRequest, opts ...grpc.CallOption) (*AddChecklistItemResponse, error)
+	EditChecklistItem(ctx context.Context, in *EditChecklistItemRequest, opts ...grpc.CallOption) (*EditChecklistItemResponse, error)
+	SetChecklistItemChecked(ctx context.Context, in *SetChecklistItemCheckedRequest, opts ...grpc.CallOption) (*SetChecklistItemCheckedResponse, error)
+	ReorderChecklist(ctx context.Context, in *ReorderChecklistRequest, opts ...grpc.CallOption) (*ReorderChecklistResponse, error)
+	DeleteChecklistItem(ctx context.Context, in *DeleteChecklistItemRequest, opts ...grpc.CallOption) (*DeleteChecklistItemResponse, error)
 }
 
 type taskServiceClient struct {
@@ -49,6 +105,16 @@ func (c *taskServiceClient) GetTasks(ctx context.Context, in *GetTasksRequest, o
 	return out, nil
 }
 
+func (c *taskServiceClient) GetTaskStats(ctx context.Context, in *GetTaskStatsRequest, opts ...grpc.CallOption) (*GetTaskStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTaskStatsResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetTaskStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *taskServiceClient) UpdateTaskStatus(ctx context.Context, in *UpdateTaskStatusRequest, opts ...grpc.CallOption) (*UpdateTaskStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateTaskStatusResponse)
@@ -59,12 +125,319 @@ func (c *taskServiceClient) UpdateTaskStatus(ctx context.Context, in *UpdateTask
 	return out, nil
 }
 
+func (c *taskServiceClient) GetWorkflow(ctx context.Context, in *GetWorkflowRequest, opts ...grpc.CallOption) (*GetWorkflowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWorkflowResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetWorkflow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) AssignTask(ctx context.Context, in *AssignTaskRequest, opts ...grpc.CallOption) (*AssignTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AssignTaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_AssignTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) UnassignTask(ctx context.Context, in *UnassignTaskRequest, opts ...grpc.CallOption) (*UnassignTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnassignTaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_UnassignTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) MoveTask(ctx context.Context, in *MoveTaskRequest, opts ...grpc.CallOption) (*MoveTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoveTaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_MoveTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ArchiveTask(ctx context.Context, in *ArchiveTaskRequest, opts ...grpc.CallOption) (*ArchiveTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArchiveTaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_ArchiveTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) UnarchiveTask(ctx context.Context, in *UnarchiveTaskRequest, opts ...grpc.CallOption) (*UnarchiveTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnarchiveTaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_UnarchiveTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetTaskHistory(ctx context.Context, in *GetTaskHistoryRequest, opts ...grpc.CallOption) (*GetTaskHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTaskHistoryResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetTaskHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) RevertTask(ctx context.Context, in *RevertTaskRequest, opts ...grpc.CallOption) (*RevertTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevertTaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_RevertTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) WatchTasks(ctx context.Context, in *WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskService_ServiceDesc.Streams[0], TaskService_WatchTasks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTasksRequest, TaskEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_WatchTasksClient = grpc.ServerStreamingClient[TaskEvent]
+
+func (c *taskServiceClient) SyncTasks(ctx context.Context, in *SyncTasksRequest, opts ...grpc.CallOption) (*SyncTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncTasksResponse)
+	err := c.cc.Invoke(ctx, TaskService_SyncTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListComments(ctx context.Context, in *ListCommentsRequest, opts ...grpc.CallOption) (*ListCommentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCommentsResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListComments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CreateCommentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateCommentResponse)
+	err := c.cc.Invoke(ctx, TaskService_CreateComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) UpdateComment(ctx context.Context, in *UpdateCommentRequest, opts ...grpc.CallOption) (*UpdateCommentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateCommentResponse)
+	err := c.cc.Invoke(ctx, TaskService_UpdateComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) DeleteComment(ctx context.Context, in *DeleteCommentRequest, opts ...grpc.CallOption) (*DeleteCommentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCommentResponse)
+	err := c.cc.Invoke(ctx, TaskService_DeleteComment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetCommentHistory(ctx context.Context, in *GetCommentHistoryRequest, opts ...grpc.CallOption) (*GetCommentHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCommentHistoryResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetCommentHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) StartTimer(ctx context.Context, in *StartTimerRequest, opts ...grpc.CallOption) (*StartTimerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartTimerResponse)
+	err := c.cc.Invoke(ctx, TaskService_StartTimer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) StopTimer(ctx context.Context, in *StopTimerRequest, opts ...grpc.CallOption) (*StopTimerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopTimerResponse)
+	err := c.cc.Invoke(ctx, TaskService_StopTimer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) LogWork(ctx context.Context, in *LogWorkRequest, opts ...grpc.CallOption) (*LogWorkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogWorkResponse)
+	err := c.cc.Invoke(ctx, TaskService_LogWork_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListWorkLogs(ctx context.Context, in *ListWorkLogsRequest, opts ...grpc.CallOption) (*ListWorkLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWorkLogsResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListWorkLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetWorkTotal(ctx context.Context, in *GetWorkTotalRequest, opts ...grpc.CallOption) (*GetWorkTotalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWorkTotalResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetWorkTotal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetWorkReport(ctx context.Context, in *GetWorkReportRequest, opts ...grpc.CallOption) (*GetWorkReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWorkReportResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetWorkReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListChecklist(ctx context.Context, in *ListChecklistRequest, opts ...grpc.CallOption) (*ListChecklistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChecklistResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListChecklist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) AddChecklistItem(ctx context.Context, in *AddChecklistItemRequest, opts ...grpc.CallOption) (*AddChecklistItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddChecklistItemResponse)
+	err := c.cc.Invoke(ctx, TaskService_AddChecklistItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) EditChecklistItem(ctx context.Context, in *EditChecklistItemRequest, opts ...grpc.CallOption) (*EditChecklistItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditChecklistItemResponse)
+	err := c.cc.Invoke(ctx, TaskService_EditChecklistItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) SetChecklistItemChecked(ctx context.Context, in *SetChecklistItemCheckedRequest, opts ...grpc.CallOption) (*SetChecklistItemCheckedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetChecklistItemCheckedResponse)
+	err := c.cc.Invoke(ctx, TaskService_SetChecklistItemChecked_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ReorderChecklist(ctx context.Context, in *ReorderChecklistRequest, opts ...grpc.CallOption) (*ReorderChecklistResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReorderChecklistResponse)
+	err := c.cc.Invoke(ctx, TaskService_ReorderChecklist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) DeleteChecklistItem(ctx context.Context, in *DeleteChecklistItemRequest, opts ...grpc.CallOption) (*DeleteChecklistItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteChecklistItemResponse)
+	err := c.cc.Invoke(ctx, TaskService_DeleteChecklistItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TaskServiceServer is the server API for TaskService service.
 // All implementations must embed UnimplementedTaskServiceServer
 // for forward compatibility.
 type TaskServiceServer interface {
 	GetTasks(context.Context, *GetTasksRequest) (*GetTasksResponse, error)
+	GetTaskStats(context.Context, *GetTaskStatsRequest) (*GetTaskStatsResponse, error)
 	UpdateTaskStatus(context.Context, *UpdateTaskStatusRequest) (*UpdateTaskStatusResponse, error)
+	GetWorkflow(context.Context, *GetWorkflowRequest) (*GetWorkflowResponse, error)
+	AssignTask(context.Context, *AssignTaskRequest) (*AssignTaskResponse, error)
+	UnassignTask(context.Context, *UnassignTaskRequest) (*UnassignTaskResponse, error)
+	MoveTask(context.Context, *MoveTaskRequest) (*MoveTaskResponse, error)
+	ArchiveTask(context.Context, *ArchiveTaskRequest) (*ArchiveTaskResponse, error)
+	UnarchiveTask(context.Context, *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error)
+	GetTaskHistory(context.Context, *GetTaskHistoryRequest) (*GetTaskHistoryResponse, error)
+	RevertTask(context.Context, *RevertTaskRequest) (*RevertTaskResponse, error)
+	WatchTasks(*WatchTasksRequest, grpc.ServerStreamingServer[TaskEvent]) error
+	SyncTasks(context.Context, *SyncTasksRequest) (*SyncTasksResponse, error)
+	ListComments(context.Context, *ListCommentsRequest) (*ListCommentsResponse, error)
+	CreateComment(context.Context, *CreateCommentRequest) (*CreateCommentResponse, error)
+	UpdateComment(context.Context, *UpdateCommentRequest) (*UpdateCommentResponse, error)
+	DeleteComment(context.Context, *DeleteCommentRequest) (*DeleteCommentResponse, error)
+	GetCommentHistory(context.Context, *GetCommentHistoryRequest) (*GetCommentHistoryResponse, error)
+	StartTimer(context.Context, *StartTimerRequest) (*StartTimerResponse, error)
+	StopTimer(context.Context, *StopTimerRequest) (*StopTimerResponse, error)
+	LogWork(context.Context, *LogWorkRequest) (*LogWorkResponse, error)
+	ListWorkLogs(context.Context, *ListWorkLogsRequest) (*ListWorkLogsResponse, error)
+	GetWorkTotal(context.Context, *GetWorkTotalRequest) (*GetWorkTotalResponse, error)
+	GetWorkReport(context.Context, *GetWorkReportRequest) (*GetWorkReportResponse, error)
+	ListChecklist(context.Context, *ListChecklistRequest) (*ListChecklistResponse, error)
+	AddChecklistItem(context.Context, *AddChecklistItemRequest) (*AddChecklistItemResponse, error)
+	EditChecklistItem(context.Context, *EditChecklistItemRequest) (*EditChecklistItemResponse, error)
+	SetChecklistItemChecked(context.Context, *SetChecklistItemCheckedRequest) (*SetChecklistItemCheckedResponse, error)
+	ReorderChecklist(context.Context, *ReorderChecklistRequest) (*ReorderChecklistResponse, error)
+	DeleteChecklistItem(context.Context, *DeleteChecklistItemRequest) (*DeleteChecklistItemResponse, error)
 	mustEmbedUnimplementedTaskServiceServer()
 }
 
@@ -78,9 +451,93 @@ type UnimplementedTaskServiceServer struct{}
 func (UnimplementedTaskServiceServer) GetTasks(context.Context, *GetTasksRequest) (*GetTasksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTasks not implemented")
 }
+func (UnimplementedTaskServiceServer) GetTaskStats(context.Context, *GetTaskStatsRequest) (*GetTaskStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskStats not implemented")
+}
 func (UnimplementedTaskServiceServer) UpdateTaskStatus(context.Context, *UpdateTaskStatusRequest) (*UpdateTaskStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTaskStatus not implemented")
 }
+func (UnimplementedTaskServiceServer) GetWorkflow(context.Context, *GetWorkflowRequest) (*GetWorkflowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkflow not implemented")
+}
+func (UnimplementedTaskServiceServer) AssignTask(context.Context, *AssignTaskRequest) (*AssignTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignTask not implemented")
+}
+func (UnimplementedTaskServiceServer) UnassignTask(context.Context, *UnassignTaskRequest) (*UnassignTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnassignTask not implemented")
+}
+func (UnimplementedTaskServiceServer) MoveTask(context.Context, *MoveTaskRequest) (*MoveTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveTask not implemented")
+}
+func (UnimplementedTaskServiceServer) ArchiveTask(context.Context, *ArchiveTaskRequest) (*ArchiveTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveTask not implemented")
+}
+func (UnimplementedTaskServiceServer) UnarchiveTask(context.Context, *UnarchiveTaskRequest) (*UnarchiveTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnarchiveTask not implemented")
+}
+func (UnimplementedTaskServiceServer) GetTaskHistory(context.Context, *GetTaskHistoryRequest) (*GetTaskHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskHistory not implemented")
+}
+func (UnimplementedTaskServiceServer) RevertTask(context.Context, *RevertTaskRequest) (*RevertTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevertTask not implemented")
+}
+func (UnimplementedTaskServiceServer) WatchTasks(*WatchTasksRequest, grpc.ServerStreamingServer[TaskEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTasks not implemented")
+}
+func (UnimplementedTaskServiceServer) SyncTasks(context.Context, *SyncTasksRequest) (*SyncTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncTasks not implemented")
+}
+func (UnimplementedTaskServiceServer) ListComments(context.Context, *ListCommentsRequest) (*ListCommentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListComments not implemented")
+}
+func (UnimplementedTaskServiceServer) CreateComment(context.Context, *CreateCommentRequest) (*CreateCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateComment not implemented")
+}
+func (UnimplementedTaskServiceServer) UpdateComment(context.Context, *UpdateCommentRequest) (*UpdateCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateComment not implemented")
+}
+func (UnimplementedTaskServiceServer) DeleteComment(context.Context, *DeleteCommentRequest) (*DeleteCommentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteComment not implemented")
+}
+func (UnimplementedTaskServiceServer) GetCommentHistory(context.Context, *GetCommentHistoryRequest) (*GetCommentHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCommentHistory not implemented")
+}
+func (UnimplementedTaskServiceServer) StartTimer(context.Context, *StartTimerRequest) (*StartTimerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTimer not implemented")
+}
+func (UnimplementedTaskServiceServer) StopTimer(context.Context, *StopTimerRequest) (*StopTimerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopTimer not implemented")
+}
+func (UnimplementedTaskServiceServer) LogWork(context.Context, *LogWorkRequest) (*LogWorkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogWork not implemented")
+}
+func (UnimplementedTaskServiceServer) ListWorkLogs(context.Context, *ListWorkLogsRequest) (*ListWorkLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWorkLogs not implemented")
+}
+func (UnimplementedTaskServiceServer) GetWorkTotal(context.Context, *GetWorkTotalRequest) (*GetWorkTotalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkTotal not implemented")
+}
+func (UnimplementedTaskServiceServer) GetWorkReport(context.Context, *GetWorkReportRequest) (*GetWorkReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkReport not implemented")
+}
+func (UnimplementedTaskServiceServer) ListChecklist(context.Context, *ListChecklistRequest) (*ListChecklistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChecklist not implemented")
+}
+func (UnimplementedTaskServiceServer) AddChecklistItem(context.Context, *AddChecklistItemRequest) (*AddChecklistItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddChecklistItem not implemented")
+}
+func (UnimplementedTaskServiceServer) EditChecklistItem(context.Context, *EditChecklistItemRequest) (*EditChecklistItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditChecklistItem not implemented")
+}
+func (UnimplementedTaskServiceServer) SetChecklistItemChecked(context.Context, *SetChecklistItemCheckedRequest) (*SetChecklistItemCheckedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetChecklistItemChecked not implemented")
+}
+func (UnimplementedTaskServiceServer) ReorderChecklist(context.Context, *ReorderChecklistRequest) (*ReorderChecklistResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderChecklist not implemented")
+}
+func (UnimplementedTaskServiceServer) DeleteChecklistItem(context.Context, *DeleteChecklistItemRequest) (*DeleteChecklistItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteChecklistItem not implemented")
+}
 func (UnimplementedTaskServiceServer) mustEmbedUnimplementedTaskServiceServer() {}
 func (UnimplementedTaskServiceServer) testEmbeddedByValue()                     {}
 
@@ -120,6 +577,24 @@ func _TaskService_GetTasks_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskService_GetTaskStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetTaskStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetTaskStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetTaskStats(ctx, req.(*GetTaskStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TaskService_UpdateTaskStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateTaskStatusRequest)
 	if err := dec(in); err != nil {
@@ -138,22 +613,615 @@ func _TaskService_UpdateTaskStatus_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
-// TaskService_ServiceDesc is the grpc.ServiceDesc for TaskService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var TaskService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "task.TaskService",
-	HandlerType: (*TaskServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "GetTasks",
-			Handler:    _TaskService_GetTasks_Handler,
-		},
-		{
-			MethodName: "UpdateTaskStatus",
-			Handler:    _TaskService_UpdateTaskStatus_Handler,
+func _TaskService_GetWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetWorkflow(ctx, req.(*GetWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_AssignTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).AssignTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_AssignTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).AssignTask(ctx, req.(*AssignTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_UnassignTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnassignTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).UnassignTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_UnassignTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).UnassignTask(ctx, req.(*UnassignTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_MoveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).MoveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_MoveTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).MoveTask(ctx, req.(*MoveTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ArchiveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ArchiveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ArchiveTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ArchiveTask(ctx, req.(*ArchiveTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_UnarchiveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnarchiveTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).UnarchiveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_UnarchiveTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).UnarchiveTask(ctx, req.(*UnarchiveTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetTaskHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetTaskHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetTaskHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetTaskHistory(ctx, req.(*GetTaskHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_RevertTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).RevertTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_RevertTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).RevertTask(ctx, req.(*RevertTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_WatchTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTasksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaskServiceServer).WatchTasks(m, &grpc.GenericServerStream[WatchTasksRequest, TaskEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_WatchTasksServer = grpc.ServerStreamingServer[TaskEvent]
+
+func _TaskService_SyncTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).SyncTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_SyncTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).SyncTasks(ctx, req.(*SyncTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListComments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCommentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListComments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListComments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListComments(ctx, req.(*ListCommentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_CreateComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).CreateComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_CreateComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).CreateComment(ctx, req.(*CreateCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_UpdateComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).UpdateComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_UpdateComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).UpdateComment(ctx, req.(*UpdateCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_DeleteComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).DeleteComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_DeleteComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).DeleteComment(ctx, req.(*DeleteCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetCommentHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCommentHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetCommentHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetCommentHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetCommentHistory(ctx, req.(*GetCommentHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_StartTimer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTimerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).StartTimer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_StartTimer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).StartTimer(ctx, req.(*StartTimerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_StopTimer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopTimerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).StopTimer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_StopTimer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).StopTimer(ctx, req.(*StopTimerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_LogWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogWorkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).LogWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_LogWork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).LogWork(ctx, req.(*LogWorkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListWorkLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWorkLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListWorkLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListWorkLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListWorkLogs(ctx, req.(*ListWorkLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetWorkTotal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorkTotalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetWorkTotal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetWorkTotal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetWorkTotal(ctx, req.(*GetWorkTotalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetWorkReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorkReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetWorkReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetWorkReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetWorkReport(ctx, req.(*GetWorkReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListChecklist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChecklistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListChecklist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListChecklist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListChecklist(ctx, req.(*ListChecklistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_AddChecklistItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddChecklistItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).AddChecklistItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_AddChecklistItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).AddChecklistItem(ctx, req.(*AddChecklistItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_EditChecklistItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditChecklistItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).EditChecklistItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_EditChecklistItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).EditChecklistItem(ctx, req.(*EditChecklistItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_SetChecklistItemChecked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetChecklistItemCheckedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).SetChecklistItemChecked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_SetChecklistItemChecked_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).SetChecklistItemChecked(ctx, req.(*SetChecklistItemCheckedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ReorderChecklist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderChecklistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ReorderChecklist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ReorderChecklist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ReorderChecklist(ctx, req.(*ReorderChecklistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_DeleteChecklistItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteChecklistItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).DeleteChecklistItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_DeleteChecklistItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).DeleteChecklistItem(ctx, req.(*DeleteChecklistItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TaskService_ServiceDesc is the grpc.ServiceDesc for TaskService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TaskService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.TaskService",
+	HandlerType: (*TaskServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTasks",
+			Handler:    _TaskService_GetTasks_Handler,
+		},
+		{
+			MethodName: "GetTaskStats",
+			Handler:    _TaskService_GetTaskStats_Handler,
+		},
+		{
+			MethodName: "UpdateTaskStatus",
+			Handler:    _TaskService_UpdateTaskStatus_Handler,
+		},
+		{
+			MethodName: "GetWorkflow",
+			Handler:    _TaskService_GetWorkflow_Handler,
+		},
+		{
+			MethodName: "AssignTask",
+			Handler:    _TaskService_AssignTask_Handler,
+		},
+		{
+			MethodName: "UnassignTask",
+			Handler:    _TaskService_UnassignTask_Handler,
+		},
+		{
+			MethodName: "MoveTask",
+			Handler:    _TaskService_MoveTask_Handler,
+		},
+		{
+			MethodName: "ArchiveTask",
+			Handler:    _TaskService_ArchiveTask_Handler,
+		},
+		{
+			MethodName: "UnarchiveTask",
+			Handler:    _TaskService_UnarchiveTask_Handler,
+		},
+		{
+			MethodName: "GetTaskHistory",
+			Handler:    _TaskService_GetTaskHistory_Handler,
+		},
+		{
+			MethodName: "RevertTask",
+			Handler:    _TaskService_RevertTask_Handler,
+		},
+		{
+			MethodName: "SyncTasks",
+			Handler:    _TaskService_SyncTasks_Handler,
+		},
+		{
+			MethodName: "ListComments",
+			Handler:    _TaskService_ListComments_Handler,
+		},
+		{
+			MethodName: "CreateComment",
+			Handler:    _TaskService_CreateComment_Handler,
+		},
+		{
+			MethodName: "UpdateComment",
+			Handler:    _TaskService_UpdateComment_Handler,
+		},
+		{
+			MethodName: "DeleteComment",
+			Handler:    _TaskService_DeleteComment_Handler,
+		},
+		{
+			MethodName: "GetCommentHistory",
+			Handler:    _TaskService_GetCommentHistory_Handler,
+		},
+		{
+			MethodName: "StartTimer",
+			Handler:    _TaskService_StartTimer_Handler,
+		},
+		{
+			MethodName: "StopTimer",
+			Handler:    _TaskService_StopTimer_Handler,
+		},
+		{
+			MethodName: "LogWork",
+			Handler:    _TaskService_LogWork_Handler,
+		},
+		{
+			MethodName: "ListWorkLogs",
+			Handler:    _TaskService_ListWorkLogs_Handler,
+		},
+		{
+			MethodName: "GetWorkTotal",
+			Handler:    _TaskService_GetWorkTotal_Handler,
+		},
+		{
+			MethodName: "GetWorkReport",
+			Handler:    _TaskService_GetWorkReport_Handler,
+		},
+		{
+			MethodName: "ListChecklist",
+			Handler:    _TaskService_ListChecklist_Handler,
+		},
+		{
+			MethodName: "AddChecklistItem",
+			Handler:    _TaskService_AddChecklistItem_Handler,
+		},
+		{
+			MethodName: "EditChecklistItem",
+			Handler:    _TaskService_EditChecklistItem_Handler,
+		},
+		{
+			MethodName: "SetChecklistItemChecked",
+			Handler:    _TaskService_SetChecklistItemChecked_Handler,
+		},
+		{
+			MethodName: "ReorderChecklist",
+			Handler:    _TaskService_ReorderChecklist_Handler,
+		},
+		{
+			MethodName: "DeleteChecklistItem",
+			Handler:    _TaskService_DeleteChecklistItem_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTasks",
+			Handler:       _TaskService_WatchTasks_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }